@@ -3,7 +3,9 @@ package ai
 import (
 	"aiquiz/config"
 	"aiquiz/models/dto"
+	"aiquiz/utils/codecheck"
 	"aiquiz/utils/enums"
+	"aiquiz/utils/markdown"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -31,9 +33,21 @@ type RequestBody struct {
 	Parameters Parameters `json:"parameters"`
 }
 
+// 通义千问文本生成接口路径，域名由配置 DASHSCOPE_BASE_URL 决定
+const textGenerationPath = "/api/v1/services/aigc/text-generation/generation"
+
 // GenerateResponse 生成题目响应结构体
 type GenerateResponse struct {
-	Questions []dto.Question `json:"questions"`
+	Questions  []dto.Question `json:"questions"`
+	CodeIssues [][]string     `json:"code_issues"` // 与 Questions 按下标对应，题目中代码的编译问题
+	Usage      Usage          `json:"usage"`
+}
+
+// Usage 模型调用的token用量
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
 }
 
 // GenerateQuestions 生成编程题目
@@ -42,9 +56,18 @@ type GenerateResponse struct {
 // keywords: 关键词（如"Gin 框架"、"数据库操作"等）
 // count: 题目数量
 func GenerateQuestions(aiModel, language, questionType, keywords string, count int) (*GenerateResponse, error) {
+	return GenerateQuestionsWithPrompt(aiModel, PromptTemplateDefault, language, questionType, keywords, count)
+}
 
-	// 构建提示词
-	prompt, err := buildPrompt(language, questionType, keywords, count)
+// GenerateQuestionsWithPrompt 使用指定的提示词模板生成编程题目，供提示词实验使用
+func GenerateQuestionsWithPrompt(aiModel, promptTemplate, language, questionType, keywords string, count int) (*GenerateResponse, error) {
+	builder, ok := promptTemplates[promptTemplate]
+	if !ok {
+		return nil, fmt.Errorf("不支持的提示词模板: %s", promptTemplate)
+	}
+
+	// 构建提示词(关键词在插入提示词前做一次清洗，避免破坏引号包裹的结构)
+	prompt, err := builder(language, questionType, SanitizeKeywords(keywords), count)
 	if err != nil {
 		return nil, err
 	}
@@ -59,13 +82,7 @@ func GenerateQuestions(aiModel, language, questionType, keywords string, count i
 	}
 
 	// 根据不同的模型解析API响应
-	var cleanedJson string
-	switch aiModel {
-	case string(enums.AiModelQwenPlus):
-		cleanedJson, err = parseQwenApiResponse(respBody)
-	case string(enums.AiModelDeepSeek):
-		cleanedJson, err = parseV3ApiResponse(respBody)
-	}
+	cleanedJson, err := parseApiResponse(aiModel, respBody)
 	if err != nil {
 		return nil, err
 	}
@@ -77,11 +94,12 @@ func GenerateQuestions(aiModel, language, questionType, keywords string, count i
 	}
 
 	// 验证题目
-	if err := validateQuestions(questions, questionType); err != nil {
+	codeIssues, err := validateQuestions(questions, questionType, language)
+	if err != nil {
 		return nil, err
 	}
 
-	return &GenerateResponse{Questions: questions}, nil
+	return &GenerateResponse{Questions: questions, CodeIssues: codeIssues, Usage: parseUsage(respBody)}, nil
 }
 
 // 构建提示词
@@ -120,9 +138,12 @@ func buildPrompt(language, questionType, keywords string, count int) (string, er
    - 确保JSON格式完全正确
    - 选项value严格遵循2的次幂规则
    - answer字段必须是唯一正确选项的value值
-   - 特别注意: 不允许包含任何Markdown格式标记，如标识json的代码块`,
+   - 特别注意: 整个JSON数组不要用代码块包裹
+
+4. 内容格式：
+%s`,
 			count, language, questionTypeName, keywords,
-			language, keywords, questionTypeName), nil
+			language, keywords, questionTypeName, contentFormatRules(language, "   - ")), nil
 	}
 
 	// 多选题提示词
@@ -154,9 +175,12 @@ func buildPrompt(language, questionType, keywords string, count int) (string, er
    - 确保JSON格式完全正确
    - 选项value严格遵循2的次幂规则
    - answer字段必须是所有正确选项的value总和
-   - 特别注意: 不允许包含任何Markdown格式标记,如标识json的代码块`,
+   - 特别注意: 整个JSON数组不要用代码块包裹
+
+4. 内容格式：
+%s`,
 		count, language, questionTypeName, keywords,
-		language, keywords, questionTypeName), nil
+		language, keywords, questionTypeName, contentFormatRules(language, "   - ")), nil
 }
 
 // 构建请求体
@@ -193,19 +217,18 @@ func sendRequest(requestBody RequestBody) ([]byte, error) {
 		return nil, fmt.Errorf("序列化请求体失败: %v", err)
 	}
 
-	req, err := http.NewRequest("POST",
-		"https://dashscope.aliyuncs.com/api/v1/services/aigc/text-generation/generation",
-		bytes.NewBuffer(jsonData))
+	appConfig := config.GetConfig(false)
+	req, err := http.NewRequest("POST", appConfig.DashScopeBaseURL+textGenerationPath, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %v", err)
 	}
 
-	appConfig := config.GetConfig(false)
 	apiKey := appConfig.DashScopeApiKey
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	// 传输层可按配置切换为录制或回放模式
+	client := &http.Client{Transport: getTransport()}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("发送请求失败: %v", err)
@@ -224,41 +247,70 @@ func sendRequest(requestBody RequestBody) ([]byte, error) {
 	return bodyText, nil
 }
 
-// qwen响应解析
-func parseQwenApiResponse(bodyText []byte) (string, error) {
+// 根据不同的模型选择响应解析方式
+func parseApiResponse(aiModel string, bodyText []byte) (string, error) {
+	switch aiModel {
+	case string(enums.AiModelQwenPlus), string(enums.AiModelDeepSeek):
+		return parseCompletionContent(bodyText)
+	}
+	return "", nil
+}
+
+// completionChoice 响应中的一个候选结果
+type completionChoice struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+}
+
+// parseCompletionContent 解析模型返回的文本，兼容三种响应格式:
+// DashScope 的 result_format=message(output.choices)、result_format=text(output.text)
+// 以及 OpenAI 兼容接口(顶层 choices)
+func parseCompletionContent(bodyText []byte) (string, error) {
 	var apiResponse struct {
 		Output struct {
-			Text string `json:"text"`
+			Text    string             `json:"text"`
+			Choices []completionChoice `json:"choices"`
 		} `json:"output"`
+		Choices []completionChoice `json:"choices"`
 	}
-
 	if err := json.Unmarshal(bodyText, &apiResponse); err != nil {
 		return "", fmt.Errorf("解析API响应失败: %v，响应内容: %s", err, string(bodyText))
 	}
-
-	return apiResponse.Output.Text, nil
+	switch {
+	case len(apiResponse.Output.Choices) > 0:
+		return apiResponse.Output.Choices[0].Message.Content, nil
+	case apiResponse.Output.Text != "":
+		return apiResponse.Output.Text, nil
+	case len(apiResponse.Choices) > 0:
+		return apiResponse.Choices[0].Message.Content, nil
+	}
+	return "", fmt.Errorf("API响应中没有找到有效内容，响应内容: %s", string(bodyText))
 }
 
-// DeepSeek-V3的响应解析
-func parseV3ApiResponse(bodyText []byte) (string, error) {
+// 解析token用量，同时兼容DashScope与OpenAI的字段命名，解析失败时返回空用量
+func parseUsage(bodyText []byte) Usage {
 	var apiResponse struct {
-		Output struct {
-			Choices []struct {
-				Message struct {
-					Content string `json:"content"`
-				} `json:"message"`
-			} `json:"choices"`
-		} `json:"output"`
+		Usage struct {
+			InputTokens      int `json:"input_tokens"`
+			OutputTokens     int `json:"output_tokens"`
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
 	}
 	if err := json.Unmarshal(bodyText, &apiResponse); err != nil {
-		return "", fmt.Errorf("解析API响应失败: %v，响应内容: %s", err, string(bodyText))
+		return Usage{}
+	}
+	usage := Usage{
+		InputTokens:  apiResponse.Usage.InputTokens + apiResponse.Usage.PromptTokens,
+		OutputTokens: apiResponse.Usage.OutputTokens + apiResponse.Usage.CompletionTokens,
+		TotalTokens:  apiResponse.Usage.TotalTokens,
 	}
-	// 检查是否有返回结果
-	if len(apiResponse.Output.Choices) == 0 {
-		return "", fmt.Errorf("API响应中没有找到有效内容，响应内容: %s", string(bodyText))
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.InputTokens + usage.OutputTokens
 	}
-	// 返回第一个选择的内容
-	return apiResponse.Output.Choices[0].Message.Content, nil
+	return usage
 }
 
 // 解析题目数组
@@ -270,20 +322,45 @@ func parseQuestions(cleanedJson string) ([]dto.Question, error) {
 	return questions, nil
 }
 
-// 验证题目是否符合题型要求
-func validateQuestions(questions []dto.Question, questionType string) error {
-	for i, q := range questions {
+// 验证题目是否符合题型要求，并检查题目中的代码能否编译。
+// 格式与题型错误返回 error，代码问题不影响本次生成，按题目下标返回供标记
+func validateQuestions(questions []dto.Question, questionType, language string) ([][]string, error) {
+	codeIssues := make([][]string, len(questions))
+	for i := range questions {
+		q := &questions[i]
+		if err := CleanQuestionContent(&q.Title, q.Options, &q.Explanation); err != nil {
+			return nil, fmt.Errorf("第%d题内容格式错误: %v", i+1, err)
+		}
 		if questionType == "single" {
-			if err := validateSingleQuestion(q, i+1); err != nil {
-				return err
+			if err := validateSingleQuestion(*q, i+1); err != nil {
+				return nil, err
 			}
 		} else {
-			if err := validateMultipleQuestion(q, i+1); err != nil {
-				return err
+			if err := validateMultipleQuestion(*q, i+1); err != nil {
+				return nil, err
 			}
 		}
+		codeIssues[i] = CheckQuestionCode(language, q.Title, q.Options)
 	}
-	return nil
+	return codeIssues, nil
+}
+
+// CheckQuestionCode 检查题干与选项中代码块能否编译，返回发现的问题(目前只支持 Go)
+func CheckQuestionCode(language, title string, options []dto.Option) []string {
+	contents := make([]string, 0, len(options))
+	for _, opt := range options {
+		contents = append(contents, opt.Content)
+	}
+	return codecheck.CheckQuestion(language, title, contents)
+}
+
+// CleanQuestionContent 规范化并校验题干、选项与解析中的 Markdown 内容，结果写回原处
+func CleanQuestionContent(title *string, options []dto.Option, explanation *string) error {
+	fields := []*string{title, explanation}
+	for i := range options {
+		fields = append(fields, &options[i].Content)
+	}
+	return markdown.CleanAll(fields...)
 }
 
 // 验证单选题
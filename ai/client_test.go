@@ -0,0 +1,201 @@
+package ai
+
+import (
+	"aiquiz/models/dto"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixtureDir 测试使用的 fixture 目录(go test 的工作目录为包目录)
+const fixtureDir = "testdata/fixtures"
+
+// fixtureCase 录制 fixture 时使用的生成参数，fixture 按请求内容匹配，修改提示词后需重新录制
+type fixtureCase struct {
+	model, language, questionType, keywords string
+	count                                   int
+}
+
+// 每种响应格式至少一个 fixture
+var (
+	qwenMessage   = fixtureCase{"qwen-plus", "Go", "single", "goroutine", 2}     // DashScope result_format=message
+	v3Message     = fixtureCase{"deepseek-v3", "Python", "multiple", "列表推导式", 2} // DashScope result_format=message，带 reasoning_content
+	qwenText      = fixtureCase{"qwen-plus", "Go", "single", "map", 2}           // DashScope result_format=text，第2题答案不是单个选项的value
+	v3Compatible  = fixtureCase{"deepseek-v3", "Go", "multiple", "接口", 2}        // OpenAI 兼容接口
+	qwenTruncated = fixtureCase{"qwen-plus", "Go", "multiple", "泛型", 2}          // 输出达到长度上限被截断
+	v3Rejected    = fixtureCase{"deepseek-v3", "Go", "single", "unsafe", 2}      // 内容审核未通过，状态码 400
+)
+
+// useReplay 从测试目录回放请求，测试结束后恢复
+func useReplay(t *testing.T) {
+	t.Helper()
+	SetTransport(NewReplayTransport(fixtureDir))
+	t.Cleanup(func() { SetTransport(nil) })
+}
+
+// loadRecorded 读取与生成参数对应的 fixture
+func loadRecorded(t *testing.T, fc fixtureCase) *Fixture {
+	t.Helper()
+	prompt, err := promptTemplates[PromptTemplateDefault](fc.language, fc.questionType, SanitizeKeywords(fc.keywords), fc.count)
+	if err != nil {
+		t.Fatalf("构建提示词失败: %v", err)
+	}
+	body, err := json.Marshal(buildRequestBody(fc.model, fc.language, fc.questionType, prompt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, "http://fixture"+textGenerationPath, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _, err := keyFromRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := LoadFixture(filepath.Join(fixtureDir, key.fileName()))
+	if err != nil {
+		t.Fatalf("读取fixture失败(提示词变化后需重新录制): %v", err)
+	}
+	return fixture
+}
+
+func TestFixturesDoNotContainSecrets(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(fixtureDir, "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("没有找到fixture: %v", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte("Authorization")) || bytes.Contains(data, []byte("sk-")) {
+			t.Errorf("%s 中包含请求头或密钥", filepath.Base(file))
+		}
+	}
+}
+
+func TestParseCompletionContent(t *testing.T) {
+	for _, fc := range []fixtureCase{qwenMessage, v3Message, qwenText, v3Compatible} {
+		fixture := loadRecorded(t, fc)
+		content, err := parseApiResponse(fc.model, []byte(fixture.Response))
+		if err != nil {
+			t.Fatalf("%s %s: 解析失败: %v", fc.model, fc.keywords, err)
+		}
+		questions, err := parseQuestions(content)
+		if err != nil {
+			t.Fatalf("%s %s: 解析题目失败: %v", fc.model, fc.keywords, err)
+		}
+		if len(questions) != fc.count {
+			t.Errorf("%s %s: 期望 %d 道题目，实际 %d 道", fc.model, fc.keywords, fc.count, len(questions))
+		}
+	}
+
+	// 输出被截断时响应本身合法，但题目数组不完整
+	truncated := loadRecorded(t, qwenTruncated)
+	content, err := parseApiResponse(qwenTruncated.model, []byte(truncated.Response))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseQuestions(content); err == nil {
+		t.Error("被截断的题目数组应解析失败")
+	}
+
+	if _, err := parseCompletionContent([]byte(`{"output": {"text": "[`)); err == nil {
+		t.Error("响应体不是合法JSON时应返回错误")
+	}
+	if _, err := parseCompletionContent([]byte(`{"output": {"choices": []}}`)); err == nil {
+		t.Error("没有返回结果时应返回错误")
+	}
+}
+
+func TestValidateQuestions(t *testing.T) {
+	for _, fc := range []fixtureCase{qwenMessage, v3Message, v3Compatible} {
+		fixture := loadRecorded(t, fc)
+		content, err := parseApiResponse(fc.model, []byte(fixture.Response))
+		if err != nil {
+			t.Fatal(err)
+		}
+		questions, err := parseQuestions(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issues, err := validateQuestions(questions, fc.questionType, fc.language)
+		if err != nil {
+			t.Errorf("%s %s: 期望校验通过，实际: %v", fc.model, fc.questionType, err)
+		}
+		if len(issues) != len(questions) {
+			t.Errorf("代码问题应与题目按下标对应")
+		}
+	}
+
+	fixture := loadRecorded(t, qwenText)
+	content, err := parseApiResponse(qwenText.model, []byte(fixture.Response))
+	if err != nil {
+		t.Fatal(err)
+	}
+	questions, err := parseQuestions(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := validateQuestions(questions, "single", "Go"); err == nil || !strings.Contains(err.Error(), "单选题") {
+		t.Errorf("答案不是单个选项时应校验失败，实际: %v", err)
+	}
+
+	options := []dto.Option{{Content: "A", Value: 1}, {Content: "B", Value: 2}, {Content: "C", Value: 4}, {Content: "D", Value: 8}}
+	cases := []struct {
+		name         string
+		questionType string
+		question     dto.Question
+	}{
+		{"单选题选项不足", "single", dto.Question{Title: "题目", Options: options[:3], Answer: 1}},
+		{"多选题只有一个正确选项", "multiple", dto.Question{Title: "题目", Options: options, Answer: 4}},
+		{"多选题答案包含不存在的选项", "multiple", dto.Question{Title: "题目", Options: options, Answer: 19}},
+	}
+	for _, tc := range cases {
+		if _, err := validateQuestions([]dto.Question{tc.question}, tc.questionType, "Go"); err == nil {
+			t.Errorf("%s: 期望校验失败", tc.name)
+		}
+	}
+}
+
+func TestGenerateQuestionsReplay(t *testing.T) {
+	useReplay(t)
+
+	for _, fc := range []fixtureCase{qwenMessage, v3Message, v3Compatible} {
+		res, err := GenerateQuestions(fc.model, fc.language, fc.questionType, fc.keywords, fc.count)
+		if err != nil {
+			t.Fatalf("%s %s: 回放生成失败: %v", fc.model, fc.keywords, err)
+		}
+		if len(res.Questions) != fc.count {
+			t.Errorf("%s %s: 期望 %d 道题目，实际 %d 道", fc.model, fc.keywords, fc.count, len(res.Questions))
+		}
+		if res.Usage.InputTokens == 0 || res.Usage.OutputTokens == 0 || res.Usage.TotalTokens == 0 {
+			t.Errorf("%s %s: 未解析到token用量: %+v", fc.model, fc.keywords, res.Usage)
+		}
+	}
+
+	failures := []struct {
+		fc   fixtureCase
+		want string
+	}{
+		{qwenText, "单选题"},
+		{qwenTruncated, "解析题目数组失败"},
+		{v3Rejected, "状态码: 400"},
+	}
+	for _, f := range failures {
+		_, err := GenerateQuestions(f.fc.model, f.fc.language, f.fc.questionType, f.fc.keywords, f.fc.count)
+		if err == nil || !strings.Contains(err.Error(), f.want) {
+			t.Errorf("%s %s: 期望包含 %q 的错误，实际: %v", f.fc.model, f.fc.keywords, f.want, err)
+		}
+	}
+	// 没有录制过的请求不能访问网络
+	_, err := GenerateQuestions("qwen-plus", "Go", "single", "未录制的关键词", 1)
+	if err == nil || !strings.Contains(err.Error(), "未找到匹配的fixture") {
+		t.Errorf("未录制的请求应返回未找到fixture的错误，实际: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"aiquiz/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// Embedder 文本向量(embedding)的提供方
+type Embedder interface {
+	// Model 使用的模型名称，模型变化后已保存的向量需要重新计算
+	Model() string
+	// Embed 计算各段文本的向量，结果与 texts 按下标对应
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// OpenAIEmbedder 调用 OpenAI 兼容的 /embeddings 接口(如 DashScope 兼容模式)，
+// 地址、密钥与模型每次调用时从配置读取。离线开发可指向 cmd/mockai
+type OpenAIEmbedder struct{}
+
+func NewOpenAIEmbedder() *OpenAIEmbedder {
+	return &OpenAIEmbedder{}
+}
+
+type embeddingRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *OpenAIEmbedder) Model() string {
+	return config.GetConfig(false).EmbeddingModel
+}
+
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	appConfig := config.GetConfig(false)
+	jsonData, err := json.Marshal(embeddingRequest{
+		Model:          appConfig.EmbeddingModel,
+		Input:          texts,
+		Dimensions:     appConfig.EmbeddingDimensions,
+		EncodingFormat: "float",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", appConfig.EmbeddingBaseURL+"/embeddings", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+appConfig.EmbeddingApiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Transport: getTransport()}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	bodyText, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求失败，状态码: %d，响应内容: %s", resp.StatusCode, string(bodyText))
+	}
+
+	var res embeddingResponse
+	if err := json.Unmarshal(bodyText, &res); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	if len(res.Data) != len(texts) {
+		return nil, fmt.Errorf("向量数量与文本数量不一致: %d != %d", len(res.Data), len(texts))
+	}
+	sort.Slice(res.Data, func(i, j int) bool { return res.Data[i].Index < res.Data[j].Index })
+	vectors := make([][]float32, len(res.Data))
+	for i, item := range res.Data {
+		if len(item.Embedding) == 0 {
+			return nil, errors.New("模型返回了空向量")
+		}
+		vectors[i] = item.Embedding
+	}
+	return vectors, nil
+}
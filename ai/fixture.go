@@ -0,0 +1,227 @@
+package ai
+
+import (
+	"aiquiz/config"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 传输模式，由配置 AI_TRANSPORT_MODE 决定
+const (
+	TransportLive   = "live"   // 直接请求模型服务
+	TransportRecord = "record" // 请求模型服务并将交互录制到 fixture 目录
+	TransportReplay = "replay" // 只从 fixture 目录回放，不访问网络
+)
+
+var (
+	transportMu sync.RWMutex
+	transport   http.RoundTripper
+)
+
+// SetTransport 替换发送请求使用的传输层，传入 nil 时恢复为按配置创建
+func SetTransport(rt http.RoundTripper) {
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	transport = rt
+}
+
+// getTransport 获取当前传输层，首次调用时按配置初始化
+func getTransport() http.RoundTripper {
+	transportMu.RLock()
+	rt := transport
+	transportMu.RUnlock()
+	if rt != nil {
+		return rt
+	}
+
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	if transport == nil {
+		appConfig := config.GetConfig(false)
+		switch appConfig.AITransportMode {
+		case TransportRecord:
+			transport = NewRecordingTransport(appConfig.AIFixtureDir, http.DefaultTransport)
+		case TransportReplay:
+			transport = NewReplayTransport(appConfig.AIFixtureDir)
+		default:
+			transport = http.DefaultTransport
+		}
+	}
+	return transport
+}
+
+// Fixture 一次录制的模型交互（已脱敏，不包含任何请求头）
+type Fixture struct {
+	Model      string          `json:"model"`
+	PromptHash string          `json:"prompt_hash"`
+	Parameters json.RawMessage `json:"parameters"`
+	Request    json.RawMessage `json:"request"`
+	StatusCode int             `json:"status_code"`
+	Response   string          `json:"response"`
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+// fixtureKey 匹配 fixture 使用的键：模型、提示词哈希、参数
+type fixtureKey struct {
+	Model      string
+	PromptHash string
+	Parameters json.RawMessage
+}
+
+// fileName fixture 的文件名，参数取哈希前缀以区分同一提示词的不同参数
+func (k fixtureKey) fileName() string {
+	paramSum := sha256.Sum256(k.Parameters)
+	model := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(k.Model)
+	return fmt.Sprintf("%s_%s_%s.json", model, k.PromptHash[:16], hex.EncodeToString(paramSum[:])[:8])
+}
+
+// keyFromRequest 从请求体中提取 fixture 键，并恢复请求体以便后续发送
+func keyFromRequest(req *http.Request) (fixtureKey, []byte, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return fixtureKey{}, nil, fmt.Errorf("读取请求体失败: %v", err)
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	var requestBody RequestBody
+	if err := json.Unmarshal(body, &requestBody); err != nil {
+		return fixtureKey{}, nil, fmt.Errorf("解析请求体失败: %v", err)
+	}
+	// 提示词哈希覆盖所有消息的角色与内容
+	h := sha256.New()
+	for _, m := range requestBody.Input.Messages {
+		h.Write([]byte(m.Role))
+		h.Write([]byte{0})
+		h.Write([]byte(m.Content))
+		h.Write([]byte{0})
+	}
+	params, err := json.Marshal(requestBody.Parameters)
+	if err != nil {
+		return fixtureKey{}, nil, fmt.Errorf("序列化请求参数失败: %v", err)
+	}
+	return fixtureKey{
+		Model:      requestBody.Model,
+		PromptHash: hex.EncodeToString(h.Sum(nil)),
+		Parameters: params,
+	}, body, nil
+}
+
+// RecordingTransport 转发请求并把交互录制到目录中
+type RecordingTransport struct {
+	Dir  string
+	Next http.RoundTripper
+}
+
+func NewRecordingTransport(dir string, next http.RoundTripper) *RecordingTransport {
+	return &RecordingTransport{Dir: dir, Next: next}
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, reqBody, err := keyFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// 脱敏：请求头(含Authorization)不落盘，并抹去正文中可能出现的密钥
+	apiKey := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	fixture := Fixture{
+		Model:      key.Model,
+		PromptHash: key.PromptHash,
+		Parameters: key.Parameters,
+		Request:    json.RawMessage(scrubSecret(reqBody, apiKey)),
+		StatusCode: resp.StatusCode,
+		Response:   string(scrubSecret(respBody, apiKey)),
+		RecordedAt: time.Now(),
+	}
+	if err := writeFixture(t.Dir, key, &fixture); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ReplayTransport 只从目录中回放录制好的交互，找不到时直接返回错误
+type ReplayTransport struct {
+	Dir string
+}
+
+func NewReplayTransport(dir string) *ReplayTransport {
+	return &ReplayTransport{Dir: dir}
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, _, err := keyFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	fixture, err := LoadFixture(filepath.Join(t.Dir, key.fileName()))
+	if err != nil {
+		return nil, fmt.Errorf("未找到匹配的fixture(model=%s, prompt=%s): %v", key.Model, key.PromptHash[:16], err)
+	}
+	return &http.Response{
+		StatusCode:    fixture.StatusCode,
+		Status:        fmt.Sprintf("%d %s", fixture.StatusCode, http.StatusText(fixture.StatusCode)),
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(fixture.Response)),
+		ContentLength: int64(len(fixture.Response)),
+		Request:       req,
+	}, nil
+}
+
+// LoadFixture 读取单个 fixture 文件
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("解析fixture失败: %v", err)
+	}
+	return &fixture, nil
+}
+
+func writeFixture(dir string, key fixtureKey, fixture *Fixture) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建fixture目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化fixture失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, key.fileName()), data, 0o644); err != nil {
+		return fmt.Errorf("写入fixture失败: %v", err)
+	}
+	return nil
+}
+
+func scrubSecret(data []byte, secret string) []byte {
+	if secret == "" {
+		return data
+	}
+	return bytes.ReplaceAll(data, []byte(secret), []byte("***"))
+}
@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"aiquiz/models/dto"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxKnowledgePointsPerQuestion 每道题目最多关联的知识点数量
+const maxKnowledgePointsPerQuestion = 3
+
+// KnowledgePointOption 可供模型选择的知识点，Path 为从顶层知识点开始的完整路径
+type KnowledgePointOption struct {
+	ID   int
+	Path string
+}
+
+// ClassifyKnowledgePoints 调用模型为题目选择考查的知识点，结果与 questions 按下标对应。
+// 模型返回的不在 options 中的知识点会被忽略
+func ClassifyKnowledgePoints(aiModel, language string, options []KnowledgePointOption, questions []dto.Question) ([][]int, error) {
+	valid := make(map[int]bool, len(options))
+	var points strings.Builder
+	for _, opt := range options {
+		valid[opt.ID] = true
+		fmt.Fprintf(&points, "[%d] %s\n", opt.ID, opt.Path)
+	}
+	var content strings.Builder
+	for i, q := range questions {
+		fmt.Fprintf(&content, "<question index=\"%d\">\n%s\n", i+1, q.Title)
+		for _, opt := range q.Options {
+			content.WriteString("- " + opt.Content + "\n")
+		}
+		content.WriteString("</question>\n")
+	}
+
+	requestBody := RequestBody{
+		Model: aiModel,
+		Input: Input{
+			Messages: []Message{
+				{
+					Role:    "system",
+					Content: fmt.Sprintf("你是%s编程课程的教研员，负责为题目标注考查的知识点。", language),
+				},
+				{
+					Role: "user",
+					Content: fmt.Sprintf("知识点列表(方括号中为知识点ID):\n%s\n"+
+						"请为以下每道题目选择1到%d个最具体的考查知识点，只能使用列表中的ID，没有合适的知识点时返回空数组。"+
+						"仅返回JSON数组，如 [{\"index\": 1, \"ids\": [3, 5]}]，不要输出其他内容。\n\n%s",
+						points.String(), maxKnowledgePointsPerQuestion, content.String()),
+				},
+			},
+		},
+		Parameters: Parameters{ResultFormat: "message"},
+	}
+	respBody, err := sendRequest(requestBody)
+	if err != nil {
+		return nil, err
+	}
+	text, err := parseApiResponse(aiModel, respBody)
+	if err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	if start, end := strings.Index(text, "["), strings.LastIndex(text, "]"); start >= 0 && end > start {
+		text = text[start : end+1]
+	}
+	var items []struct {
+		Index int               `json:"index"`
+		IDs   []json.RawMessage `json:"ids"`
+	}
+	if err := json.Unmarshal([]byte(text), &items); err != nil {
+		return nil, fmt.Errorf("解析知识点分类结果失败: %v", err)
+	}
+
+	result := make([][]int, len(questions))
+	for _, item := range items {
+		i := item.Index - 1
+		if i < 0 || i >= len(questions) {
+			continue
+		}
+		for _, raw := range item.IDs {
+			// 兼容模型将ID返回为字符串的情况
+			id, err := strconv.Atoi(strings.Trim(string(raw), `"`))
+			if err != nil || !valid[id] || slices.Contains(result[i], id) {
+				continue
+			}
+			if len(result[i]) < maxKnowledgePointsPerQuestion {
+				result[i] = append(result[i], id)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"aiquiz/utils/markdown"
+)
+
+// 提示词模板名称
+const (
+	PromptTemplateDefault    = "default"
+	PromptTemplateStructured = "structured"
+)
+
+type promptBuilder func(language, questionType, keywords string, count int) (string, error)
+
+// promptTemplates 所有可用的提示词模板，实验中的对照组始终使用 default
+var promptTemplates = map[string]promptBuilder{
+	PromptTemplateDefault:    buildPrompt,
+	PromptTemplateStructured: buildStructuredPrompt,
+}
+
+// IsSupportedPromptTemplate 检查提示词模板是否存在
+func IsSupportedPromptTemplate(name string) bool {
+	_, ok := promptTemplates[name]
+	return ok
+}
+
+// buildStructuredPrompt 先列考查点再出题，并要求干扰项来自常见误区
+func buildStructuredPrompt(language, questionType, keywords string, count int) (string, error) {
+	var typeRule, answerRule string
+	switch questionType {
+	case "single":
+		typeRule = "单项选择题，每题有且只有1个正确选项"
+		answerRule = "answer为唯一正确选项的value"
+	case "multiple":
+		typeRule = "多项选择题，每题至少2个、至多3个正确选项"
+		answerRule = "answer为所有正确选项value之和"
+	default:
+		return "", fmt.Errorf("不支持的题目类型: %s", questionType)
+	}
+
+	return fmt.Sprintf(`你需要为%s编程语言出%d道%s，主题为"%s"。
+
+出题步骤（只在内部思考，不要输出）：
+1. 先列出该主题下%d个互不重复的考查点，每题只考查一个考查点
+2. 针对每个考查点，写出学习者最常见的误解，用这些误解构造干扰项
+3. 核对正确选项确实正确、干扰项确实错误
+
+输出要求：
+- 只输出一个JSON数组，不要输出考查点列表、思考过程或任何其他文字，整个数组不要用代码块包裹
+- 数组元素结构: {"title": "完整的问题", "options": [{"content": "选项内容", "value": 1}, {"content": "选项内容", "value": 2}, {"content": "选项内容", "value": 4}, {"content": "选项内容", "value": 8}], "answer": 整数, "explanation": "解析"}
+- 每题恰好4个选项，value依次为1、2、4、8
+- %s
+- explanation需逐一说明每个选项正确或错误的原因，不要提及value
+%s`,
+		language, count, typeRule, keywords, count, answerRule, contentFormatRules(language, "- ")), nil
+}
+
+// contentFormatRules 题目内容可使用的 Markdown 子集说明，每条规则前加上 prefix。
+// 代码块的语言标记与题目语言一致，服务端按同样的子集校验与渲染
+func contentFormatRules(language, prefix string) string {
+	tag := markdown.LanguageTag(language)
+	rules := []string{
+		"title、选项content和explanation可以使用Markdown子集: **加粗**、*斜体*、`行内代码`、以- 或1. 开头的列表，其余Markdown语法(标题、链接、图片、表格、HTML)都不要使用",
+		"代码必须放在代码块中: 单独一行```" + tag + "开始，单独一行```结束，JSON字符串中的换行写作\\n",
+		"考查代码阅读、输出结果或错误定位的题目，应在title中给出完整的" + language + "代码片段，选项中的短代码使用`行内代码`",
+	}
+	return prefix + strings.Join(rules, "\n"+prefix)
+}
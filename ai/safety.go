@@ -0,0 +1,175 @@
+package ai
+
+import (
+	"aiquiz/config"
+	"aiquiz/models/dto"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// 内容审核未通过时的处理方式
+const (
+	ModerationReject = "reject" // 直接拒绝
+	ModerationFlag   = "flag"   // 标记原因，交由教师在确认时判断
+)
+
+// injectionPatterns 常见的提示词注入写法，只匹配指令式的表述，
+// 避免误伤 "file system: inode"、"system message queue"、"Linux 系统指令" 这类正常的编程主题
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)ignore\s+(all\s+)?(the\s+)?(previous|above|prior)`),
+	regexp.MustCompile(`(?i)disregard\s+(all\s+)?(the\s+)?(previous|above|instructions)`),
+	regexp.MustCompile(`(?i)\b(reveal|show|print|output|repeat|override|forget|ignore)\s+(me\s+)?(your|the)\s+(system|developer|hidden)\s+(prompt|message|instructions?)`),
+	regexp.MustCompile(`(?i)\b(new|updated)\s+(system|developer)\s+(prompt|instructions?)\s*:`),
+	regexp.MustCompile(`(?im)^\s*(system|developer|assistant)\s*:`),
+	regexp.MustCompile(`(?i)\b(you\s+are\s+now|from\s+now\s+on\s+you|pretend\s+(to\s+be|you\s+are)|(you|please)\s+(must\s+|should\s+)?act\s+as)\b`),
+	regexp.MustCompile(`(忽略|无视|忘记|不要遵守|跳过).{0,6}(之前|以上|上面|前面|所有|全部).{0,6}(指令|要求|规则|提示|设定)`),
+	regexp.MustCompile(`(你现在是|从现在起你|你的新身份|假装你是|请你?扮演)`),
+	regexp.MustCompile(`(输出|返回|打印|显示|泄露|告诉我).{0,6}(系统提示词|提示词|系统消息|系统指令|指令内容)`),
+	regexp.MustCompile("```|<\\s*/?\\s*(system|user|assistant)\\s*>"),
+}
+
+// exampleEmailDomain RFC 2606 保留的示例域名，题目中常用作示例邮箱，不视为个人信息
+var exampleEmailDomain = regexp.MustCompile(`(?i)@([A-Za-z0-9-]+\.)*(example\.(com|net|org)|[A-Za-z0-9-]+\.(example|test|invalid|localhost))$|@(example|test|invalid|localhost)$`)
+
+// piiPatterns 需要拦截的个人敏感信息，exempt 用于排除不属于个人信息的匹配
+var piiPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+	exempt  func(match string) bool
+}{
+	{"邮箱地址", regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), exampleEmailDomain.MatchString},
+	{"手机号码", regexp.MustCompile(`(^|\D)1[3-9]\d{9}($|\D)`), nil},
+	{"身份证号码", regexp.MustCompile(`(^|\D)[1-9]\d{5}(19|20)\d{2}(0[1-9]|1[0-2])(0[1-9]|[12]\d|3[01])\d{3}[\dXx]($|\D)`), nil},
+	{"API密钥", regexp.MustCompile(`\b(sk|ak)-[A-Za-z0-9]{20,}\b`), nil},
+}
+
+// containsPII 内容中是否有不属于例外的匹配
+func containsPII(pattern *regexp.Regexp, exempt func(string) bool, content string) bool {
+	for _, match := range pattern.FindAllString(content, -1) {
+		if exempt == nil || !exempt(match) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckKeywords 校验出题关键词：长度、控制字符、异常字符比例以及提示词注入
+func CheckKeywords(keywords string) error {
+	appConfig := config.GetConfig(false)
+	runes := []rune(strings.TrimSpace(keywords))
+	if len(runes) == 0 {
+		return fmt.Errorf("关键词不能为空")
+	}
+	if len(runes) > appConfig.KeywordsMaxLength {
+		return fmt.Errorf("关键词长度不能超过%d个字符", appConfig.KeywordsMaxLength)
+	}
+
+	odd := 0
+	for _, r := range runes {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("关键词包含控制字符")
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r) && !strings.ContainsRune("+#.-_/、，,()（）", r) {
+			odd++
+		}
+	}
+	// 允许 C++、C# 之类的写法，但符号占比过高通常不是正常的主题
+	if odd*3 > len(runes) {
+		return fmt.Errorf("关键词包含过多特殊字符")
+	}
+
+	for _, pattern := range injectionPatterns {
+		if pattern.MatchString(keywords) {
+			return fmt.Errorf("关键词疑似包含提示词注入内容")
+		}
+	}
+	return nil
+}
+
+// SanitizeKeywords 移除会破坏提示词结构的字符(引号、换行、反引号)，作为插入提示词前的最后防线
+func SanitizeKeywords(keywords string) string {
+	replacer := strings.NewReplacer(`"`, "", "“", "", "”", "", "`", "", "\r", " ", "\n", " ", "\t", " ")
+	return strings.Join(strings.Fields(replacer.Replace(keywords)), " ")
+}
+
+// ModerateQuestion 审核单道题目，返回未通过的原因，为空表示通过。
+// useModel 为 true 且配置了审核模型时，会额外调用模型进行审核
+func ModerateQuestion(q dto.Question, useModel bool) []string {
+	appConfig := config.GetConfig(false)
+	texts := []string{q.Title, q.Explanation}
+	for _, opt := range q.Options {
+		texts = append(texts, opt.Content)
+	}
+	content := strings.Join(texts, "\n")
+	lower := strings.ToLower(content)
+
+	var reasons []string
+	for _, term := range appConfig.ModerationBannedTerms {
+		if strings.Contains(lower, strings.ToLower(term)) {
+			reasons = append(reasons, fmt.Sprintf("包含违禁词: %s", term))
+		}
+	}
+	for _, pii := range piiPatterns {
+		if containsPII(pii.pattern, pii.exempt, content) {
+			reasons = append(reasons, fmt.Sprintf("疑似包含%s", pii.name))
+		}
+	}
+	if useModel && appConfig.ModerationModel != "" {
+		if reason, err := moderateByModel(appConfig.ModerationModel, content); err != nil {
+			reasons = append(reasons, "模型审核失败: "+err.Error())
+		} else if reason != "" {
+			reasons = append(reasons, "模型审核未通过: "+reason)
+		}
+	}
+	return reasons
+}
+
+// moderateByModel 调用审核模型，返回不通过的原因
+func moderateByModel(aiModel, content string) (string, error) {
+	requestBody := RequestBody{
+		Model: aiModel,
+		Input: Input{
+			Messages: []Message{
+				{
+					Role:    "system",
+					Content: "你是编程题库的内容审核员。只判断给定文本是否包含违法、色情、暴力、歧视、政治敏感、个人隐私或与编程教学无关的不当内容。",
+				},
+				{
+					Role: "user",
+					Content: "请审核以下题目内容，仅返回JSON对象 {\"safe\": true或false, \"reason\": \"不安全时的简短原因\"}，不要输出其他内容。\n\n" +
+						"<content>\n" + content + "\n</content>",
+				},
+			},
+		},
+		Parameters: Parameters{ResultFormat: "message"},
+	}
+	respBody, err := sendRequest(requestBody)
+	if err != nil {
+		return "", err
+	}
+	text, err := parseApiResponse(aiModel, respBody)
+	if err != nil {
+		return "", err
+	}
+	var verdict struct {
+		Safe   bool   `json:"safe"`
+		Reason string `json:"reason"`
+	}
+	text = strings.TrimSpace(text)
+	if start, end := strings.Index(text, "{"), strings.LastIndex(text, "}"); start >= 0 && end > start {
+		text = text[start : end+1]
+	}
+	if err := json.Unmarshal([]byte(text), &verdict); err != nil {
+		return "", fmt.Errorf("解析审核结果失败: %v", err)
+	}
+	if verdict.Safe {
+		return "", nil
+	}
+	if verdict.Reason == "" {
+		verdict.Reason = "内容不安全"
+	}
+	return verdict.Reason, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"aiquiz/models/dto"
+	"fmt"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// duplicateThreshold 标题字符二元组的Jaccard相似度达到该值视为重复
+const duplicateThreshold = 0.8
+
+// verifyAnswer 在结构校验之外进一步核对答案：选项值互不相同且为2的次幂、
+// 答案只包含已有选项、单选恰好一个正确项、选项内容不重复且解析不为空
+func verifyAnswer(q dto.Question, questionType string) []string {
+	var problems []string
+	var all int
+	contents := make(map[string]struct{}, len(q.Options))
+	for _, opt := range q.Options {
+		if opt.Value <= 0 || bits.OnesCount(uint(opt.Value)) != 1 {
+			problems = append(problems, fmt.Sprintf("选项value %d 不是2的次幂", opt.Value))
+		}
+		if all&opt.Value != 0 {
+			problems = append(problems, fmt.Sprintf("选项value %d 重复", opt.Value))
+		}
+		all |= opt.Value
+
+		content := normalize(opt.Content)
+		if content == "" {
+			problems = append(problems, "存在空选项")
+		} else if _, ok := contents[content]; ok {
+			problems = append(problems, fmt.Sprintf("选项内容重复: %s", opt.Content))
+		}
+		contents[content] = struct{}{}
+	}
+	if q.Answer&^all != 0 {
+		problems = append(problems, "答案包含不存在的选项")
+	}
+	correct := bits.OnesCount(uint(q.Answer & all))
+	if questionType == "single" && correct != 1 {
+		problems = append(problems, fmt.Sprintf("单选题正确选项数量为%d", correct))
+	}
+	if questionType == "multiple" && correct == len(q.Options) {
+		problems = append(problems, "多选题所有选项均为正确答案")
+	}
+	if strings.TrimSpace(q.Explanation) == "" {
+		problems = append(problems, "缺少解析")
+	}
+	return problems
+}
+
+// countDuplicates 统计与之前出现过的题目高度相似的题目数量
+func countDuplicates(seen *[]map[string]struct{}, questions []dto.Question) int {
+	duplicates := 0
+	for _, q := range questions {
+		grams := bigrams(q.Title)
+		for _, prev := range *seen {
+			if jaccard(grams, prev) >= duplicateThreshold {
+				duplicates++
+				break
+			}
+		}
+		*seen = append(*seen, grams)
+	}
+	return duplicates
+}
+
+// normalize 去掉空白与标点并统一小写
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func bigrams(s string) map[string]struct{} {
+	runes := []rune(normalize(s))
+	grams := make(map[string]struct{}, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])] = struct{}{}
+	}
+	return grams
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	inter := 0
+	for g := range a {
+		if _, ok := b[g]; ok {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"aiquiz/ai"
+	"aiquiz/utils/enums"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 离线的生成质量评测工具，切换默认模型前用于对比各模型的表现。
+// 使用方式:
+//   回放已录制的交互: go run ./cmd/evalai -mode replay -fixtures ./ai/testdata/fixtures
+//   对接本地桩服务:   go run ./cmd/evalai -base-url http://localhost:8090
+//   录制真实交互:     go run ./cmd/evalai -mode record -fixtures ./ai/testdata/fixtures
+// 录制时会把评测集写入 fixture 目录的 suite.json，回放未指定 -suite 时使用该评测集
+
+// SpecResult 单个模型在单条规格上的评测结果
+type SpecResult struct {
+	Spec            Spec     `json:"spec"`
+	Valid           bool     `json:"valid"`
+	Attempts        int      `json:"attempts"`
+	LatencyMs       int64    `json:"latency_ms"`
+	InputTokens     int      `json:"input_tokens"`
+	OutputTokens    int      `json:"output_tokens"`
+	QuestionCount   int      `json:"question_count"`
+	DuplicateCount  int      `json:"duplicate_count"`
+	AnswerIssues    []string `json:"answer_issues,omitempty"`
+	AnswerIssueRate float64  `json:"answer_issue_rate"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+// ModelReport 单个模型的汇总结果
+type ModelReport struct {
+	Model             string       `json:"model"`
+	ValidityRate      float64      `json:"validity_rate"` // 结构有效的响应占全部尝试的比例
+	SuccessRate       float64      `json:"success_rate"`  // 在最大尝试次数内成功的规格比例
+	AvgRetries        float64      `json:"avg_retries"`
+	AvgLatencyMs      int64        `json:"avg_latency_ms"`
+	P95LatencyMs      int64        `json:"p95_latency_ms"`
+	TotalTokens       int          `json:"total_tokens"`
+	TokensPerQuestion float64      `json:"tokens_per_question"`
+	DuplicateRate     float64      `json:"duplicate_rate"`
+	AnswerIssueRate   float64      `json:"answer_issue_rate"`
+	Results           []SpecResult `json:"results"`
+}
+
+// Report 评测报告
+type Report struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Mode        string        `json:"mode"`
+	MaxAttempts int           `json:"max_attempts"`
+	Suite       []Spec        `json:"suite"`
+	Models      []ModelReport `json:"models"`
+}
+
+func main() {
+	mode := flag.String("mode", ai.TransportLive, "传输模式: live / record / replay")
+	fixtures := flag.String("fixtures", "./ai/testdata/fixtures", "fixture 目录(record/replay 模式使用)")
+	baseURL := flag.String("base-url", "", "模型服务地址，覆盖 DASHSCOPE_BASE_URL，可指向 cmd/mockai")
+	models := flag.String("models", "", "参与评测的模型，逗号分隔，默认全部支持的模型")
+	suitePath := flag.String("suite", "", "评测集JSON文件，默认使用内置评测集")
+	maxAttempts := flag.Int("max-attempts", 3, "每条规格的最大尝试次数(含首次)")
+	outDir := flag.String("out", "./eval_report", "报告输出目录")
+	flag.Parse()
+
+	if *baseURL != "" {
+		os.Setenv("DASHSCOPE_BASE_URL", *baseURL)
+	}
+	os.Setenv("AI_TRANSPORT_MODE", *mode)
+	os.Setenv("AI_FIXTURE_DIR", *fixtures)
+	if *maxAttempts < 1 {
+		*maxAttempts = 1
+	}
+
+	var suite []Spec
+	var err error
+	if *suitePath == "" && *mode == ai.TransportReplay {
+		suite, err = loadReplaySuite(*fixtures)
+	} else {
+		suite, err = loadSuite(*suitePath)
+	}
+	if err != nil {
+		log.Fatalf("加载评测集失败: %v", err)
+	}
+
+	var modelList []string
+	if *models != "" {
+		for _, m := range strings.Split(*models, ",") {
+			m = strings.TrimSpace(m)
+			if !enums.IsSupportedAiModel(enums.AiModel(m)) {
+				log.Fatalf("不支持的模型: %s", m)
+			}
+			modelList = append(modelList, m)
+		}
+	} else {
+		for m := range enums.SupportedAiModels {
+			modelList = append(modelList, string(m))
+		}
+		sort.Strings(modelList)
+	}
+
+	report := Report{
+		GeneratedAt: time.Now(),
+		Mode:        *mode,
+		MaxAttempts: *maxAttempts,
+		Suite:       suite,
+	}
+	for _, model := range modelList {
+		log.Printf("开始评测模型 %s", model)
+		report.Models = append(report.Models, evaluateModel(model, suite, *maxAttempts))
+	}
+
+	if *mode == ai.TransportRecord {
+		if err := saveRecordedSuite(*fixtures, suite, modelList); err != nil {
+			log.Fatalf("写入录制的评测集失败: %v", err)
+		}
+	}
+	if err := writeReport(*outDir, &report); err != nil {
+		log.Fatalf("写入报告失败: %v", err)
+	}
+	log.Printf("评测完成，报告已写入 %s", *outDir)
+}
+
+// evaluateModel 对单个模型依次运行评测集中的所有规格
+func evaluateModel(model string, suite []Spec, maxAttempts int) ModelReport {
+	report := ModelReport{Model: model}
+	// 同一模型生成的所有题目共同参与查重
+	var seen []map[string]struct{}
+	var latencies []int64
+	var valid, attempts, retries, questions, duplicates, answerIssues int
+
+	var specs []Spec
+	for _, spec := range suite {
+		if spec.appliesTo(model) {
+			specs = append(specs, spec)
+		}
+	}
+	for _, spec := range specs {
+		result := SpecResult{Spec: spec}
+		start := time.Now()
+		var resp *ai.GenerateResponse
+		for result.Attempts < maxAttempts {
+			result.Attempts++
+			r, err := ai.GenerateQuestions(model, spec.Language, spec.QuestionType, spec.Keywords, spec.Count)
+			if err != nil {
+				result.Errors = append(result.Errors, err.Error())
+				continue
+			}
+			resp = r
+			break
+		}
+		result.LatencyMs = time.Since(start).Milliseconds()
+		latencies = append(latencies, result.LatencyMs)
+		attempts += result.Attempts
+		retries += result.Attempts - 1
+
+		if resp != nil {
+			result.Valid = len(resp.Questions) == spec.Count
+			if !result.Valid {
+				result.Errors = append(result.Errors, "返回的题目数量与要求不一致")
+			}
+			result.InputTokens = resp.Usage.InputTokens
+			result.OutputTokens = resp.Usage.OutputTokens
+			result.QuestionCount = len(resp.Questions)
+			result.DuplicateCount = countDuplicates(&seen, resp.Questions)
+
+			issueQuestions := 0
+			for i, q := range resp.Questions {
+				problems := verifyAnswer(q, spec.QuestionType)
+				if len(problems) > 0 {
+					issueQuestions++
+					result.AnswerIssues = append(result.AnswerIssues, formatIssue(i+1, problems))
+				}
+			}
+			if result.QuestionCount > 0 {
+				result.AnswerIssueRate = float64(issueQuestions) / float64(result.QuestionCount)
+			}
+			answerIssues += issueQuestions
+		}
+		if result.Valid {
+			valid++
+		}
+		questions += result.QuestionCount
+		duplicates += result.DuplicateCount
+		report.TotalTokens += result.InputTokens + result.OutputTokens
+		report.Results = append(report.Results, result)
+		log.Printf("  %s: valid=%v attempts=%d latency=%dms", spec, result.Valid, result.Attempts, result.LatencyMs)
+	}
+
+	n := len(specs)
+	if n > 0 {
+		// 每条规格最多只有最后一次尝试成功，其余尝试均为结构无效
+		report.ValidityRate = float64(valid) / float64(attempts)
+		report.SuccessRate = float64(valid) / float64(n)
+		report.AvgRetries = float64(retries) / float64(n)
+		var sum int64
+		for _, l := range latencies {
+			sum += l
+		}
+		report.AvgLatencyMs = sum / int64(n)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		report.P95LatencyMs = latencies[(len(latencies)*95-1)/100]
+	}
+	if questions > 0 {
+		report.TokensPerQuestion = float64(report.TotalTokens) / float64(questions)
+		report.DuplicateRate = float64(duplicates) / float64(questions)
+		report.AnswerIssueRate = float64(answerIssues) / float64(questions)
+	}
+	return report
+}
+
+func formatIssue(index int, problems []string) string {
+	return fmt.Sprintf("第%d题: %s", index, strings.Join(problems, "; "))
+}
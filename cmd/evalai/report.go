@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeReport 同时输出 report.json 与 report.md
+func writeReport(dir string, report *Report) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), data, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "report.md"), []byte(renderMarkdown(report)), 0o644)
+}
+
+func renderMarkdown(report *Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# AI 出题评测报告\n\n")
+	fmt.Fprintf(&b, "- 生成时间: %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- 传输模式: %s\n", report.Mode)
+	fmt.Fprintf(&b, "- 每条规格最大尝试次数: %d\n", report.MaxAttempts)
+	fmt.Fprintf(&b, "- 规格数量: %d\n\n", len(report.Suite))
+
+	b.WriteString("## 模型对比\n\n")
+	b.WriteString("| 模型 | 结构有效率 | 成功率 | 平均重试 | 平均延迟(ms) | P95延迟(ms) | 总token | 每题token | 重复率 | 答案问题率 |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|---|---|\n")
+	for _, m := range report.Models {
+		fmt.Fprintf(&b, "| %s | %.1f%% | %.1f%% | %.2f | %d | %d | %d | %.1f | %.1f%% | %.1f%% |\n",
+			m.Model, m.ValidityRate*100, m.SuccessRate*100, m.AvgRetries, m.AvgLatencyMs, m.P95LatencyMs,
+			m.TotalTokens, m.TokensPerQuestion, m.DuplicateRate*100, m.AnswerIssueRate*100)
+	}
+
+	for _, m := range report.Models {
+		fmt.Fprintf(&b, "\n## %s 明细\n\n", m.Model)
+		b.WriteString("| 规格 | 有效 | 尝试次数 | 延迟(ms) | 输入/输出token | 题数 | 重复 | 答案问题率 |\n")
+		b.WriteString("|---|---|---|---|---|---|---|---|\n")
+		for _, r := range m.Results {
+			valid := "否"
+			if r.Valid {
+				valid = "是"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %d | %d | %d/%d | %d | %d | %.1f%% |\n",
+				r.Spec, valid, r.Attempts, r.LatencyMs, r.InputTokens, r.OutputTokens,
+				r.QuestionCount, r.DuplicateCount, r.AnswerIssueRate*100)
+		}
+		for _, r := range m.Results {
+			if len(r.Errors) == 0 && len(r.AnswerIssues) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "\n**%s**\n\n", r.Spec)
+			for _, e := range r.Errors {
+				fmt.Fprintf(&b, "- 错误: %s\n", truncate(e, 200))
+			}
+			for _, issue := range r.AnswerIssues {
+				fmt.Fprintf(&b, "- %s\n", issue)
+			}
+		}
+	}
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// suiteFileName 录制时写入 fixture 目录的评测集，回放时默认使用，保证每条规格都有对应的 fixture
+const suiteFileName = "suite.json"
+
+// Spec 一条生成规格，对应一次 ai.GenerateQuestions 调用
+type Spec struct {
+	Language     string   `json:"language"`
+	QuestionType string   `json:"question_type"`
+	Keywords     string   `json:"keywords"`
+	Count        int      `json:"count"`
+	Models       []string `json:"models,omitempty"` // 只在这些模型上评测(回放时为录制过的模型)，为空表示全部模型
+}
+
+// appliesTo 规格是否需要在该模型上评测
+func (s Spec) appliesTo(model string) bool {
+	return len(s.Models) == 0 || slices.Contains(s.Models, model)
+}
+
+// sameRequest 两条规格是否发出相同的生成请求
+func (s Spec) sameRequest(o Spec) bool {
+	return s.Language == o.Language && s.QuestionType == o.QuestionType && s.Keywords == o.Keywords && s.Count == o.Count
+}
+
+func (s Spec) String() string {
+	return fmt.Sprintf("%s/%s/%s x%d", s.Language, s.QuestionType, s.Keywords, s.Count)
+}
+
+// defaultSuite 固定的评测集，覆盖常用语言、两种题型和不同的主题粒度
+var defaultSuite = []Spec{
+	{Language: "Go", QuestionType: "single", Keywords: "goroutine 与 channel", Count: 5},
+	{Language: "Go", QuestionType: "multiple", Keywords: "Gin 框架", Count: 5},
+	{Language: "Go", QuestionType: "single", Keywords: "切片与map", Count: 3},
+	{Language: "Python", QuestionType: "single", Keywords: "装饰器", Count: 5},
+	{Language: "Python", QuestionType: "multiple", Keywords: "生成器与迭代器", Count: 3},
+	{Language: "Java", QuestionType: "single", Keywords: "JVM 内存模型", Count: 5},
+	{Language: "Java", QuestionType: "multiple", Keywords: "集合框架", Count: 3},
+	{Language: "JavaScript", QuestionType: "single", Keywords: "事件循环", Count: 3},
+}
+
+// loadSuite 从JSON文件读取评测集，未指定时使用内置评测集
+func loadSuite(path string) ([]Spec, error) {
+	if path == "" {
+		return defaultSuite, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var specs []Spec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("解析评测集失败: %v", err)
+	}
+	for i, spec := range specs {
+		if spec.QuestionType != "single" && spec.QuestionType != "multiple" {
+			return nil, fmt.Errorf("第%d条规格的题型无效: %s", i+1, spec.QuestionType)
+		}
+		if spec.Count < 1 || spec.Count > 10 {
+			return nil, fmt.Errorf("第%d条规格的题目数量必须在1到10之间", i+1)
+		}
+	}
+	return specs, nil
+}
+
+// loadReplaySuite 读取 fixture 目录中录制时写入的评测集
+func loadReplaySuite(dir string) ([]Spec, error) {
+	path := filepath.Join(dir, suiteFileName)
+	specs, err := loadSuite(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("fixture目录中没有评测集 %s，请先以 record 模式录制，或通过 -suite 指定已录制的评测集", path)
+	}
+	return specs, err
+}
+
+// saveRecordedSuite 将本次录制的规格与模型合并写入 fixture 目录中的评测集，供回放使用
+func saveRecordedSuite(dir string, suite []Spec, models []string) error {
+	recorded, err := loadSuite(filepath.Join(dir, suiteFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for _, spec := range suite {
+		var applied []string
+		for _, model := range models {
+			if spec.appliesTo(model) {
+				applied = append(applied, model)
+			}
+		}
+		i := slices.IndexFunc(recorded, spec.sameRequest)
+		if i < 0 {
+			spec.Models = nil
+			recorded = append(recorded, spec)
+			i = len(recorded) - 1
+		}
+		for _, model := range applied {
+			if !slices.Contains(recorded[i].Models, model) {
+				recorded[i].Models = append(recorded[i].Models, model)
+			}
+		}
+		slices.Sort(recorded[i].Models)
+	}
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, suiteFileName), append(data, '\n'), 0o644)
+}
@@ -57,6 +57,26 @@ func main() {
 		&model.Paper{},
 		&model.Question{},
 		&model.PaperQuestion{},
+		&model.Experiment{},
+		&model.GenerationRecord{},
+		&model.Tag{},
+		&model.TagAlias{},
+		&model.QuestionTag{},
+		&model.QuestionRevision{},
+		&model.QuestionReview{},
+		&model.QuestionComment{},
+		&model.QuestionReport{},
+		&model.Collection{},
+		&model.CollectionQuestion{},
+		&model.Attachment{},
+		&model.QuestionAttachment{},
+		&model.CodeRun{},
+		&model.QuestionFingerprint{},
+		&model.QuestionEmbedding{},
+		&model.KnowledgePoint{},
+		&model.QuestionKnowledgePoint{},
+		&model.Syllabus{},
+		&model.SyllabusTopic{},
 	)
 
 	// 执行代码生成
@@ -24,6 +24,26 @@ func main() {
 		&model.Question{},
 		&model.Paper{},
 		&model.PaperQuestion{},
+		&model.Experiment{},
+		&model.GenerationRecord{},
+		&model.Tag{},
+		&model.TagAlias{},
+		&model.QuestionTag{},
+		&model.QuestionRevision{},
+		&model.QuestionReview{},
+		&model.QuestionComment{},
+		&model.QuestionReport{},
+		&model.Collection{},
+		&model.CollectionQuestion{},
+		&model.Attachment{},
+		&model.QuestionAttachment{},
+		&model.CodeRun{},
+		&model.QuestionFingerprint{},
+		&model.QuestionEmbedding{},
+		&model.KnowledgePoint{},
+		&model.QuestionKnowledgePoint{},
+		&model.Syllabus{},
+		&model.SyllabusTopic{},
 	)
 	if err != nil {
 		panic(fmt.Errorf("建表失败: %v", err))
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"aiquiz/models/dto"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"hash/fnv"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// 离线的 DashScope / OpenAI 兼容桩服务，用于本地开发与集成测试。
+// 使用方式: go run ./cmd/mockai -addr :8090，然后将 DASHSCOPE_BASE_URL 设置为 http://localhost:8090
+
+// Message 请求中的对话消息
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// dashScopeReq 通义千问文本生成接口请求体
+type dashScopeReq struct {
+	Model string `json:"model"`
+	Input struct {
+		Messages []Message `json:"messages"`
+	} `json:"input"`
+}
+
+// chatCompletionReq OpenAI chat completions 接口请求体
+type chatCompletionReq struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+}
+
+// embeddingReq OpenAI embeddings 接口请求体
+type embeddingReq struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions"`
+}
+
+// defaultEmbeddingDimensions 请求未指定维度时返回的向量维度
+const defaultEmbeddingDimensions = 256
+
+// ScriptStep 脚本中的一步，按顺序依次消费，消费完后回退到默认的题目生成
+type ScriptStep struct {
+	// Fault 注入的故障: malformed(模型内容不是合法JSON) / malformed_body(响应体不是合法JSON) / 5xx / empty(没有返回内容)
+	Fault string `json:"fault"`
+	// DelayMs 额外的响应延迟(毫秒)
+	DelayMs int `json:"delay_ms"`
+	// Content 原样返回的模型输出文本，优先于 Questions
+	Content string `json:"content"`
+	// Questions 返回的题目列表，会被序列化为JSON数组作为模型输出
+	Questions []dto.Question `json:"questions"`
+}
+
+// mockServer 桩服务状态
+type mockServer struct {
+	latency       time.Duration
+	jitter        time.Duration
+	malformedRate float64
+	errorRate     float64
+
+	mu        sync.Mutex
+	rnd       *rand.Rand
+	script    []ScriptStep
+	requestNo int
+}
+
+func main() {
+	addr := flag.String("addr", ":8090", "监听地址")
+	latency := flag.Duration("latency", 0, "每个请求的固定延迟，如 500ms")
+	jitter := flag.Duration("jitter", 0, "在固定延迟基础上增加的随机延迟上限")
+	malformedRate := flag.Float64("malformed-rate", 0, "返回非法JSON内容的概率(0-1)")
+	errorRate := flag.Float64("error-rate", 0, "返回5xx错误的概率(0-1)")
+	scriptPath := flag.String("script", "", "脚本文件路径(JSON数组，元素结构见 ScriptStep)")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "随机数种子，固定后故障注入可复现")
+	flag.Parse()
+
+	server := &mockServer{
+		latency:       *latency,
+		jitter:        *jitter,
+		malformedRate: *malformedRate,
+		errorRate:     *errorRate,
+		rnd:           rand.New(rand.NewSource(*seed)),
+	}
+	if *scriptPath != "" {
+		steps, err := loadScript(*scriptPath)
+		if err != nil {
+			log.Fatalf("加载脚本失败: %v", err)
+		}
+		server.script = steps
+		log.Printf("已加载脚本 %s，共 %d 步", *scriptPath, len(steps))
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(gin.Logger(), gin.Recovery())
+	// 通义千问(DashScope)文本生成接口
+	r.POST("/api/v1/services/aigc/text-generation/generation", server.handleDashScope)
+	// OpenAI chat completions 接口(包含 DashScope 的兼容模式路径)
+	r.POST("/v1/chat/completions", server.handleChatCompletions)
+	r.POST("/compatible-mode/v1/chat/completions", server.handleChatCompletions)
+	// OpenAI embeddings 接口
+	r.POST("/v1/embeddings", server.handleEmbeddings)
+	r.POST("/compatible-mode/v1/embeddings", server.handleEmbeddings)
+	// 控制接口，供集成测试在运行时追加脚本或重置状态
+	mock := r.Group("/mock")
+	{
+		mock.POST("/script", server.appendScript)
+		mock.POST("/reset", server.reset)
+		mock.GET("/stats", server.stats)
+	}
+
+	log.Printf("mockai 启动于 %s", *addr)
+	if err := r.Run(*addr); err != nil {
+		log.Fatalf("mockai 启动失败: %v", err)
+	}
+}
+
+func loadScript(path string) ([]ScriptStep, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var steps []ScriptStep
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("解析脚本失败: %v", err)
+	}
+	return steps, nil
+}
+
+// nextStep 取出下一步脚本，没有脚本时按概率注入随机故障
+func (s *mockServer) nextStep() (ScriptStep, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestNo++
+	if len(s.script) > 0 {
+		step := s.script[0]
+		s.script = s.script[1:]
+		return step, s.requestNo
+	}
+	var step ScriptStep
+	switch p := s.rnd.Float64(); {
+	case p < s.errorRate:
+		step.Fault = "5xx"
+	case p < s.errorRate+s.malformedRate:
+		step.Fault = "malformed"
+	}
+	return step, s.requestNo
+}
+
+// delay 模拟模型的响应延迟
+func (s *mockServer) delay(step ScriptStep) {
+	d := s.latency + time.Duration(step.DelayMs)*time.Millisecond
+	if s.jitter > 0 {
+		s.mu.Lock()
+		d += time.Duration(s.rnd.Int63n(int64(s.jitter)))
+		s.mu.Unlock()
+	}
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// completion 根据脚本步骤和提示词生成模型输出，返回 false 表示已直接写入错误响应
+func (s *mockServer) completion(c *gin.Context, messages []Message) (string, bool) {
+	step, requestNo := s.nextStep()
+	s.delay(step)
+
+	switch step.Fault {
+	case "5xx":
+		status := http.StatusInternalServerError
+		if requestNo%2 == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{
+			"code":       "InternalError",
+			"message":    "mockai injected server error",
+			"request_id": fmt.Sprintf("mock-%d", requestNo),
+		})
+		return "", false
+	case "malformed_body":
+		c.Data(http.StatusOK, "application/json", []byte(`{"output": {"text": "[{"title": `))
+		return "", false
+	case "empty":
+		return "", true
+	}
+
+	var content string
+	switch {
+	case step.Content != "":
+		content = step.Content
+	case len(step.Questions) > 0:
+		data, _ := json.Marshal(step.Questions)
+		content = string(data)
+	case isModerationPrompt(messages):
+		content = `{"safe": true, "reason": ""}`
+	case isKnowledgePrompt(messages):
+		content = classifyKnowledgePoints(lastUserPrompt(messages))
+	default:
+		content = cannedQuestions(lastUserPrompt(messages))
+	}
+	if step.Fault == "malformed" {
+		// 截断输出，模拟模型返回不完整的JSON
+		content = content[:len(content)/2]
+	}
+	return content, true
+}
+
+func (s *mockServer) handleDashScope(c *gin.Context) {
+	var req dashScopeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": "InvalidParameter", "message": err.Error()})
+		return
+	}
+	content, ok := s.completion(c, req.Input.Messages)
+	if !ok {
+		return
+	}
+	inputTokens, outputTokens := countTokens(req.Input.Messages), len([]rune(content))/2
+	// 同时返回 text 和 choices，兼容 qwen 与 deepseek 两种解析方式
+	c.JSON(http.StatusOK, gin.H{
+		"output": gin.H{
+			"text":          content,
+			"finish_reason": "stop",
+			"choices": []gin.H{{
+				"finish_reason": "stop",
+				"message":       gin.H{"role": "assistant", "content": content},
+			}},
+		},
+		"usage": gin.H{
+			"input_tokens":  inputTokens,
+			"output_tokens": outputTokens,
+			"total_tokens":  inputTokens + outputTokens,
+		},
+		"request_id": fmt.Sprintf("mock-%d", time.Now().UnixNano()),
+	})
+}
+
+func (s *mockServer) handleChatCompletions(c *gin.Context) {
+	var req chatCompletionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"message": err.Error(), "type": "invalid_request_error"}})
+		return
+	}
+	content, ok := s.completion(c, req.Messages)
+	if !ok {
+		return
+	}
+	promptTokens, completionTokens := countTokens(req.Messages), len([]rune(content))/2
+	c.JSON(http.StatusOK, gin.H{
+		"id":      fmt.Sprintf("chatcmpl-mock-%d", time.Now().UnixNano()),
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   req.Model,
+		"choices": []gin.H{{
+			"index":         0,
+			"message":       gin.H{"role": "assistant", "content": content},
+			"finish_reason": "stop",
+		}},
+		"usage": gin.H{
+			"prompt_tokens":     promptTokens,
+			"completion_tokens": completionTokens,
+			"total_tokens":      promptTokens + completionTokens,
+		},
+	})
+}
+
+// handleEmbeddings 返回确定性的向量: 文本中的字符二元组散列到各维度后归一化，
+// 字面相近的文本向量相近，足以在本地验证语义检索流程
+func (s *mockServer) handleEmbeddings(c *gin.Context) {
+	var req embeddingReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"message": err.Error(), "type": "invalid_request_error"}})
+		return
+	}
+	s.mu.Lock()
+	s.requestNo++
+	s.mu.Unlock()
+	dimensions := req.Dimensions
+	if dimensions <= 0 {
+		dimensions = defaultEmbeddingDimensions
+	}
+	data := make([]gin.H, 0, len(req.Input))
+	tokens := 0
+	for i, text := range req.Input {
+		data = append(data, gin.H{"object": "embedding", "index": i, "embedding": hashEmbedding(text, dimensions)})
+		tokens += len([]rune(text)) / 2
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"object": "list",
+		"data":   data,
+		"model":  req.Model,
+		"usage":  gin.H{"prompt_tokens": tokens, "total_tokens": tokens},
+	})
+}
+
+func hashEmbedding(text string, dimensions int) []float64 {
+	vector := make([]float64, dimensions)
+	runes := []rune(strings.ToLower(text))
+	for i := 0; i+1 < len(runes); i++ {
+		if unicode.IsSpace(runes[i]) || unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i : i+2])))
+		sum := h.Sum64()
+		if sum&1 == 0 {
+			vector[(sum>>1)%uint64(dimensions)]++
+		} else {
+			vector[(sum>>1)%uint64(dimensions)]--
+		}
+	}
+	var norm float64
+	for _, x := range vector {
+		norm += x * x
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] /= norm
+		}
+	}
+	return vector
+}
+
+// appendScript 追加脚本步骤
+func (s *mockServer) appendScript(c *gin.Context) {
+	var steps []ScriptStep
+	if err := c.ShouldBindJSON(&steps); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	s.mu.Lock()
+	s.script = append(s.script, steps...)
+	pending := len(s.script)
+	s.mu.Unlock()
+	c.JSON(http.StatusOK, gin.H{"pending": pending})
+}
+
+// reset 清空脚本与请求计数
+func (s *mockServer) reset(c *gin.Context) {
+	s.mu.Lock()
+	s.script = nil
+	s.requestNo = 0
+	s.mu.Unlock()
+	c.JSON(http.StatusOK, gin.H{"pending": 0})
+}
+
+func (s *mockServer) stats(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c.JSON(http.StatusOK, gin.H{"requests": s.requestNo, "pending": len(s.script)})
+}
+
+func lastUserPrompt(messages []Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// isModerationPrompt 内容审核请求直接判定为安全
+func isModerationPrompt(messages []Message) bool {
+	for _, m := range messages {
+		if m.Role == "system" && strings.Contains(m.Content, "内容审核") {
+			return true
+		}
+	}
+	return false
+}
+
+// isKnowledgePrompt 知识点分类请求
+func isKnowledgePrompt(messages []Message) bool {
+	for _, m := range messages {
+		if m.Role == "system" && strings.Contains(m.Content, "知识点") {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	knowledgeLine = regexp.MustCompile(`(?m)^\[(\d+)\] (.+)$`)
+	questionBlock = regexp.MustCompile(`(?s)<question index="(\d+)">(.*?)</question>`)
+)
+
+// classifyKnowledgePoints 为每道题目选择名称(路径最后一级)出现在题目中的知识点，路径越深越优先，最多3个
+func classifyKnowledgePoints(prompt string) string {
+	type point struct {
+		id    int
+		name  string
+		depth int
+	}
+	var points []point
+	for _, m := range knowledgeLine.FindAllStringSubmatch(prompt, -1) {
+		id, _ := strconv.Atoi(m[1])
+		names := strings.Split(m[2], " / ")
+		points = append(points, point{id: id, name: strings.ToLower(names[len(names)-1]), depth: len(names)})
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].depth > points[j].depth })
+	type result struct {
+		Index int   `json:"index"`
+		IDs   []int `json:"ids"`
+	}
+	results := []result{}
+	for _, m := range questionBlock.FindAllStringSubmatch(prompt, -1) {
+		index, _ := strconv.Atoi(m[1])
+		text := strings.ToLower(m[2])
+		r := result{Index: index, IDs: []int{}}
+		for _, p := range points {
+			if len(r.IDs) < 3 && strings.Contains(text, p.name) {
+				r.IDs = append(r.IDs, p.id)
+			}
+		}
+		results = append(results, r)
+	}
+	data, _ := json.Marshal(results)
+	return string(data)
+}
+
+// countTokens 粗略估算token数量(按两个字符一个token)
+func countTokens(messages []Message) int {
+	n := 0
+	for _, m := range messages {
+		n += len([]rune(m.Content))
+	}
+	return n / 2
+}
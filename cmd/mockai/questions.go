@@ -0,0 +1,69 @@
+package main
+
+import (
+	"aiquiz/models/dto"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	countPattern    = regexp.MustCompile(`(?:生成|出)(\d+)道`)
+	languagePattern = regexp.MustCompile(`(?:关于|为)(.+?)编程语言`)
+	topicPattern    = regexp.MustCompile(`主题(?:围绕|为)"(.*?)"`)
+	fencePattern    = regexp.MustCompile("单独一行```(\\S+?)开始")
+)
+
+// cannedQuestions 根据提示词中的数量、语言、题型和主题生成结构合法的题目JSON
+func cannedQuestions(prompt string) string {
+	count := 1
+	if m := countPattern.FindStringSubmatch(prompt); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+			count = n
+		}
+	}
+	language := "Go"
+	if m := languagePattern.FindStringSubmatch(prompt); m != nil {
+		language = m[1]
+	}
+	topic := "基础语法"
+	if m := topicPattern.FindStringSubmatch(prompt); m != nil && m[1] != "" {
+		topic = m[1]
+	}
+	multiple := strings.Contains(prompt, "多项选择题")
+	// 提示词允许代码块时，偶数题在题干中附带代码片段
+	codeTag := ""
+	if m := fencePattern.FindStringSubmatch(prompt); m != nil {
+		codeTag = m[1]
+	}
+
+	questions := make([]dto.Question, 0, count)
+	for i := 1; i <= count; i++ {
+		q := dto.Question{
+			Title: fmt.Sprintf("[mock] 关于%s中%s的第%d题，以下说法正确的是？", language, topic, i),
+			Options: []dto.Option{
+				{Content: fmt.Sprintf("%s的说法A", topic), Value: 1},
+				{Content: fmt.Sprintf("%s的说法B", topic), Value: 2},
+				{Content: fmt.Sprintf("%s的说法C", topic), Value: 4},
+				{Content: fmt.Sprintf("%s的说法D", topic), Value: 8},
+			},
+		}
+		if codeTag != "" && i%2 == 0 {
+			q.Title = fmt.Sprintf("[mock] 关于%s中%s的第%d题，以下代码的说法正确的是？\n\n```%s\n// %s 示例代码\nx := %d\n```", language, topic, i, codeTag, topic, i)
+		}
+		if multiple {
+			// 多选题固定两个以上正确选项
+			q.Answer = 1 | 4 | (8 * (i % 2))
+			q.Explanation = "mock: answer 中包含的选项均为正确说法，其余选项为干扰项"
+		} else {
+			// 单选题的正确选项轮换出现
+			q.Answer = 1 << ((i - 1) % 4)
+			q.Explanation = "mock: 正确选项为标准说法，其余选项为干扰项"
+		}
+		questions = append(questions, q)
+	}
+	data, _ := json.Marshal(questions)
+	return string(data)
+}
@@ -5,15 +5,46 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
 type AppConfig struct {
-	ServerPort         string
-	Mode               string
-	DBPath             string
-	DashScopeApiKey    string
-	SupportedLanguages map[string]interface{}
+	ServerPort             string
+	Mode                   string
+	DBPath                 string
+	DashScopeApiKey        string
+	DashScopeBaseURL       string
+	AITransportMode        string   // live / record / replay
+	AIFixtureDir           string   // 相对路径相对于启动时的工作目录
+	ExperimentDiscardHours int      // 生成后超过该小时数未确认的题目视为丢弃
+	ModerationAction       string   // 内容审核未通过时的处理方式: reject / flag
+	ModerationBannedTerms  []string // 违禁词列表
+	ModerationModel        string   // 审核使用的模型，为空时不调用模型审核
+	KeywordsMaxLength      int      // 出题关键词的最大长度(字符数)
+	TrashRetentionDays     int      // 回收站保留天数，超过后彻底删除，0 表示不自动清理
+	ReviewRequireApproved  bool     // 只有审核通过的题目才能加入试卷
+	ReviewSubmitOnConfirm  bool     // 题目入库后直接进入待审核状态(否则为草稿，需作者手动提交)
+	PaperExcludeReported   bool     // 有未解决错误报告的题目不能加入试卷
+	AttachmentDir          string   // 附件文件的本地存储目录
+	AttachmentMaxSizeMB    int      // 单个附件的大小上限(MB)
+	AttachmentAllowedTypes []string // 允许上传的附件类型(按文件内容识别的 MIME 类型)
+	AttachmentOrphanHours  int      // 未被题目引用的附件超过该小时数后清理，0 表示不清理
+	CodeRunEnabled         bool     // 是否运行代码题中的 Go 代码以核对输出类题目的答案
+	CodeRunGoBinary        string   // 运行代码使用的 go 命令
+	CodeRunCacheDir        string   // 运行代码的共享编译缓存目录，只由预热写入，每次编译使用独立的可写层
+	CodeRunBuildTimeout    int      // 编译超时时间(秒)
+	CodeRunTimeout         int      // 运行超时时间(秒)
+	CodeRunMemoryMB        int      // 运行时的内存上限(MB)
+	DuplicateMaxDistance   int      // 题目指纹的汉明距离不超过该值时视为可能重复
+	EmbeddingEnabled       bool     // 是否开启语义检索(计算并保存题目向量)
+	EmbeddingBaseURL       string   // OpenAI 兼容的向量接口地址(不含 /embeddings)
+	EmbeddingApiKey        string   // 向量接口密钥，默认与 DashScope 相同
+	EmbeddingModel         string   // 向量模型
+	EmbeddingDimensions    int      // 向量维度，0 表示使用模型的默认维度
+	EmbeddingBatchSize     int      // 每次请求计算的文本数量
+	KnowledgeAutoClassify  bool     // 生成题目时是否由模型推荐考查的知识点(该语言已建立知识点时)
+	SupportedLanguages     map[string]interface{}
 }
 
 func init() {
@@ -63,16 +94,59 @@ func GetConfig(LanguageFlag bool) *AppConfig {
 			supportedLanguages[lang] = nil
 		}
 	}
+	// 离线开发可指向 cmd/mockai
+	dashScopeBaseURL := strings.TrimRight(getEnv("DASHSCOPE_BASE_URL", "https://dashscope.aliyuncs.com"), "/")
 	return &AppConfig{
 		// 从环境变量中读取，如果不存在则使用默认值
-		ServerPort:         getEnv("SERVER_PORT", ":8080"),
-		Mode:               getEnv("GIN_MODE", "debug"),
-		DBPath:             getEnv("DB_PATH", "./aiquiz.db"),
-		DashScopeApiKey:    getEnv("DASHSCOPE_API_KEY", ""),
-		SupportedLanguages: supportedLanguages,
+		ServerPort:             getEnv("SERVER_PORT", ":8080"),
+		Mode:                   getEnv("GIN_MODE", "debug"),
+		DBPath:                 getEnv("DB_PATH", "./aiquiz.db"),
+		DashScopeApiKey:        getEnv("DASHSCOPE_API_KEY", ""),
+		DashScopeBaseURL:       dashScopeBaseURL,
+		AITransportMode:        getEnv("AI_TRANSPORT_MODE", "live"),
+		AIFixtureDir:           getEnv("AI_FIXTURE_DIR", "./ai/testdata/fixtures"),
+		ExperimentDiscardHours: getEnvInt("EXPERIMENT_DISCARD_HOURS", 24),
+		ModerationAction:       getEnv("MODERATION_ACTION", "flag"),
+		ModerationBannedTerms:  splitList(getEnv("MODERATION_BANNED_TERMS", "")),
+		ModerationModel:        getEnv("MODERATION_MODEL", ""),
+		KeywordsMaxLength:      getEnvInt("KEYWORDS_MAX_LENGTH", 50),
+		TrashRetentionDays:     getEnvInt("TRASH_RETENTION_DAYS", 30),
+		ReviewRequireApproved:  getEnvBool("REVIEW_REQUIRE_APPROVED", false),
+		ReviewSubmitOnConfirm:  getEnvBool("REVIEW_SUBMIT_ON_CONFIRM", false),
+		PaperExcludeReported:   getEnvBool("PAPER_EXCLUDE_REPORTED", false),
+		AttachmentDir:          getEnv("ATTACHMENT_DIR", "./data/attachments"),
+		AttachmentMaxSizeMB:    getEnvInt("ATTACHMENT_MAX_SIZE_MB", 5),
+		AttachmentAllowedTypes: splitList(getEnv("ATTACHMENT_ALLOWED_TYPES", "image/png,image/jpeg,image/gif,image/webp,application/pdf")),
+		AttachmentOrphanHours:  getEnvInt("ATTACHMENT_ORPHAN_HOURS", 24),
+		CodeRunEnabled:         getEnvBool("CODE_RUN_ENABLED", false),
+		CodeRunGoBinary:        getEnv("CODE_RUN_GO_BINARY", "go"),
+		CodeRunCacheDir:        getEnv("CODE_RUN_CACHE_DIR", "./data/gocache"),
+		CodeRunBuildTimeout:    getEnvInt("CODE_RUN_BUILD_TIMEOUT_SECONDS", 30),
+		CodeRunTimeout:         getEnvInt("CODE_RUN_TIMEOUT_SECONDS", 5),
+		CodeRunMemoryMB:        getEnvInt("CODE_RUN_MEMORY_MB", 256),
+		DuplicateMaxDistance:   getEnvInt("DUPLICATE_MAX_DISTANCE", 10),
+		EmbeddingEnabled:       getEnvBool("EMBEDDING_ENABLED", false),
+		EmbeddingBaseURL:       strings.TrimRight(getEnv("EMBEDDING_BASE_URL", dashScopeBaseURL+"/compatible-mode/v1"), "/"),
+		EmbeddingApiKey:        getEnv("EMBEDDING_API_KEY", getEnv("DASHSCOPE_API_KEY", "")),
+		EmbeddingModel:         getEnv("EMBEDDING_MODEL", "text-embedding-v3"),
+		EmbeddingDimensions:    getEnvInt("EMBEDDING_DIMENSIONS", 0),
+		EmbeddingBatchSize:     getEnvInt("EMBEDDING_BATCH_SIZE", 10),
+		KnowledgeAutoClassify:  getEnvBool("KNOWLEDGE_AUTO_CLASSIFY", true),
+		SupportedLanguages:     supportedLanguages,
 	}
 }
 
+// splitList 解析逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // getEnv 获取环境变量，如果不存在则返回默认值
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -81,3 +155,12 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvBool 获取布尔类型的环境变量，无法解析时返回默认值
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type AttachmentController struct {
+	AttachmentService *services.AttachmentService
+	QuestionService   *services.QuestionService
+}
+
+func NewAttachmentController(attachmentService *services.AttachmentService, questionService *services.QuestionService) *AttachmentController {
+	return &AttachmentController{AttachmentService: attachmentService, QuestionService: questionService}
+}
+
+func toAttachmentRes(attachment model.Attachment) dto.AttachmentRes {
+	return dto.AttachmentRes{
+		ID:        attachment.ID,
+		Filename:  attachment.Filename,
+		MimeType:  attachment.MimeType,
+		Size:      attachment.Size,
+		URL:       fmt.Sprintf("/api/attachments/%d", attachment.ID),
+		CreatedAt: attachment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// loadAttachment 解析路径中的附件ID并获取附件，失败时已写入响应
+func (a *AttachmentController) loadAttachment(c *gin.Context) (*model.Attachment, bool) {
+	attachmentID, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的附件ID")
+		return nil, false
+	}
+	attachment, err := a.AttachmentService.GetAttachment(c.Request.Context(), attachmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "附件不存在")
+			return nil, false
+		}
+		utils.ServerErrorWithMsg(c, "获取附件失败")
+		return nil, false
+	}
+	return attachment, true
+}
+
+// Upload 上传附件(图片或 PDF)，上传后需通过题目附件接口引用，长期未被引用的附件会被自动清理
+func (a *AttachmentController) Upload(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequestWithMsg(c, "请上传文件")
+		return
+	}
+	if maxSize := a.AttachmentService.MaxSize(); fileHeader.Size > maxSize {
+		utils.BadRequestWithMsg(c, fmt.Sprintf("附件大小不能超过%dMB", maxSize>>20))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "读取文件失败")
+		return
+	}
+	defer file.Close()
+	attachment, err := a.AttachmentService.Upload(c.Request.Context(), c.GetInt("user_id"), fileHeader.Filename, file)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "上传附件失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, toAttachmentRes(*attachment), "上传附件成功")
+}
+
+// Download 下载附件，只有上传者、管理员、审核员以及能看到引用该附件的题目的用户可以下载
+func (a *AttachmentController) Download(c *gin.Context) {
+	attachment, ok := a.loadAttachment(c)
+	if !ok {
+		return
+	}
+	allowed, err := a.AttachmentService.CanDownload(c.Request.Context(), c.GetInt("user_id"), isReviewer(c), attachment)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取附件失败")
+		return
+	}
+	if !allowed {
+		utils.NotPermission(c)
+		return
+	}
+	reader, err := a.AttachmentService.Open(attachment)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "附件文件不存在")
+			return
+		}
+		utils.ServerErrorWithMsg(c, "读取附件失败")
+		return
+	}
+	defer reader.Close()
+	// 图片与 PDF 在浏览器中直接打开，其余类型下载；禁止浏览器再次猜测类型
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.MimeType, "image/") || attachment.MimeType == "application/pdf" {
+		disposition = "inline"
+	}
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.MimeType, reader, map[string]string{
+		"Content-Disposition":     fmt.Sprintf(`%s; filename*=UTF-8''%s`, disposition, url.PathEscape(attachment.Filename)),
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "default-src 'none'; sandbox",
+		"Cache-Control":           "private, max-age=86400",
+	})
+}
+
+// DeleteAttachment 删除未被题目引用的附件(上传者或管理员)
+func (a *AttachmentController) DeleteAttachment(c *gin.Context) {
+	attachment, ok := a.loadAttachment(c)
+	if !ok {
+		return
+	}
+	if !canManage(c, attachment.UserID) {
+		utils.NotPermission(c)
+		return
+	}
+	if err := a.AttachmentService.DeleteAttachment(c.Request.Context(), attachment); err != nil {
+		utils.BadRequestWithMsg(c, "删除附件失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// ListQuestionAttachments 获取题目引用的附件
+func (a *AttachmentController) ListQuestionAttachments(c *gin.Context) {
+	q, ok := loadVisibleQuestion(c, a.QuestionService)
+	if !ok {
+		return
+	}
+	links, err := a.AttachmentService.ListQuestionAttachments(c.Request.Context(), q.ID)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取题目附件失败")
+		return
+	}
+	res := make([]dto.QuestionAttachmentRes, 0, len(links))
+	for _, link := range links {
+		if link.Attachment == nil {
+			continue
+		}
+		res = append(res, dto.QuestionAttachmentRes{OptionValue: link.OptionValue, AttachmentRes: toAttachmentRes(*link.Attachment)})
+	}
+	utils.SuccessMsg(c, res, "获取题目附件成功")
+}
+
+// SetQuestionAttachments 整体设置题目引用的附件(题目创建者或管理员)
+func (a *AttachmentController) SetQuestionAttachments(c *gin.Context) {
+	q, ok := loadQuestion(c, a.QuestionService)
+	if !ok {
+		return
+	}
+	if !canManage(c, q.UserID) {
+		utils.NotPermission(c)
+		return
+	}
+	var req dto.SetQuestionAttachmentsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	err := a.AttachmentService.SetQuestionAttachments(c.Request.Context(), c.GetInt("user_id"), c.GetString("role") == "admin", q, &req)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "设置题目附件失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
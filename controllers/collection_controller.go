@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+)
+
+type CollectionController struct {
+	CollectionService *services.CollectionService
+}
+
+func NewCollectionController(collectionService *services.CollectionService) *CollectionController {
+	return &CollectionController{CollectionService: collectionService}
+}
+
+// loadCollection 获取路径中的集合，只有集合所有者与管理员可以操作，失败时已写入响应
+func (cc *CollectionController) loadCollection(c *gin.Context) (*model.Collection, bool) {
+	collectionID, err := strconv.Atoi(c.Param("collection_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的集合ID")
+		return nil, false
+	}
+	collection, err := cc.CollectionService.GetCollection(c.Request.Context(), collectionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "集合不存在")
+			return nil, false
+		}
+		utils.ServerErrorWithMsg(c, "获取集合失败")
+		return nil, false
+	}
+	if !canManage(c, collection.UserID) {
+		utils.NotPermission(c)
+		return nil, false
+	}
+	return collection, true
+}
+
+// bindCollectionReq 绑定并校验集合的创建、修改请求，失败时已写入响应
+func bindCollectionReq(c *gin.Context) (*dto.CollectionReq, bool) {
+	var req dto.CollectionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return nil, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.BadRequestWithMsg(c, "集合名称不能为空")
+		return nil, false
+	}
+	if len([]rune(req.Name)) > 100 {
+		utils.BadRequestWithMsg(c, "集合名称不能超过100个字符")
+		return nil, false
+	}
+	return &req, true
+}
+
+// bindCollectionQuestions 绑定题目ID列表，失败时已写入响应
+func bindCollectionQuestions(c *gin.Context) (*dto.CollectionQuestionsReq, bool) {
+	var req dto.CollectionQuestionsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return nil, false
+	}
+	if len(req.QuestionIDs) == 0 {
+		utils.BadRequestWithMsg(c, "题目ID列表不能为空")
+		return nil, false
+	}
+	return &req, true
+}
+
+// CreateCollection 创建集合
+func (cc *CollectionController) CreateCollection(c *gin.Context) {
+	req, ok := bindCollectionReq(c)
+	if !ok {
+		return
+	}
+	collection, err := cc.CollectionService.CreateCollection(c.Request.Context(), c.GetInt("user_id"), req)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "创建集合失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, services.ToCollectionRes(collection, 0), "创建集合成功")
+}
+
+// ListCollections 以树形结构获取自己的全部集合
+func (cc *CollectionController) ListCollections(c *gin.Context) {
+	tree, err := cc.CollectionService.ListCollectionTree(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取集合失败")
+		return
+	}
+	utils.SuccessMsg(c, tree, "获取集合成功")
+}
+
+// GetCollection 获取集合及其下级集合，集合中的题目通过 GET /api/questions?collection_id= 查询
+func (cc *CollectionController) GetCollection(c *gin.Context) {
+	collection, ok := cc.loadCollection(c)
+	if !ok {
+		return
+	}
+	res, err := cc.CollectionService.GetCollectionTree(c.Request.Context(), collection)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取集合失败")
+		return
+	}
+	utils.SuccessMsg(c, res, "获取集合成功")
+}
+
+// UpdateCollection 修改集合名称、描述或移动到其他集合之下
+func (cc *CollectionController) UpdateCollection(c *gin.Context) {
+	collection, ok := cc.loadCollection(c)
+	if !ok {
+		return
+	}
+	req, ok := bindCollectionReq(c)
+	if !ok {
+		return
+	}
+	if err := cc.CollectionService.UpdateCollection(c.Request.Context(), collection, req); err != nil {
+		utils.BadRequestWithMsg(c, "修改集合失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// DeleteCollection 删除集合及其下级集合，集合中的题目不会被删除
+func (cc *CollectionController) DeleteCollection(c *gin.Context) {
+	collection, ok := cc.loadCollection(c)
+	if !ok {
+		return
+	}
+	if err := cc.CollectionService.DeleteCollection(c.Request.Context(), collection.ID); err != nil {
+		utils.ServerErrorWithMsg(c, "删除集合失败")
+		return
+	}
+	utils.Ok(c)
+}
+
+// AddQuestions 将题目按顺序追加到集合末尾
+func (cc *CollectionController) AddQuestions(c *gin.Context) {
+	collection, ok := cc.loadCollection(c)
+	if !ok {
+		return
+	}
+	req, ok := bindCollectionQuestions(c)
+	if !ok {
+		return
+	}
+	added, err := cc.CollectionService.AddQuestions(c.Request.Context(), collection, req.QuestionIDs)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "添加题目失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, dto.CollectionQuestionsRes{Added: added}, "添加题目成功")
+}
+
+// RemoveQuestion 将题目移出集合
+func (cc *CollectionController) RemoveQuestion(c *gin.Context) {
+	collection, ok := cc.loadCollection(c)
+	if !ok {
+		return
+	}
+	questionID, err := strconv.Atoi(c.Param("question_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的题目ID")
+		return
+	}
+	removed, err := cc.CollectionService.RemoveQuestion(c.Request.Context(), collection.ID, questionID)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "移出题目失败")
+		return
+	}
+	if !removed {
+		utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "题目不在集合中")
+		return
+	}
+	utils.Ok(c)
+}
+
+// ReorderQuestions 调整集合中题目的顺序，未列出的题目保持原有相对顺序排在其后
+func (cc *CollectionController) ReorderQuestions(c *gin.Context) {
+	collection, ok := cc.loadCollection(c)
+	if !ok {
+		return
+	}
+	req, ok := bindCollectionQuestions(c)
+	if !ok {
+		return
+	}
+	if err := cc.CollectionService.ReorderQuestions(c.Request.Context(), collection.ID, req.QuestionIDs); err != nil {
+		utils.BadRequestWithMsg(c, "调整题目顺序失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DuplicateController struct {
+	DuplicateService *services.DuplicateService
+}
+
+func NewDuplicateController(duplicateService *services.DuplicateService) *DuplicateController {
+	return &DuplicateController{DuplicateService: duplicateService}
+}
+
+// ListClusters 查询题库中相互相似的题目分组(管理员)
+func (d *DuplicateController) ListClusters(c *gin.Context) {
+	var req dto.DuplicateClustersReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	clusters, err := d.DuplicateService.ListClusters(c.Request.Context(), req.Language)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取重复题目失败")
+		return
+	}
+	utils.SuccessMsg(c, clusters, "获取重复题目成功")
+}
+
+// MergeQuestions 合并重复题目: 试卷与集合中的引用改为保留的题目，重复题目移入回收站(管理员)
+func (d *DuplicateController) MergeQuestions(c *gin.Context) {
+	var req dto.MergeQuestionsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	res, err := d.DuplicateService.MergeQuestions(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "保留的题目不存在")
+			return
+		}
+		utils.BadRequestWithMsg(c, "合并题目失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, res, "合并题目成功")
+}
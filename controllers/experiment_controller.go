@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+type ExperimentController struct {
+	ExperimentService *services.ExperimentService
+}
+
+func NewExperimentController(experimentService *services.ExperimentService) *ExperimentController {
+	return &ExperimentController{ExperimentService: experimentService}
+}
+
+// CreateExperiment 创建提示词/模型实验
+func (e *ExperimentController) CreateExperiment(c *gin.Context) {
+	var req dto.CreateExperimentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	if err := e.ExperimentService.CreateExperiment(c.Request.Context(), &req); err != nil {
+		utils.BadRequestWithMsg(c, "创建实验失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// ListExperiments 获取全部实验
+func (e *ExperimentController) ListExperiments(c *gin.Context) {
+	experiments, err := e.ExperimentService.ListExperiments(c.Request.Context())
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取实验列表失败")
+		return
+	}
+	list := make([]dto.ExperimentRes, 0, len(experiments))
+	for _, experiment := range experiments {
+		list = append(list, dto.ExperimentRes{
+			ID:             experiment.ID,
+			Name:           experiment.Name,
+			Description:    experiment.Description,
+			VariantPrompt:  experiment.VariantPrompt,
+			VariantModel:   experiment.VariantModel,
+			TrafficPercent: experiment.TrafficPercent,
+			Active:         experiment.Active,
+			CreatedAt:      experiment.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:      experiment.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	utils.SuccessMsg(c, list, "获取实验列表成功")
+}
+
+// UpdateExperiment 更新实验配置或开关实验
+func (e *ExperimentController) UpdateExperiment(c *gin.Context) {
+	experimentID, err := strconv.Atoi(c.Param("experiment_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的实验ID")
+		return
+	}
+	var req dto.UpdateExperimentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	if err := e.ExperimentService.UpdateExperiment(c.Request.Context(), experimentID, &req); err != nil {
+		utils.BadRequestWithMsg(c, "更新实验失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// DeleteExperiment 删除实验，已产生的生成记录保留
+func (e *ExperimentController) DeleteExperiment(c *gin.Context) {
+	experimentID, err := strconv.Atoi(c.Param("experiment_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的实验ID")
+		return
+	}
+	if err := e.ExperimentService.DeleteExperiment(c.Request.Context(), experimentID); err != nil {
+		utils.ServerErrorWithMsg(c, "删除实验失败"+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ExportController struct {
+	ExportService *services.ExportService
+}
+
+func NewExportController(exportService *services.ExportService) *ExportController {
+	return &ExportController{ExportService: exportService}
+}
+
+// ExportQuestions 按筛选条件导出题目为 Moodle XML、GIFT 文件、QTI 内容包或可打印的 HTML(管理员导出全部题目)
+func (e *ExportController) ExportQuestions(c *gin.Context) {
+	var req dto.ExportQuestionsReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	if err := e.ExportService.CheckExportFormat(req.Format, req.QTIVersion); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	if req.TagMode != "" && req.TagMode != "and" && req.TagMode != "or" {
+		utils.BadRequestWithMsg(c, "无效的标签匹配方式，必须是 'and' 或 'or'")
+		return
+	}
+	userID := c.GetInt("user_id")
+	if c.GetString("role") == "admin" {
+		userID = 0
+	}
+	file, err := e.ExportService.ExportQuestions(c.Request.Context(), userID, &req)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "导出题目失败: "+err.Error())
+		return
+	}
+	sendExportFile(c, file)
+}
+
+// ExportPaper 将试卷中的题目导出为 Moodle XML、GIFT 文件、QTI 内容包或可打印的 HTML
+func (e *ExportController) ExportPaper(c *gin.Context) {
+	var req dto.ExportPaperReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	if err := e.ExportService.CheckExportFormat(req.Format, req.QTIVersion); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	file, err := e.ExportService.ExportPaper(c.Request.Context(), c.GetInt("paper_id"), &req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "导出试卷失败: "+err.Error())
+		return
+	}
+	sendExportFile(c, file)
+}
+
+func sendExportFile(c *gin.Context, file *services.ExportFile) {
+	disposition := "attachment"
+	if file.Inline {
+		disposition = "inline"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`%s; filename="%s"`, disposition, file.Filename))
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
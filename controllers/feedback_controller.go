@@ -0,0 +1,365 @@
+package controllers
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"aiquiz/utils/enums"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FeedbackController struct {
+	FeedbackService *services.FeedbackService
+	QuestionService *services.QuestionService
+}
+
+func NewFeedbackController(feedbackService *services.FeedbackService, questionService *services.QuestionService) *FeedbackController {
+	return &FeedbackController{FeedbackService: feedbackService, QuestionService: questionService}
+}
+
+// loadVisibleQuestion 获取路径中的题目，只有能看到题目的用户(创建者、管理员、审核员，或题目已共享)才能查看、评论与报告错误
+func loadVisibleQuestion(c *gin.Context, questionService *services.QuestionService) (*model.Question, bool) {
+	q, ok := loadQuestion(c, questionService)
+	if !ok {
+		return nil, false
+	}
+	if q.Visibility == string(enums.VisibilityPrivate) && !canManage(c, q.UserID) && !isReviewer(c) {
+		utils.NotPermission(c)
+		return nil, false
+	}
+	return q, true
+}
+
+// bindFeedbackList 绑定并校验评论、错误报告的分页查询参数，失败时已写入响应
+func bindFeedbackList(c *gin.Context) (*dto.FeedbackListReq, bool) {
+	var req dto.FeedbackListReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return nil, false
+	}
+	if req.Status != "" && !enums.IsSupportedFeedbackStatus(req.Status) {
+		utils.BadRequestWithMsg(c, "无效的状态，必须是 'open' 或 'resolved'")
+		return nil, false
+	}
+	req.Page = utils.NewPage(req.PageNum, req.PageSize)
+	return &req, true
+}
+
+// bindFeedbackStatus 绑定并校验处理状态修改请求，失败时已写入响应
+func bindFeedbackStatus(c *gin.Context) (*dto.UpdateFeedbackStatusReq, bool) {
+	var req dto.UpdateFeedbackStatusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return nil, false
+	}
+	if !enums.IsSupportedFeedbackStatus(req.Status) {
+		utils.BadRequestWithMsg(c, "无效的状态，必须是 'open' 或 'resolved'")
+		return nil, false
+	}
+	req.Resolution = strings.TrimSpace(req.Resolution)
+	return &req, true
+}
+
+func formatResolvedAt(resolvedAt *time.Time) string {
+	if resolvedAt == nil {
+		return ""
+	}
+	return resolvedAt.Format("2006-01-02 15:04:05")
+}
+
+func toCommentRes(comment model.QuestionComment) dto.CommentRes {
+	res := dto.CommentRes{
+		ID:         comment.ID,
+		QuestionID: comment.QuestionID,
+		ParentID:   comment.ParentID,
+		UserID:     comment.UserID,
+		Content:    comment.Content,
+		ResolvedBy: comment.ResolvedBy,
+		ResolvedAt: formatResolvedAt(comment.ResolvedAt),
+		CreatedAt:  comment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if comment.ParentID == 0 {
+		res.Status = comment.Status
+	}
+	if comment.User != nil {
+		res.UserName = comment.User.Username
+	}
+	if comment.Question != nil {
+		res.QuestionTitle = comment.Question.Title
+	}
+	return res
+}
+
+func toReportRes(report model.QuestionReport) dto.ReportRes {
+	res := dto.ReportRes{
+		ID:          report.ID,
+		QuestionID:  report.QuestionID,
+		Category:    report.Category,
+		Description: report.Description,
+		Status:      report.Status,
+		Resolution:  report.Resolution,
+		ReporterID:  report.ReporterID,
+		ResolvedBy:  report.ResolvedBy,
+		ResolvedAt:  formatResolvedAt(report.ResolvedAt),
+		CreatedAt:   report.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if report.Reporter != nil {
+		res.ReporterName = report.Reporter.Username
+	}
+	if report.Question != nil {
+		res.QuestionTitle = report.Question.Title
+	}
+	return res
+}
+
+// ListComments 获取题目的评论串，回复按时间顺序附在评论串下
+func (f *FeedbackController) ListComments(c *gin.Context) {
+	q, ok := loadVisibleQuestion(c, f.QuestionService)
+	if !ok {
+		return
+	}
+	comments, err := f.FeedbackService.ListComments(c.Request.Context(), q.ID)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取评论失败")
+		return
+	}
+	threads := make([]dto.CommentRes, 0)
+	index := make(map[int]int)
+	for _, comment := range comments {
+		if comment.ParentID == 0 {
+			index[comment.ID] = len(threads)
+			threads = append(threads, toCommentRes(comment))
+		}
+	}
+	for _, comment := range comments {
+		if i, exists := index[comment.ParentID]; exists {
+			threads[i].Replies = append(threads[i].Replies, toCommentRes(comment))
+			threads[i].ReplyCount++
+		}
+	}
+	utils.SuccessMsg(c, threads, "获取评论成功")
+}
+
+// AddComment 发表评论或回复评论串
+func (f *FeedbackController) AddComment(c *gin.Context) {
+	q, ok := loadVisibleQuestion(c, f.QuestionService)
+	if !ok {
+		return
+	}
+	var req dto.AddCommentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	req.Content = strings.TrimSpace(req.Content)
+	if req.Content == "" {
+		utils.BadRequestWithMsg(c, "评论内容不能为空")
+		return
+	}
+	comment, err := f.FeedbackService.AddComment(c.Request.Context(), c.GetInt("user_id"), q.ID, &req)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "发表评论失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, toCommentRes(*comment), "发表评论成功")
+}
+
+// loadComment 获取路径中的评论，失败时已写入响应
+func (f *FeedbackController) loadComment(c *gin.Context, questionID int) (*model.QuestionComment, bool) {
+	commentID, err := strconv.Atoi(c.Param("comment_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的评论ID")
+		return nil, false
+	}
+	comment, err := f.FeedbackService.GetComment(c.Request.Context(), questionID, commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "评论不存在")
+			return nil, false
+		}
+		utils.ServerErrorWithMsg(c, "获取评论失败")
+		return nil, false
+	}
+	return comment, true
+}
+
+// DeleteComment 删除评论(评论作者、题目创建者或管理员)，删除评论串时回复一并删除
+func (f *FeedbackController) DeleteComment(c *gin.Context) {
+	q, ok := loadVisibleQuestion(c, f.QuestionService)
+	if !ok {
+		return
+	}
+	comment, ok := f.loadComment(c, q.ID)
+	if !ok {
+		return
+	}
+	if comment.UserID != c.GetInt("user_id") && !canManage(c, q.UserID) {
+		utils.NotPermission(c)
+		return
+	}
+	if err := f.FeedbackService.DeleteComment(c.Request.Context(), comment); err != nil {
+		utils.ServerErrorWithMsg(c, "删除评论失败")
+		return
+	}
+	utils.Ok(c)
+}
+
+// UpdateCommentStatus 解决或重新打开评论串(评论串发起人、题目创建者或管理员)
+func (f *FeedbackController) UpdateCommentStatus(c *gin.Context) {
+	q, ok := loadVisibleQuestion(c, f.QuestionService)
+	if !ok {
+		return
+	}
+	comment, ok := f.loadComment(c, q.ID)
+	if !ok {
+		return
+	}
+	userID := c.GetInt("user_id")
+	if comment.UserID != userID && !canManage(c, q.UserID) {
+		utils.NotPermission(c)
+		return
+	}
+	req, ok := bindFeedbackStatus(c)
+	if !ok {
+		return
+	}
+	if err := f.FeedbackService.UpdateCommentStatus(c.Request.Context(), userID, comment, req.Status); err != nil {
+		utils.BadRequestWithMsg(c, "修改评论状态失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// ListReports 获取题目的错误报告，可按状态筛选
+func (f *FeedbackController) ListReports(c *gin.Context) {
+	q, ok := loadVisibleQuestion(c, f.QuestionService)
+	if !ok {
+		return
+	}
+	status := enums.FeedbackStatus(c.Query("status"))
+	if status != "" && !enums.IsSupportedFeedbackStatus(status) {
+		utils.BadRequestWithMsg(c, "无效的状态，必须是 'open' 或 'resolved'")
+		return
+	}
+	reports, err := f.FeedbackService.ListReports(c.Request.Context(), q.ID, status)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取错误报告失败")
+		return
+	}
+	list := make([]dto.ReportRes, 0, len(reports))
+	for _, report := range reports {
+		list = append(list, toReportRes(report))
+	}
+	utils.SuccessMsg(c, list, "获取错误报告成功")
+}
+
+// AddReport 报告题目错误
+func (f *FeedbackController) AddReport(c *gin.Context) {
+	q, ok := loadVisibleQuestion(c, f.QuestionService)
+	if !ok {
+		return
+	}
+	var req dto.AddReportReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	if !enums.IsSupportedReportCategory(req.Category) {
+		utils.BadRequestWithMsg(c, "无效的错误类别，必须是 'wrong_answer'、'ambiguous'、'outdated'、'typo' 或 'compile_error'")
+		return
+	}
+	req.Description = strings.TrimSpace(req.Description)
+	report, err := f.FeedbackService.AddReport(c.Request.Context(), c.GetInt("user_id"), q.ID, &req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "报告错误失败")
+		return
+	}
+	utils.SuccessMsg(c, toReportRes(*report), "报告错误成功")
+}
+
+// UpdateReportStatus 处理或重新打开错误报告(题目创建者或管理员)
+func (f *FeedbackController) UpdateReportStatus(c *gin.Context) {
+	q, ok := loadQuestion(c, f.QuestionService)
+	if !ok {
+		return
+	}
+	if !canManage(c, q.UserID) {
+		utils.NotPermission(c)
+		return
+	}
+	reportID, err := strconv.Atoi(c.Param("report_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的错误报告ID")
+		return
+	}
+	report, err := f.FeedbackService.GetReport(c.Request.Context(), q.ID, reportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "错误报告不存在")
+			return
+		}
+		utils.ServerErrorWithMsg(c, "获取错误报告失败")
+		return
+	}
+	req, ok := bindFeedbackStatus(c)
+	if !ok {
+		return
+	}
+	if err := f.FeedbackService.UpdateReportStatus(c.Request.Context(), c.GetInt("user_id"), report, req); err != nil {
+		utils.ServerErrorWithMsg(c, "修改错误报告状态失败")
+		return
+	}
+	utils.Ok(c)
+}
+
+// inboxOwner 收件箱查询的题目创建者，管理员查看全部题目
+func inboxOwner(c *gin.Context) int {
+	if c.GetString("role") == string(enums.RoleAdmin) {
+		return 0
+	}
+	return c.GetInt("user_id")
+}
+
+// InboxReports 分页查询自己题目收到的错误报告(管理员查看全部)
+func (f *FeedbackController) InboxReports(c *gin.Context) {
+	req, ok := bindFeedbackList(c)
+	if !ok {
+		return
+	}
+	reports, total, err := f.FeedbackService.ListInboxReports(c.Request.Context(), inboxOwner(c), req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取错误报告失败")
+		return
+	}
+	list := make([]dto.ReportRes, 0, len(reports))
+	for _, report := range reports {
+		list = append(list, toReportRes(report))
+	}
+	utils.SuccessMsg(c, utils.NewPageResult(list, total, req.PageNum, req.PageSize), "获取错误报告成功")
+}
+
+// InboxComments 分页查询自己题目下其他用户发起的评论串(管理员查看全部)
+func (f *FeedbackController) InboxComments(c *gin.Context) {
+	req, ok := bindFeedbackList(c)
+	if !ok {
+		return
+	}
+	threads, replies, total, err := f.FeedbackService.ListInboxComments(c.Request.Context(), inboxOwner(c), req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取评论失败")
+		return
+	}
+	list := make([]dto.CommentRes, 0, len(threads))
+	for _, thread := range threads {
+		res := toCommentRes(thread)
+		res.ReplyCount = replies[thread.ID]
+		list = append(list, res)
+	}
+	utils.SuccessMsg(c, utils.NewPageResult(list, total, req.PageNum, req.PageSize), "获取评论成功")
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+)
+
+// maxImportFileSize 导入文件的大小上限
+const maxImportFileSize = 10 << 20
+
+type ImportController struct {
+	ImportService *services.ImportService
+}
+
+func NewImportController(importService *services.ImportService) *ImportController {
+	return &ImportController{ImportService: importService}
+}
+
+// ImportQuestions 从 JSON、CSV、XLSX、Moodle XML 或 GIFT 文件导入题目，dry_run 时只返回校验结果
+func (i *ImportController) ImportQuestions(c *gin.Context) {
+	var req dto.ImportQuestionsReq
+	if err := c.ShouldBind(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.BadRequestWithMsg(c, "请上传文件")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		utils.BadRequestWithMsg(c, fmt.Sprintf("文件大小不能超过%dMB", maxImportFileSize>>20))
+		return
+	}
+	format, err := i.ImportService.DetectFormat(req.Format, fileHeader.Filename)
+	if err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "读取文件失败")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize))
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "读取文件失败")
+		return
+	}
+
+	res, err := i.ImportService.ImportQuestions(c.Request.Context(), c.GetInt("user_id"), &req, format, data)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "导入失败: "+err.Error())
+		return
+	}
+	if len(res.Errors) > 0 && !req.DryRun && !req.SkipInvalid {
+		utils.FailMsgWithData(c, utils.ERROR_PARAM, "存在校验失败的题目，未导入任何题目", res)
+		return
+	}
+	msg := "导入题目成功"
+	if req.DryRun {
+		msg = "校验完成"
+	}
+	utils.SuccessMsg(c, res, msg)
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"aiquiz/config"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+)
+
+type KnowledgePointController struct {
+	KnowledgePointService *services.KnowledgePointService
+	QuestionService       *services.QuestionService
+}
+
+func NewKnowledgePointController(knowledgePointService *services.KnowledgePointService, questionService *services.QuestionService) *KnowledgePointController {
+	return &KnowledgePointController{KnowledgePointService: knowledgePointService, QuestionService: questionService}
+}
+
+// loadKnowledgePoint 获取路径中的知识点，失败时已写入响应
+func (k *KnowledgePointController) loadKnowledgePoint(c *gin.Context) (*model.KnowledgePoint, bool) {
+	id, err := strconv.Atoi(c.Param("knowledge_point_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的知识点ID")
+		return nil, false
+	}
+	point, err := k.KnowledgePointService.GetKnowledgePoint(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "知识点不存在")
+			return nil, false
+		}
+		utils.ServerErrorWithMsg(c, "获取知识点失败")
+		return nil, false
+	}
+	return point, true
+}
+
+// bindKnowledgePointReq 绑定并校验知识点的创建、修改请求，失败时已写入响应
+func bindKnowledgePointReq(c *gin.Context) (*dto.KnowledgePointReq, bool) {
+	var req dto.KnowledgePointReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return nil, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.BadRequestWithMsg(c, "知识点名称不能为空")
+		return nil, false
+	}
+	if len([]rune(req.Name)) > 100 {
+		utils.BadRequestWithMsg(c, "知识点名称不能超过100个字符")
+		return nil, false
+	}
+	return &req, true
+}
+
+// ListKnowledgePoints 以树形结构获取知识点及各知识点覆盖的题目数量(自己的题目与共享题目，管理员统计全部题目)
+func (k *KnowledgePointController) ListKnowledgePoints(c *gin.Context) {
+	var req dto.KnowledgePointTreeReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	tree, err := k.KnowledgePointService.ListTree(c.Request.Context(), req.Language, visibleScope(c))
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取知识点失败")
+		return
+	}
+	utils.SuccessMsg(c, tree, "获取知识点成功")
+}
+
+// GetKnowledgePoint 获取知识点及其下级知识点，关联的题目通过 GET /api/questions?knowledge_point_id= 查询
+func (k *KnowledgePointController) GetKnowledgePoint(c *gin.Context) {
+	point, ok := k.loadKnowledgePoint(c)
+	if !ok {
+		return
+	}
+	res, err := k.KnowledgePointService.GetTree(c.Request.Context(), point, visibleScope(c))
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取知识点失败")
+		return
+	}
+	utils.SuccessMsg(c, res, "获取知识点成功")
+}
+
+// CreateKnowledgePoint 创建知识点(管理员)
+func (k *KnowledgePointController) CreateKnowledgePoint(c *gin.Context) {
+	req, ok := bindKnowledgePointReq(c)
+	if !ok {
+		return
+	}
+	if _, ok := config.GetConfig(true).SupportedLanguages[req.Language]; !ok {
+		utils.BadRequestWithMsg(c, "无效的语言")
+		return
+	}
+	point, err := k.KnowledgePointService.CreateKnowledgePoint(c.Request.Context(), req)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "创建知识点失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, services.ToKnowledgePointRes(point), "创建知识点成功")
+}
+
+// UpdateKnowledgePoint 修改知识点名称、描述或移动到同一语言的其他知识点之下(管理员)
+func (k *KnowledgePointController) UpdateKnowledgePoint(c *gin.Context) {
+	point, ok := k.loadKnowledgePoint(c)
+	if !ok {
+		return
+	}
+	req, ok := bindKnowledgePointReq(c)
+	if !ok {
+		return
+	}
+	if req.Language != "" && req.Language != point.Language {
+		utils.BadRequestWithMsg(c, "不能修改知识点的语言")
+		return
+	}
+	if err := k.KnowledgePointService.UpdateKnowledgePoint(c.Request.Context(), point, req); err != nil {
+		utils.BadRequestWithMsg(c, "修改知识点失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// DeleteKnowledgePoint 删除知识点及其下级知识点，关联的题目不会被删除(管理员)
+func (k *KnowledgePointController) DeleteKnowledgePoint(c *gin.Context) {
+	point, ok := k.loadKnowledgePoint(c)
+	if !ok {
+		return
+	}
+	if err := k.KnowledgePointService.DeleteKnowledgePoint(c.Request.Context(), point.ID); err != nil {
+		utils.ServerErrorWithMsg(c, "删除知识点失败")
+		return
+	}
+	utils.Ok(c)
+}
+
+// ListQuestionKnowledgePoints 获取题目关联的知识点
+func (k *KnowledgePointController) ListQuestionKnowledgePoints(c *gin.Context) {
+	q, ok := loadVisibleQuestion(c, k.QuestionService)
+	if !ok {
+		return
+	}
+	list, err := k.KnowledgePointService.ListQuestionKnowledgePoints(c.Request.Context(), q)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取题目知识点失败")
+		return
+	}
+	utils.SuccessMsg(c, list, "获取题目知识点成功")
+}
+
+// SetQuestionKnowledgePoints 整体替换题目关联的知识点(题目创建者与管理员)
+func (k *KnowledgePointController) SetQuestionKnowledgePoints(c *gin.Context) {
+	q, ok := loadQuestion(c, k.QuestionService)
+	if !ok {
+		return
+	}
+	if !canManage(c, q.UserID) {
+		utils.NotPermission(c)
+		return
+	}
+	var req dto.QuestionKnowledgePointsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	if err := k.KnowledgePointService.SetQuestionKnowledgePoints(c.Request.Context(), q, req.KnowledgePointIDs); err != nil {
+		if errors.Is(err, services.ErrInvalidKnowledgePoint) {
+			utils.BadRequestWithMsg(c, err.Error())
+			return
+		}
+		utils.ServerErrorWithMsg(c, "设置题目知识点失败")
+		return
+	}
+	utils.Ok(c)
+}
+
+// PaperCoverage 统计试卷题目覆盖的知识点、题目数量与分值
+func (k *KnowledgePointController) PaperCoverage(c *gin.Context) {
+	res, err := k.KnowledgePointService.PaperCoverage(c.Request.Context(), c.GetInt("paper_id"))
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取试卷知识点覆盖情况失败")
+		return
+	}
+	utils.SuccessMsg(c, res, "获取试卷知识点覆盖情况成功")
+}
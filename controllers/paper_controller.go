@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"aiquiz/dao/model"
 	"aiquiz/models/dto"
 	"aiquiz/services"
 	"aiquiz/utils"
@@ -92,6 +93,25 @@ func (p *PaperController) AddPaperQuestions(c *gin.Context) {
 	utils.Ok(c)
 }
 
+// AddCollectionQuestions 将集合中的题目按集合中的顺序加入试卷，已在试卷中的题目跳过
+func (p *PaperController) AddCollectionQuestions(c *gin.Context) {
+	var req dto.AddCollectionToPaperReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	if req.CollectionID <= 0 || req.Score < 0 {
+		utils.ParamError(c)
+		return
+	}
+	added, err := p.PaperService.AddCollectionQuestions(c.Request.Context(), c.GetInt("user_id"), c.GetString("role") == "admin", c.GetInt("paper_id"), &req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "添加题目失败"+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, dto.CollectionQuestionsRes{Added: added}, "添加题目成功")
+}
+
 // UpdatePaper 更新试卷信息
 func (p *PaperController) UpdatePaper(c *gin.Context) {
 	paperID := c.GetInt("paper_id")
@@ -130,8 +150,27 @@ func (p *PaperController) GetPaper(c *gin.Context) {
 	// 先转换题目列表
 	var questions []dto.QuestionRes
 	for _, paperQues := range paper.Questions {
-		// 获取具体题目
+		// 获取具体题目(题目已被删除时跳过)
 		q := paperQues.Question
+		if q == nil {
+			continue
+		}
+		// 固定了版本的题目使用该版本的内容
+		revision := 0
+		if r := paperQues.Revision; r != nil {
+			revision = r.Revision
+			q = &model.Question{
+				ID:           q.ID,
+				Title:        r.Title,
+				QuestionType: r.QuestionType,
+				Options:      r.Options,
+				Answer:       r.Answer,
+				Explanation:  r.Explanation,
+				Keywords:     r.Keywords,
+				Language:     r.Language,
+				AiModel:      q.AiModel,
+			}
+		}
 
 		// 将json数组反序列化为option数组
 		optionsStr := q.Options
@@ -160,6 +199,7 @@ func (p *PaperController) GetPaper(c *gin.Context) {
 			AiModel:      q.AiModel,
 			Keywords:     q.Keywords,
 			Question:     ques,
+			Revision:     revision,
 		})
 	}
 
@@ -208,6 +248,34 @@ func (p *PaperController) DeletePaperQuestions(c *gin.Context) {
 	utils.Ok(c)
 }
 
+// PinQuestionRevision 固定试卷中题目使用的版本，避免题目后续修改影响试卷
+func (p *PaperController) PinQuestionRevision(c *gin.Context) {
+	questionID, err := strconv.Atoi(c.Param("question_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的题目ID")
+		return
+	}
+	var req dto.PinQuestionRevisionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	if req.Revision < 0 {
+		utils.BadRequestWithMsg(c, "无效的版本号")
+		return
+	}
+	updated, err := p.PaperService.PinQuestionRevision(c.Request.Context(), c.GetInt("paper_id"), questionID, req.Revision)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "固定题目版本失败: "+err.Error())
+		return
+	}
+	if !updated {
+		utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "试卷中没有该题目")
+		return
+	}
+	utils.Ok(c)
+}
+
 func (p *PaperController) UpdatePaperQuestionOrder(c *gin.Context) {
 	var req []dto.QuestionOrderReq
 	if err := c.ShouldBindJSON(&req); err != nil {
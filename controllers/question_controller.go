@@ -8,17 +8,25 @@ import (
 	"aiquiz/services"
 	"aiquiz/utils"
 	"aiquiz/utils/enums"
+	"aiquiz/utils/markdown"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
 	"strconv"
+	"strings"
 )
 
 type QuestionController struct {
-	QuestionService *services.QuestionService
+	QuestionService  *services.QuestionService
+	CodeRunService   *services.CodeRunService
+	DuplicateService *services.DuplicateService
 }
 
-func NewQuestionController(questionService *services.QuestionService) *QuestionController {
-	return &QuestionController{QuestionService: questionService}
+func NewQuestionController(questionService *services.QuestionService, codeRunService *services.CodeRunService, duplicateService *services.DuplicateService) *QuestionController {
+	return &QuestionController{QuestionService: questionService, CodeRunService: codeRunService, DuplicateService: duplicateService}
 }
 
 // GenerateQuestion 调用ai模型生成题目并验证
@@ -46,29 +54,19 @@ func (q *QuestionController) GenerateQuestion(c *gin.Context) {
 		utils.BadRequestWithMsg(c, "无效的AI模型")
 		return
 	}
+	// 关键词会被插入提示词，需拦截注入与异常输入
+	if err := ai.CheckKeywords(req.Keywords); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
 
-	// 调用ai模型
-	generatedQuestions, err := ai.GenerateQuestions(string(req.AiModel), req.Language, string(req.QuestionType), req.Keywords, req.Count)
+	// 调用ai模型(若有生效的实验会按用户分组)
+	questionResponseList, err := q.QuestionService.GenerateQuestions(c.Request.Context(), c.GetInt("user_id"), &req)
 	// 应该重试
 	if err != nil {
 		utils.FailMsg(c, utils.ERROR_AI_GENERATE, "生成题目失败"+err.Error())
 		return
 	}
-	if generatedQuestions == nil || len(generatedQuestions.Questions) == 0 {
-		utils.FailMsg(c, utils.ERROR_AI_GENERATE, "生成题目失败,请重试")
-		return
-	}
-	var questionResponseList []dto.GenerateQuestionRes
-	for _, question := range generatedQuestions.Questions {
-		questionRes := dto.GenerateQuestionRes{
-			Question:     question,
-			QuestionType: string(req.QuestionType),
-			Language:     req.Language,
-			AiModel:      string(req.AiModel),
-			Keywords:     req.Keywords,
-		}
-		questionResponseList = append(questionResponseList, questionRes)
-	}
 	utils.SuccessMsg(c, questionResponseList, "生成题目成功")
 }
 
@@ -86,9 +84,14 @@ func (q *QuestionController) ConfirmQuestions(c *gin.Context) {
 		utils.BadRequestWithMsg(c, "请提供题目")
 		return
 	}
+	rejectMode := config.GetConfig(false).ModerationAction == ai.ModerationReject
 	questions := make([]model.Question, 0, len(reqs))
+	candidates := make([]services.DuplicateCandidate, 0, len(reqs))
+	generationIDs := make([]int, 0, len(reqs))
+	tagNames := make([][]string, 0, len(reqs))
+	knowledgePointIDs := make([][]int, 0, len(reqs))
 	// 转换为模型
-	for _, req := range reqs {
+	for i, req := range reqs {
 		if !enums.IsSupportedQuestionType(req.QuestionType) {
 			utils.BadRequestWithMsg(c, "无效的题目类型，必须是 'single' 或 'multiple'")
 			return
@@ -97,10 +100,40 @@ func (q *QuestionController) ConfirmQuestions(c *gin.Context) {
 			utils.BadRequestWithMsg(c, "无效的语言")
 			return
 		}
-		if !enums.IsSupportedAiModel(req.AiModel) {
+		// 手动录入的题目使用 manual 标记来源
+		if !enums.IsSupportedAiModel(req.AiModel) && req.AiModel != enums.AiModelManual {
 			utils.BadRequestWithMsg(c, "无效的AI模型")
 			return
 		}
+		if strings.TrimSpace(req.Keywords) == "" && len(req.Tags) == 0 {
+			utils.BadRequestWithMsg(c, "请提供关键词或标签")
+			return
+		}
+		if req.Difficulty != "" && !enums.IsSupportedDifficulty(req.Difficulty) {
+			utils.BadRequestWithMsg(c, "无效的难度，必须是 'easy'、'medium' 或 'hard'")
+			return
+		}
+		if req.Visibility != "" && !enums.IsSupportedVisibility(req.Visibility) {
+			utils.BadRequestWithMsg(c, "无效的可见范围，必须是 'private'、'organization' 或 'public'")
+			return
+		}
+		if err := ai.CleanQuestionContent(&req.Title, req.Options, &req.Explanation); err != nil {
+			utils.BadRequestWithMsg(c, fmt.Sprintf("第%d题内容格式错误: %v", i+1, err))
+			return
+		}
+		// 教师可能修改过题目，入库前再做一次本地规则审核
+		if rejectMode {
+			reasons := ai.ModerateQuestion(dto.Question{
+				Title:       req.Title,
+				Options:     req.Options,
+				Answer:      req.Answer,
+				Explanation: req.Explanation,
+			}, false)
+			if len(reasons) > 0 {
+				utils.BadRequestWithMsg(c, fmt.Sprintf("第%d题未通过内容审核: %s", i+1, strings.Join(reasons, "; ")))
+				return
+			}
+		}
 		// 序列化 Options 为 JSON 字符串
 		optionBytes, err := json.Marshal(req.Options)
 		if err != nil {
@@ -120,40 +153,118 @@ func (q *QuestionController) ConfirmQuestions(c *gin.Context) {
 			Options:     string(optionBytes),
 			Answer:      answer,
 			Explanation: req.Explanation,
+			Difficulty:  string(req.Difficulty),
+			Visibility:  string(req.Visibility),
 			UserID:      userID,
 		}
 		questions = append(questions, question)
+		candidates = append(candidates, services.NewDuplicateCandidate(0, req.Language, req.Title, req.Options))
+		generationIDs = append(generationIDs, req.GenerationID)
+		tagNames = append(tagNames, req.Tags)
+		knowledgePointIDs = append(knowledgePointIDs, req.KnowledgePointIDs)
 	}
 	// 保存题目
-	err := q.QuestionService.ConfirmQuestions(c.Request.Context(), &questions)
+	err := q.QuestionService.ConfirmQuestions(c.Request.Context(), c.GetInt("user_id"), &questions, tagNames, generationIDs, knowledgePointIDs)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidKnowledgePoint) {
+			utils.BadRequestWithMsg(c, err.Error())
+			return
+		}
 		utils.ServerErrorWithMsg(c, "保存题目失败")
 		return
 	}
-	utils.Ok(c)
+	// 返回题库中与新题目可能重复的题目(不含本次一起入库的题目自身)，检测失败不影响入库结果
+	res := make([]dto.ConfirmQuestionRes, len(questions))
+	for i := range questions {
+		res[i].ID = questions[i].ID
+		candidates[i].ID = questions[i].ID
+	}
+	duplicates, err := q.DuplicateService.FindDuplicates(c.Request.Context(), c.GetInt("user_id"), candidates)
+	if err != nil {
+		log.Printf("检测重复题目失败: %v", err)
+	} else {
+		for i := range res {
+			res[i].Duplicates = duplicates[i]
+		}
+	}
+	utils.SuccessMsg(c, res, "保存题目成功")
 }
 
 // ListQuestions 根据查询条件分页查询题目
 func (q *QuestionController) ListQuestions(c *gin.Context) {
-	var req dto.ListQuestionsReq
-	if err := c.ShouldBindQuery(&req); err != nil {
-		utils.BadRequestWithMsg(c, err.Error())
+	req, ok := bindListQuestionsReq(c)
+	if !ok {
+		return
 	}
-	// 分页的默认值处理
-	page := utils.NewPage(req.PageNum, req.PageSize)
-	req.PageSize = page.PageSize
-	req.PageNum = page.PageNum
 	// 查询题目列表(管理员查询全部的题目)
 	userID := c.GetInt("user_id")
 	role := c.GetString("role")
 	if role == "admin" {
 		userID = 0
 	}
-	questions, total, err := q.QuestionService.ListQuestions(c.Request.Context(), userID, &req)
+	questions, total, err := q.QuestionService.ListQuestions(c.Request.Context(), userID, req)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "集合不存在")
+			return
+		}
 		utils.ServerErrorWithMsg(c, "获取题目失败")
 		return
 	}
+	writeQuestionList(c, req, questions, total)
+}
+
+// ListSharedQuestions 分页查询共享题库中的题目(organization 与 public)
+func (q *QuestionController) ListSharedQuestions(c *gin.Context) {
+	q.listSharedQuestions(c, false)
+}
+
+// ListPublicQuestions 分页查询公开的题目，无需登录
+func (q *QuestionController) ListPublicQuestions(c *gin.Context) {
+	q.listSharedQuestions(c, true)
+}
+
+func (q *QuestionController) listSharedQuestions(c *gin.Context, publicOnly bool) {
+	req, ok := bindListQuestionsReq(c)
+	if !ok {
+		return
+	}
+	questions, total, err := q.QuestionService.ListSharedQuestions(c.Request.Context(), req, publicOnly)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取题目失败")
+		return
+	}
+	writeQuestionList(c, req, questions, total)
+}
+
+// bindListQuestionsReq 绑定并校验题目列表查询参数，失败时已写入响应
+func bindListQuestionsReq(c *gin.Context) (*dto.ListQuestionsReq, bool) {
+	var req dto.ListQuestionsReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return nil, false
+	}
+	if req.TagMode != "" && req.TagMode != "and" && req.TagMode != "or" {
+		utils.BadRequestWithMsg(c, "无效的标签匹配方式，必须是 'and' 或 'or'")
+		return nil, false
+	}
+	if req.Visibility != "" && !enums.IsSupportedVisibility(req.Visibility) {
+		utils.BadRequestWithMsg(c, "无效的可见范围，必须是 'private'、'organization' 或 'public'")
+		return nil, false
+	}
+	if req.Status != "" && !enums.IsSupportedReviewStatus(req.Status) {
+		utils.BadRequestWithMsg(c, "无效的审核状态，必须是 'draft'、'in_review'、'approved' 或 'rejected'")
+		return nil, false
+	}
+	// 分页的默认值处理
+	page := utils.NewPage(req.PageNum, req.PageSize)
+	req.PageSize = page.PageSize
+	req.PageNum = page.PageNum
+	return &req, true
+}
+
+// writeQuestionList 将题目转换为分页结果返回，全文检索时附带高亮摘要
+func writeQuestionList(c *gin.Context, req *dto.ListQuestionsReq, questions []model.Question, total int64) {
 	// 转为res
 	var list = make([]dto.QuestionRes, 0, len(questions))
 	for _, question := range questions {
@@ -181,10 +292,31 @@ func (q *QuestionController) ListQuestions(c *gin.Context) {
 			QuestionType: question.QuestionType,
 			Language:     question.Language,
 			AiModel:      question.AiModel,
+			Difficulty:   question.Difficulty,
+			Visibility:   question.Visibility,
+			Status:       question.Status,
+			Reported:     question.OpenReports > 0,
+			OpenReports:  question.OpenReports,
+			ForkedFromID: question.ForkedFromID,
 			Keywords:     question.Keywords,
 			CreateAt:     question.CreatedAt.Format("2006-01-02 15:04:05"),
 			UserID:       question.UserID,
 			UserName:     question.User.Username,
+			Tags:         make([]string, 0, len(question.Tags)),
+		}
+		if question.ForkedFromUser != nil {
+			questionRes.ForkedFrom = question.ForkedFromUser.Username
+		}
+		for _, tag := range question.Tags {
+			questionRes.Tags = append(questionRes.Tags, tag.DisplayName)
+		}
+		if req.Q != "" {
+			texts := []string{question.Title}
+			for _, opt := range options {
+				texts = append(texts, opt.Content)
+			}
+			texts = append(texts, question.Explanation, question.Keywords)
+			questionRes.Snippet = utils.HighlightSnippet(req.Q, 40, texts...)
 		}
 		list = append(list, questionRes)
 	}
@@ -215,6 +347,18 @@ func (q *QuestionController) UpdateQuestion(c *gin.Context) {
 		utils.BadRequestWithMsg(c, err.Error())
 		return
 	}
+	if req.Difficulty != "" && !enums.IsSupportedDifficulty(req.Difficulty) {
+		utils.BadRequestWithMsg(c, "无效的难度，必须是 'easy'、'medium' 或 'hard'")
+		return
+	}
+	if req.Visibility != "" && !enums.IsSupportedVisibility(req.Visibility) {
+		utils.BadRequestWithMsg(c, "无效的可见范围，必须是 'private'、'organization' 或 'public'")
+		return
+	}
+	if err := ai.CleanQuestionContent(&req.Title, req.Options, &req.Explanation); err != nil {
+		utils.BadRequestWithMsg(c, "内容格式错误: "+err.Error())
+		return
+	}
 	// 更新题目
 	err = q.QuestionService.UpdateQuestion(c.Request.Context(), userID, questionID, req)
 	if err != nil {
@@ -250,3 +394,265 @@ func (q *QuestionController) DeleteQuestion(c *gin.Context) {
 	}
 	utils.Ok(c)
 }
+
+// checkQuestionPermission 解析路径中的题目ID并校验权限(题目创建者或管理员)，失败时已写入响应
+func (q *QuestionController) checkQuestionPermission(c *gin.Context) (int, bool) {
+	questionID, err := strconv.Atoi(c.Param("question_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的题目ID")
+		return 0, false
+	}
+	if c.GetString("role") != "admin" && !q.QuestionService.CheckQuestionPermission(c.Request.Context(), c.GetInt("user_id"), questionID) {
+		utils.NotPermission(c)
+		return 0, false
+	}
+	return questionID, true
+}
+
+// ListRevisions 获取题目的历史版本
+func (q *QuestionController) ListRevisions(c *gin.Context) {
+	questionID, ok := q.checkQuestionPermission(c)
+	if !ok {
+		return
+	}
+	revisions, err := q.QuestionService.ListRevisions(c.Request.Context(), questionID)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取题目版本失败")
+		return
+	}
+	list := make([]dto.QuestionRevisionRes, 0, len(revisions))
+	for i := range revisions {
+		r := &revisions[i]
+		var options []dto.Option
+		if err := json.Unmarshal([]byte(r.Options), &options); err != nil {
+			utils.ServerErrorWithMsg(c, "选项反序列化失败")
+			return
+		}
+		answer, err := strconv.Atoi(r.Answer)
+		if err != nil {
+			utils.ServerErrorWithMsg(c, "答案转换失败")
+			return
+		}
+		tags, err := services.RevisionTags(r)
+		if err != nil {
+			utils.ServerErrorWithMsg(c, err.Error())
+			return
+		}
+		res := dto.QuestionRevisionRes{
+			ID:           r.ID,
+			QuestionID:   r.QuestionID,
+			Revision:     r.Revision,
+			Action:       r.Action,
+			RestoredFrom: r.RestoredFrom,
+			Question: dto.Question{
+				Title:       r.Title,
+				Options:     options,
+				Answer:      answer,
+				Explanation: r.Explanation,
+			},
+			QuestionType: r.QuestionType,
+			Language:     r.Language,
+			Keywords:     r.Keywords,
+			Tags:         tags,
+			EditorID:     r.EditorID,
+			CreatedAt:    r.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if r.Editor != nil {
+			res.EditorName = r.Editor.Username
+		}
+		list = append(list, res)
+	}
+	utils.SuccessMsg(c, list, "获取题目版本成功")
+}
+
+// DiffRevisions 逐字段对比题目的两个版本
+func (q *QuestionController) DiffRevisions(c *gin.Context) {
+	questionID, ok := q.checkQuestionPermission(c)
+	if !ok {
+		return
+	}
+	var req dto.RevisionDiffReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	if req.From < 0 || req.To < 0 {
+		utils.BadRequestWithMsg(c, "无效的版本号")
+		return
+	}
+	diff, err := q.QuestionService.DiffRevisions(c.Request.Context(), questionID, &req)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "对比版本失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, diff, "对比版本成功")
+}
+
+// RestoreRevision 将题目恢复为指定版本
+func (q *QuestionController) RestoreRevision(c *gin.Context) {
+	questionID, ok := q.checkQuestionPermission(c)
+	if !ok {
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision < 1 {
+		utils.BadRequestWithMsg(c, "无效的版本号")
+		return
+	}
+	if err := q.QuestionService.RestoreRevision(c.Request.Context(), c.GetInt("user_id"), questionID, revision); err != nil {
+		utils.ServerErrorWithMsg(c, "恢复版本失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// BulkOperate 对多道题目执行批量操作(删除、修改语言、增删标签、修改难度、修改可见范围)，返回每道题目的处理结果
+func (q *QuestionController) BulkOperate(c *gin.Context) {
+	var req dto.BulkQuestionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	if (len(req.IDs) == 0) == (req.Filter == nil) {
+		utils.BadRequestWithMsg(c, "请指定题目ID列表或筛选条件(二选一)")
+		return
+	}
+	if req.Filter != nil && req.Filter.TagMode != "" && req.Filter.TagMode != "and" && req.Filter.TagMode != "or" {
+		utils.BadRequestWithMsg(c, "无效的标签匹配方式，必须是 'and' 或 'or'")
+		return
+	}
+	switch req.Operation {
+	case enums.BulkDelete:
+	case enums.BulkSetLanguage:
+		if _, ok := config.GetConfig(true).SupportedLanguages[req.Language]; !ok {
+			utils.BadRequestWithMsg(c, "无效的语言")
+			return
+		}
+	case enums.BulkAddTags, enums.BulkRemoveTags:
+		if len(utils.SplitKeywords(strings.Join(req.Tags, ","))) == 0 {
+			utils.BadRequestWithMsg(c, "请提供标签")
+			return
+		}
+	case enums.BulkSetDifficulty:
+		if !enums.IsSupportedDifficulty(req.Difficulty) {
+			utils.BadRequestWithMsg(c, "无效的难度，必须是 'easy'、'medium' 或 'hard'")
+			return
+		}
+	case enums.BulkSetVisibility:
+		if !enums.IsSupportedVisibility(req.Visibility) {
+			utils.BadRequestWithMsg(c, "无效的可见范围，必须是 'private'、'organization' 或 'public'")
+			return
+		}
+	case enums.BulkMoveToCollection:
+		if req.CollectionID <= 0 {
+			utils.BadRequestWithMsg(c, "请指定要加入的集合")
+			return
+		}
+	default:
+		utils.BadRequestWithMsg(c, "不支持的批量操作")
+		return
+	}
+	res, err := q.QuestionService.BulkOperate(c.Request.Context(), c.GetInt("user_id"), c.GetString("role") == "admin", &req)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "批量操作失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, res, "批量操作完成")
+}
+
+// ForkQuestion 将自己可见的题目(自己的题目或共享题目，管理员可复制全部题目)复制到自己的题库
+func (q *QuestionController) ForkQuestion(c *gin.Context) {
+	questionID, err := strconv.Atoi(c.Param("question_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的题目ID")
+		return
+	}
+	source, err := q.QuestionService.GetQuestion(c.Request.Context(), questionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "题目不存在")
+			return
+		}
+		utils.ServerErrorWithMsg(c, "获取题目失败")
+		return
+	}
+	if source.Visibility == string(enums.VisibilityPrivate) && !canManage(c, source.UserID) {
+		utils.NotPermission(c)
+		return
+	}
+	forked, err := q.QuestionService.ForkQuestion(c.Request.Context(), c.GetInt("user_id"), source)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "复制题目失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, dto.ForkQuestionRes{ID: forked.ID, ForkedFromID: forked.ForkedFromID}, "复制题目成功")
+}
+
+// RenderContent 校验 Markdown 内容并渲染为安全的 HTML 片段，用于编辑时预览
+func (q *QuestionController) RenderContent(c *gin.Context) {
+	var req dto.RenderReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	content, err := markdown.Clean(req.Content)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "内容格式错误: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, dto.RenderRes{HTML: markdown.Render(content)}, "渲染成功")
+}
+
+// VerifyOutput 运行输出类题目的题干代码，核对正确选项是否与实际运行结果一致，用于审核员确认入库前检查
+func (q *QuestionController) VerifyOutput(c *gin.Context) {
+	if !q.CodeRunService.Enabled() {
+		utils.BadRequestWithMsg(c, "未开启代码运行")
+		return
+	}
+	var req dto.VerifyOutputReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	if err := ai.CleanQuestionContent(&req.Question.Title, req.Question.Options, &req.Question.Explanation); err != nil {
+		utils.BadRequestWithMsg(c, "内容格式错误: "+err.Error())
+		return
+	}
+	res, err := q.CodeRunService.VerifyOutput(c.Request.Context(), req.Language, req.Question)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "运行代码失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, res, "核对完成")
+}
+
+// RenderQuestion 将题干、选项与解析渲染为安全的 HTML 片段，用于展示与打印
+func (q *QuestionController) RenderQuestion(c *gin.Context) {
+	question, ok := loadVisibleQuestion(c, q.QuestionService)
+	if !ok {
+		return
+	}
+	var options []dto.Option
+	if err := json.Unmarshal([]byte(question.Options), &options); err != nil {
+		utils.ServerErrorWithMsg(c, "选项反序列化失败")
+		return
+	}
+	answer, err := strconv.Atoi(question.Answer)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "答案转换失败")
+		return
+	}
+	res := dto.RenderedQuestionRes{
+		ID:           question.ID,
+		QuestionType: question.QuestionType,
+		Language:     question.Language,
+		Title:        markdown.Render(question.Title),
+		Options:      make([]dto.RenderedOption, 0, len(options)),
+		Answer:       answer,
+		Explanation:  markdown.Render(question.Explanation),
+	}
+	for _, opt := range options {
+		res.Options = append(res.Options, dto.RenderedOption{Value: opt.Value, HTML: markdown.Render(opt.Content)})
+	}
+	utils.SuccessMsg(c, res, "渲染成功")
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"aiquiz/utils/enums"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type ReviewController struct {
+	ReviewService   *services.ReviewService
+	QuestionService *services.QuestionService
+}
+
+func NewReviewController(reviewService *services.ReviewService, questionService *services.QuestionService) *ReviewController {
+	return &ReviewController{ReviewService: reviewService, QuestionService: questionService}
+}
+
+func isReviewer(c *gin.Context) bool {
+	role := c.GetString("role")
+	return role == string(enums.RoleReviewer) || role == string(enums.RoleAdmin)
+}
+
+// loadQuestion 解析路径中的题目ID并获取题目，失败时已写入响应
+func loadQuestion(c *gin.Context, questionService *services.QuestionService) (*model.Question, bool) {
+	questionID, err := strconv.Atoi(c.Param("question_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的题目ID")
+		return nil, false
+	}
+	q, err := questionService.GetQuestion(c.Request.Context(), questionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "题目不存在")
+			return nil, false
+		}
+		utils.ServerErrorWithMsg(c, "获取题目失败")
+		return nil, false
+	}
+	return q, true
+}
+
+// SubmitReview 题目作者(或管理员)将草稿或被驳回的题目提交审核
+func (r *ReviewController) SubmitReview(c *gin.Context) {
+	q, ok := loadQuestion(c, r.QuestionService)
+	if !ok {
+		return
+	}
+	if !canManage(c, q.UserID) {
+		utils.NotPermission(c)
+		return
+	}
+	var req dto.ReviewQuestionReq
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	if err := r.ReviewService.ChangeStatus(c.Request.Context(), c.GetInt("user_id"), q, enums.ReviewSubmit, strings.TrimSpace(req.Comment)); err != nil {
+		utils.BadRequestWithMsg(c, "提交审核失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// ApproveQuestion 审核通过
+func (r *ReviewController) ApproveQuestion(c *gin.Context) {
+	r.review(c, enums.ReviewApprove)
+}
+
+// RejectQuestion 审核驳回，必须填写审核意见
+func (r *ReviewController) RejectQuestion(c *gin.Context) {
+	r.review(c, enums.ReviewReject)
+}
+
+func (r *ReviewController) review(c *gin.Context, action enums.ReviewAction) {
+	q, ok := loadQuestion(c, r.QuestionService)
+	if !ok {
+		return
+	}
+	var req dto.ReviewQuestionReq
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if action == enums.ReviewReject && req.Comment == "" {
+		utils.BadRequestWithMsg(c, "驳回时请填写审核意见")
+		return
+	}
+	// 审核员不能审核自己的题目
+	userID := c.GetInt("user_id")
+	if q.UserID == userID && c.GetString("role") != string(enums.RoleAdmin) {
+		utils.FailMsg(c, utils.ERROR_NOT_PERMISSION, "不能审核自己的题目")
+		return
+	}
+	if err := r.ReviewService.ChangeStatus(c.Request.Context(), userID, q, action, req.Comment); err != nil {
+		utils.BadRequestWithMsg(c, "审核失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// ListQueue 分页查询待审核的题目
+func (r *ReviewController) ListQueue(c *gin.Context) {
+	req, ok := bindListQuestionsReq(c)
+	if !ok {
+		return
+	}
+	questions, total, err := r.ReviewService.ListQueue(c.Request.Context(), req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取待审核题目失败")
+		return
+	}
+	writeQuestionList(c, req, questions, total)
+}
+
+// ListReviews 获取题目的审核记录(题目作者、审核员或管理员)
+func (r *ReviewController) ListReviews(c *gin.Context) {
+	q, ok := loadQuestion(c, r.QuestionService)
+	if !ok {
+		return
+	}
+	if !canManage(c, q.UserID) && !isReviewer(c) {
+		utils.NotPermission(c)
+		return
+	}
+	reviews, err := r.ReviewService.ListReviews(c.Request.Context(), q.ID)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取审核记录失败")
+		return
+	}
+	list := make([]dto.QuestionReviewRes, 0, len(reviews))
+	for _, review := range reviews {
+		res := dto.QuestionReviewRes{
+			ID:         review.ID,
+			QuestionID: review.QuestionID,
+			Action:     review.Action,
+			FromStatus: review.FromStatus,
+			ToStatus:   review.ToStatus,
+			Comment:    review.Comment,
+			ReviewerID: review.ReviewerID,
+			CreatedAt:  review.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if review.Reviewer != nil {
+			res.ReviewerName = review.Reviewer.Username
+		}
+		list = append(list, res)
+	}
+	utils.SuccessMsg(c, list, "获取审核记录成功")
+}
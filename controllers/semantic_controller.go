@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+type SemanticController struct {
+	SemanticService *services.SemanticService
+	QuestionService *services.QuestionService
+}
+
+func NewSemanticController(semanticService *services.SemanticService, questionService *services.QuestionService) *SemanticController {
+	return &SemanticController{SemanticService: semanticService, QuestionService: questionService}
+}
+
+// visibleScope 语义检索的可见范围，管理员可检索全部题目
+func visibleScope(c *gin.Context) int {
+	if c.GetString("role") == "admin" {
+		return 0
+	}
+	return c.GetInt("user_id")
+}
+
+// Search 按语义检索自己的题目与共享题目
+func (s *SemanticController) Search(c *gin.Context) {
+	if !s.SemanticService.Enabled() {
+		utils.BadRequestWithMsg(c, "未开启语义检索")
+		return
+	}
+	var req dto.SemanticSearchReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	req.Q = strings.TrimSpace(req.Q)
+	if req.Q == "" {
+		utils.BadRequestWithMsg(c, "检索内容不能为空")
+		return
+	}
+	list, err := s.SemanticService.Search(c.Request.Context(), visibleScope(c), req.Q, req.Language, req.Limit)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "语义检索失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, list, "语义检索成功")
+}
+
+// Similar 查询与题目语义相近的题目
+func (s *SemanticController) Similar(c *gin.Context) {
+	if !s.SemanticService.Enabled() {
+		utils.BadRequestWithMsg(c, "未开启语义检索")
+		return
+	}
+	q, ok := loadVisibleQuestion(c, s.QuestionService)
+	if !ok {
+		return
+	}
+	var req dto.SimilarQuestionsReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	list, err := s.SemanticService.Similar(c.Request.Context(), visibleScope(c), q, req.Limit)
+	if err != nil {
+		if errors.Is(err, services.ErrEmbeddingPending) {
+			utils.BadRequestWithMsg(c, "题目向量尚未计算，请稍后再试")
+			return
+		}
+		utils.ServerErrorWithMsg(c, "获取相似题目失败")
+		return
+	}
+	utils.SuccessMsg(c, list, "获取相似题目成功")
+}
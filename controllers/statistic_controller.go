@@ -41,3 +41,13 @@ func (u *StatisticController) GetSystemStatistics(c *gin.Context) {
 	}
 	utils.SuccessMsg(c, statistics, "系统统计获取成功")
 }
+
+// GetExperimentStatistics 获取提示词/模型实验的采纳率统计
+func (u *StatisticController) GetExperimentStatistics(c *gin.Context) {
+	results, err := u.StatisticsService.GetExperimentResults(c.Request.Context())
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "实验统计失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, results, "实验统计获取成功")
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"aiquiz/config"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+)
+
+type SyllabusController struct {
+	SyllabusService *services.SyllabusService
+}
+
+func NewSyllabusController(syllabusService *services.SyllabusService) *SyllabusController {
+	return &SyllabusController{SyllabusService: syllabusService}
+}
+
+// getSyllabus 获取课程大纲，只有大纲所有者与管理员可以使用，失败时已写入响应
+func (sc *SyllabusController) getSyllabus(c *gin.Context, syllabusID int) (*model.Syllabus, bool) {
+	syllabus, err := sc.SyllabusService.GetSyllabus(c.Request.Context(), syllabusID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "课程大纲不存在")
+			return nil, false
+		}
+		utils.ServerErrorWithMsg(c, "获取课程大纲失败")
+		return nil, false
+	}
+	if !canManage(c, syllabus.UserID) {
+		utils.NotPermission(c)
+		return nil, false
+	}
+	return syllabus, true
+}
+
+// loadSyllabus 获取路径中的课程大纲，失败时已写入响应
+func (sc *SyllabusController) loadSyllabus(c *gin.Context) (*model.Syllabus, bool) {
+	syllabusID, err := strconv.Atoi(c.Param("syllabus_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的课程大纲ID")
+		return nil, false
+	}
+	return sc.getSyllabus(c, syllabusID)
+}
+
+// bindSyllabusReq 绑定并校验课程大纲的创建、修改请求，失败时已写入响应
+func bindSyllabusReq(c *gin.Context) (*dto.SyllabusReq, bool) {
+	var req dto.SyllabusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return nil, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.BadRequestWithMsg(c, "课程大纲名称不能为空")
+		return nil, false
+	}
+	if len([]rune(req.Name)) > 100 {
+		utils.BadRequestWithMsg(c, "课程大纲名称不能超过100个字符")
+		return nil, false
+	}
+	if req.Language != "" {
+		if _, ok := config.GetConfig(true).SupportedLanguages[req.Language]; !ok {
+			utils.BadRequestWithMsg(c, "无效的语言")
+			return nil, false
+		}
+	}
+	if len(req.Topics) == 0 {
+		utils.BadRequestWithMsg(c, "主题列表不能为空")
+		return nil, false
+	}
+	names := make(map[string]struct{}, len(req.Topics))
+	for i := range req.Topics {
+		topic := &req.Topics[i]
+		topic.Name = strings.TrimSpace(topic.Name)
+		if msg := checkSyllabusTopic(topic); msg != "" {
+			utils.BadRequestWithMsg(c, fmt.Sprintf("第%d个主题%s", i+1, msg))
+			return nil, false
+		}
+		if _, ok := names[topic.Name]; ok {
+			utils.BadRequestWithMsg(c, fmt.Sprintf("第%d个主题名称重复", i+1))
+			return nil, false
+		}
+		names[topic.Name] = struct{}{}
+	}
+	return &req, true
+}
+
+// checkSyllabusTopic 校验主题，返回错误原因
+func checkSyllabusTopic(topic *dto.SyllabusTopicReq) string {
+	if topic.Name == "" {
+		return "名称不能为空"
+	}
+	if len([]rune(topic.Name)) > 100 {
+		return "名称不能超过100个字符"
+	}
+	if topic.Weight <= 0 {
+		return "权重必须大于0"
+	}
+	if len(utils.SplitKeywords(strings.Join(topic.Tags, ","))) == 0 &&
+		len(utils.SplitKeywords(strings.Join(topic.Keywords, ","))) == 0 {
+		return "至少需要一个标签或关键词"
+	}
+	return ""
+}
+
+// CreateSyllabus 创建课程大纲
+func (sc *SyllabusController) CreateSyllabus(c *gin.Context) {
+	req, ok := bindSyllabusReq(c)
+	if !ok {
+		return
+	}
+	syllabus, err := sc.SyllabusService.CreateSyllabus(c.Request.Context(), c.GetInt("user_id"), req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "创建课程大纲失败")
+		return
+	}
+	utils.SuccessMsg(c, services.ToSyllabusRes(syllabus), "创建课程大纲成功")
+}
+
+// ListSyllabi 获取自己的全部课程大纲
+func (sc *SyllabusController) ListSyllabi(c *gin.Context) {
+	res, err := sc.SyllabusService.ListSyllabi(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取课程大纲失败")
+		return
+	}
+	utils.SuccessMsg(c, res, "获取课程大纲成功")
+}
+
+// GetSyllabus 获取课程大纲及其主题
+func (sc *SyllabusController) GetSyllabus(c *gin.Context) {
+	syllabus, ok := sc.loadSyllabus(c)
+	if !ok {
+		return
+	}
+	utils.SuccessMsg(c, services.ToSyllabusRes(syllabus), "获取课程大纲成功")
+}
+
+// UpdateSyllabus 修改课程大纲，请求中的主题列表整体替换原有主题
+func (sc *SyllabusController) UpdateSyllabus(c *gin.Context) {
+	syllabus, ok := sc.loadSyllabus(c)
+	if !ok {
+		return
+	}
+	req, ok := bindSyllabusReq(c)
+	if !ok {
+		return
+	}
+	if err := sc.SyllabusService.UpdateSyllabus(c.Request.Context(), syllabus, req); err != nil {
+		utils.ServerErrorWithMsg(c, "修改课程大纲失败")
+		return
+	}
+	utils.Ok(c)
+}
+
+// DeleteSyllabus 删除课程大纲
+func (sc *SyllabusController) DeleteSyllabus(c *gin.Context) {
+	syllabus, ok := sc.loadSyllabus(c)
+	if !ok {
+		return
+	}
+	if err := sc.SyllabusService.DeleteSyllabus(c.Request.Context(), syllabus.ID); err != nil {
+		utils.ServerErrorWithMsg(c, "删除课程大纲失败")
+		return
+	}
+	utils.Ok(c)
+}
+
+// PaperCoverage 按课程大纲统计试卷各主题的分值与目标的差距，并为缺少的主题推荐题库题目
+func (sc *SyllabusController) PaperCoverage(c *gin.Context) {
+	var req dto.SyllabusCoverageReq
+	if err := c.ShouldBindQuery(&req); err != nil || req.SyllabusID <= 0 {
+		utils.BadRequestWithMsg(c, "无效的课程大纲ID")
+		return
+	}
+	syllabus, ok := sc.getSyllabus(c, req.SyllabusID)
+	if !ok {
+		return
+	}
+	res, err := sc.SyllabusService.PaperCoverage(c.Request.Context(), c.GetInt("paper_id"), syllabus, visibleScope(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "试卷不存在")
+			return
+		}
+		utils.ServerErrorWithMsg(c, "获取试卷大纲覆盖情况失败")
+		return
+	}
+	utils.SuccessMsg(c, res, "获取试卷大纲覆盖情况成功")
+}
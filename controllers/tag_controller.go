@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+type TagController struct {
+	TagService *services.TagService
+}
+
+func NewTagController(tagService *services.TagService) *TagController {
+	return &TagController{TagService: tagService}
+}
+
+// ListTags 分页查询标签及其题目数量
+func (t *TagController) ListTags(c *gin.Context) {
+	var req dto.ListTagsReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return
+	}
+	page := utils.NewPage(req.PageNum, req.PageSize)
+	req.Page = page
+	list, total, err := t.TagService.ListTags(c.Request.Context(), &req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取标签失败")
+		return
+	}
+	utils.SuccessMsg(c, utils.NewPageResult(list, total, req.PageNum, req.PageSize), "获取标签成功")
+}
+
+// CreateTag 创建标签
+func (t *TagController) CreateTag(c *gin.Context) {
+	var req dto.TagReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	tag, err := t.TagService.CreateTag(c.Request.Context(), &req)
+	if err != nil {
+		utils.BadRequestWithMsg(c, "创建标签失败: "+err.Error())
+		return
+	}
+	utils.SuccessMsg(c, dto.TagRes{ID: tag.ID, Name: tag.Name, DisplayName: tag.DisplayName, Aliases: []dto.TagAliasRes{}}, "创建标签成功")
+}
+
+// UpdateTag 修改标签名称
+func (t *TagController) UpdateTag(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的标签ID")
+		return
+	}
+	var req dto.TagReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	if err := t.TagService.RenameTag(c.Request.Context(), tagID, &req); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.BadRequestWithMsg(c, "标签不存在")
+			return
+		}
+		utils.BadRequestWithMsg(c, "修改标签失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// DeleteTag 删除标签，题目上的该标签一并移除
+func (t *TagController) DeleteTag(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的标签ID")
+		return
+	}
+	if err := t.TagService.DeleteTag(c.Request.Context(), tagID); err != nil {
+		utils.ServerErrorWithMsg(c, "删除标签失败"+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// AddAlias 为标签添加别名
+func (t *TagController) AddAlias(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的标签ID")
+		return
+	}
+	var req dto.TagAliasReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	if err := t.TagService.AddAlias(c.Request.Context(), tagID, &req); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.BadRequestWithMsg(c, "标签不存在")
+			return
+		}
+		utils.BadRequestWithMsg(c, "添加别名失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// DeleteAlias 删除标签别名
+func (t *TagController) DeleteAlias(c *gin.Context) {
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的标签ID")
+		return
+	}
+	aliasID, err := strconv.Atoi(c.Param("alias_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的别名ID")
+		return
+	}
+	deleted, err := t.TagService.DeleteAlias(c.Request.Context(), tagID, aliasID)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "删除别名失败"+err.Error())
+		return
+	}
+	if !deleted {
+		utils.BadRequestWithMsg(c, "别名不存在")
+		return
+	}
+	utils.Ok(c)
+}
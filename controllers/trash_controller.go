@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"aiquiz/models/dto"
+	"aiquiz/services"
+	"aiquiz/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+type TrashController struct {
+	TrashService *services.TrashService
+}
+
+func NewTrashController(trashService *services.TrashService) *TrashController {
+	return &TrashController{TrashService: trashService}
+}
+
+// bindTrashList 绑定回收站分页参数，返回查询范围(管理员查询全部)
+func bindTrashList(c *gin.Context) (*dto.TrashListReq, int, bool) {
+	var req dto.TrashListReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequestWithMsg(c, err.Error())
+		return nil, 0, false
+	}
+	req.Page = utils.NewPage(req.PageNum, req.PageSize)
+	userID := c.GetInt("user_id")
+	if c.GetString("role") == "admin" {
+		userID = 0
+	}
+	return &req, userID, true
+}
+
+// canManage 只有资源所有者与管理员可以恢复或彻底删除
+func canManage(c *gin.Context, ownerID int) bool {
+	return c.GetString("role") == "admin" || c.GetInt("user_id") == ownerID
+}
+
+// ListQuestions 分页查询回收站中的题目
+func (t *TrashController) ListQuestions(c *gin.Context) {
+	req, userID, ok := bindTrashList(c)
+	if !ok {
+		return
+	}
+	questions, total, err := t.TrashService.ListQuestions(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取回收站题目失败")
+		return
+	}
+	list := make([]dto.TrashQuestionRes, 0, len(questions))
+	for _, q := range questions {
+		res := dto.TrashQuestionRes{
+			ID:           q.ID,
+			Title:        q.Title,
+			QuestionType: q.QuestionType,
+			Language:     q.Language,
+			Keywords:     q.Keywords,
+			UserID:       q.UserID,
+			DeletedAt:    q.DeletedAt.Time.Format("2006-01-02 15:04:05"),
+			ExpireAt:     t.TrashService.ExpireAt(q.DeletedAt.Time),
+		}
+		if q.User != nil {
+			res.UserName = q.User.Username
+		}
+		list = append(list, res)
+	}
+	utils.SuccessMsg(c, utils.NewPageResult(list, total, req.PageNum, req.PageSize), "获取回收站题目成功")
+}
+
+// ListPapers 分页查询回收站中的试卷
+func (t *TrashController) ListPapers(c *gin.Context) {
+	req, userID, ok := bindTrashList(c)
+	if !ok {
+		return
+	}
+	papers, total, err := t.TrashService.ListPapers(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取回收站试卷失败")
+		return
+	}
+	list := make([]dto.TrashPaperRes, 0, len(papers))
+	for _, p := range papers {
+		res := dto.TrashPaperRes{
+			ID:          p.ID,
+			Title:       p.Title,
+			Description: p.Description,
+			TotalScore:  p.TotalScore,
+			UserID:      p.CreatorID,
+			DeletedAt:   p.DeletedAt.Time.Format("2006-01-02 15:04:05"),
+			ExpireAt:    t.TrashService.ExpireAt(p.DeletedAt.Time),
+		}
+		if p.Creator != nil {
+			res.UserName = p.Creator.Username
+		}
+		list = append(list, res)
+	}
+	utils.SuccessMsg(c, utils.NewPageResult(list, total, req.PageNum, req.PageSize), "获取回收站试卷成功")
+}
+
+// ListPaperQuestions 分页查询从试卷中移除的题目
+func (t *TrashController) ListPaperQuestions(c *gin.Context) {
+	req, userID, ok := bindTrashList(c)
+	if !ok {
+		return
+	}
+	links, total, err := t.TrashService.ListPaperQuestions(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ServerErrorWithMsg(c, "获取回收站试卷题目失败")
+		return
+	}
+	list := make([]dto.TrashPaperQuestionRes, 0, len(links))
+	for _, link := range links {
+		list = append(list, dto.TrashPaperQuestionRes{
+			ID:            link.ID,
+			PaperID:       link.PaperID,
+			PaperTitle:    link.PaperTitle,
+			QuestionID:    link.QuestionID,
+			QuestionTitle: link.QuestionTitle,
+			QuestionOrder: link.QuestionOrder,
+			Score:         link.Score,
+			DeletedAt:     link.DeletedAt.Format("2006-01-02 15:04:05"),
+			ExpireAt:      t.TrashService.ExpireAt(link.DeletedAt),
+		})
+	}
+	utils.SuccessMsg(c, utils.NewPageResult(list, total, req.PageNum, req.PageSize), "获取回收站试卷题目成功")
+}
+
+// RestoreQuestion 从回收站恢复题目
+func (t *TrashController) RestoreQuestion(c *gin.Context) {
+	questionID, err := strconv.Atoi(c.Param("question_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的题目ID")
+		return
+	}
+	question, err := t.TrashService.GetQuestion(c.Request.Context(), questionID)
+	if err != nil {
+		trashLookupError(c, err)
+		return
+	}
+	if !canManage(c, question.UserID) {
+		utils.NotPermission(c)
+		return
+	}
+	if err := t.TrashService.RestoreQuestion(c.Request.Context(), question); err != nil {
+		utils.BadRequestWithMsg(c, "恢复题目失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// PurgeQuestion 彻底删除回收站中的题目
+func (t *TrashController) PurgeQuestion(c *gin.Context) {
+	questionID, err := strconv.Atoi(c.Param("question_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的题目ID")
+		return
+	}
+	question, err := t.TrashService.GetQuestion(c.Request.Context(), questionID)
+	if err != nil {
+		trashLookupError(c, err)
+		return
+	}
+	if !canManage(c, question.UserID) {
+		utils.NotPermission(c)
+		return
+	}
+	if err := t.TrashService.PurgeQuestion(c.Request.Context(), questionID); err != nil {
+		utils.ServerErrorWithMsg(c, "彻底删除题目失败"+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// RestorePaper 从回收站恢复试卷及与其一同删除的题目关联
+func (t *TrashController) RestorePaper(c *gin.Context) {
+	paperID, err := strconv.Atoi(c.Param("paper_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的试卷ID")
+		return
+	}
+	paper, err := t.TrashService.GetPaper(c.Request.Context(), paperID)
+	if err != nil {
+		trashLookupError(c, err)
+		return
+	}
+	if !canManage(c, paper.CreatorID) {
+		utils.NotPermission(c)
+		return
+	}
+	if err := t.TrashService.RestorePaper(c.Request.Context(), paper); err != nil {
+		utils.BadRequestWithMsg(c, "恢复试卷失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// PurgePaper 彻底删除回收站中的试卷
+func (t *TrashController) PurgePaper(c *gin.Context) {
+	paperID, err := strconv.Atoi(c.Param("paper_id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的试卷ID")
+		return
+	}
+	paper, err := t.TrashService.GetPaper(c.Request.Context(), paperID)
+	if err != nil {
+		trashLookupError(c, err)
+		return
+	}
+	if !canManage(c, paper.CreatorID) {
+		utils.NotPermission(c)
+		return
+	}
+	if err := t.TrashService.PurgePaper(c.Request.Context(), paperID); err != nil {
+		utils.ServerErrorWithMsg(c, "彻底删除试卷失败"+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// RestorePaperQuestion 将移除的题目恢复到试卷原来的位置
+func (t *TrashController) RestorePaperQuestion(c *gin.Context) {
+	linkID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的ID")
+		return
+	}
+	link, paper, err := t.TrashService.GetPaperQuestion(c.Request.Context(), linkID)
+	if err != nil {
+		trashLookupError(c, err)
+		return
+	}
+	if !canManage(c, paper.CreatorID) {
+		utils.NotPermission(c)
+		return
+	}
+	if err := t.TrashService.RestorePaperQuestion(c.Request.Context(), link); err != nil {
+		utils.BadRequestWithMsg(c, "恢复试卷题目失败: "+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+// PurgePaperQuestion 彻底删除从试卷中移除的题目关联
+func (t *TrashController) PurgePaperQuestion(c *gin.Context) {
+	linkID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestWithMsg(c, "无效的ID")
+		return
+	}
+	_, paper, err := t.TrashService.GetPaperQuestion(c.Request.Context(), linkID)
+	if err != nil {
+		trashLookupError(c, err)
+		return
+	}
+	if !canManage(c, paper.CreatorID) {
+		utils.NotPermission(c)
+		return
+	}
+	if err := t.TrashService.PurgePaperQuestion(c.Request.Context(), linkID); err != nil {
+		utils.ServerErrorWithMsg(c, "彻底删除试卷题目失败"+err.Error())
+		return
+	}
+	utils.Ok(c)
+}
+
+func trashLookupError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "回收站中没有该记录")
+		return
+	}
+	utils.ServerErrorWithMsg(c, "查询回收站失败")
+}
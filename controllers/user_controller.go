@@ -4,7 +4,10 @@ import (
 	"aiquiz/models/dto"
 	"aiquiz/services"
 	"aiquiz/utils"
+	"aiquiz/utils/enums"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"strconv"
 )
 
@@ -84,6 +87,41 @@ func (u *UserController) UpdateUser(c *gin.Context) {
 	utils.SuccessMsg(c, nil, "更新用户信息成功")
 }
 
+// UpdateUserRole 修改用户角色(仅管理员)，用户重新登录后生效
+func (u *UserController) UpdateUserRole(c *gin.Context) {
+	if c.GetString("role") != "admin" {
+		utils.FailMsg(c, utils.ERROR_UNAUTHORIZED, "无权限访问")
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ParamError(c)
+		return
+	}
+	var req dto.UpdateUserRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ParamError(c)
+		return
+	}
+	if !enums.IsSupportedRole(req.Role) {
+		utils.BadRequestWithMsg(c, "无效的角色，必须是 'user'、'reviewer' 或 'admin'")
+		return
+	}
+	if userID == c.GetInt("user_id") {
+		utils.BadRequestWithMsg(c, "不能修改自己的角色")
+		return
+	}
+	if err := u.UserService.UpdateUserRole(c.Request.Context(), userID, req.Role); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.FailMsg(c, utils.ERROR_RECORD_NOT_EXIST, "用户不存在")
+			return
+		}
+		utils.ServerErrorWithMsg(c, "修改用户角色失败")
+		return
+	}
+	utils.SuccessMsg(c, nil, "修改用户角色成功")
+}
+
 // DeleteUser 删除用户以及其级联数据
 func (u *UserController) DeleteUser(c *gin.Context) {
 	userID := c.GetInt("user_id")
@@ -0,0 +1,133 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils/enums"
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+// AttachmentDao 附件及题目对附件的引用
+type AttachmentDao struct {
+	DB *gorm.DB
+}
+
+func NewAttachmentDAO(db *gorm.DB) *AttachmentDao {
+	return &AttachmentDao{DB: db}
+}
+
+func (dao *AttachmentDao) CreateAttachment(c context.Context, attachment *model.Attachment) error {
+	return dao.DB.WithContext(c).Create(attachment).Error
+}
+
+func (dao *AttachmentDao) GetAttachment(c context.Context, attachmentID int) (*model.Attachment, error) {
+	var attachment model.Attachment
+	if err := dao.DB.WithContext(c).Where("id = ?", attachmentID).Take(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// GetAttachments 按ID批量获取附件，不存在的ID会被忽略
+func (dao *AttachmentDao) GetAttachments(c context.Context, attachmentIDs []int) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := dao.DB.WithContext(c).Where("id IN ?", attachmentIDs).Find(&attachments).Error
+	return attachments, err
+}
+
+// DeleteAttachments 删除附件及题目对它们的引用，文件由调用方在确认没有其他附件使用后删除
+func (dao *AttachmentDao) DeleteAttachments(c context.Context, attachmentIDs []int) error {
+	if len(attachmentIDs) == 0 {
+		return nil
+	}
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("attachment_id IN ?", attachmentIDs).Delete(&model.QuestionAttachment{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", attachmentIDs).Delete(&model.Attachment{}).Error
+	})
+}
+
+// ListQuestionAttachments 获取题目引用的附件，按题干、选项 value 与位置排序
+func (dao *AttachmentDao) ListQuestionAttachments(c context.Context, questionID int) ([]model.QuestionAttachment, error) {
+	var links []model.QuestionAttachment
+	err := dao.DB.WithContext(c).Where("question_id = ?", questionID).
+		Preload("Attachment").
+		Order("option_value, position").Find(&links).Error
+	return links, err
+}
+
+// ReplaceQuestionAttachments 整体替换题目引用的附件
+func (dao *AttachmentDao) ReplaceQuestionAttachments(c context.Context, questionID int, links []model.QuestionAttachment) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id = ?", questionID).Delete(&model.QuestionAttachment{}).Error; err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+// CopyQuestionAttachments 复制题目时让新题目引用与原题目相同的附件
+func (dao *AttachmentDao) CopyQuestionAttachments(c context.Context, tx *gorm.DB, fromQuestionID, toQuestionID int) error {
+	return tx.WithContext(c).Exec(`INSERT INTO question_attachments (question_id, attachment_id, option_value, position, created_at)
+		SELECT ?, attachment_id, option_value, position, ? FROM question_attachments WHERE question_id = ?`,
+		toQuestionID, time.Now(), fromQuestionID).Error
+}
+
+// IsReferencedByVisibleQuestion 附件是否被用户能看到的题目(自己的题目或共享题目，不含回收站中的题目)引用
+func (dao *AttachmentDao) IsReferencedByVisibleQuestion(c context.Context, attachmentID, userID int) (bool, error) {
+	var count int64
+	err := dao.DB.WithContext(c).Model(&model.QuestionAttachment{}).
+		Joins("JOIN questions ON questions.id = question_attachments.question_id AND questions.deleted_at IS NULL").
+		Where("question_attachments.attachment_id = ?", attachmentID).
+		Where("questions.user_id = ? OR questions.visibility IN ?", userID, enums.SharedVisibilities).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// IsReferenced 附件是否被题目(包括回收站中的题目)引用
+func (dao *AttachmentDao) IsReferenced(c context.Context, attachmentID int) (bool, error) {
+	var count int64
+	err := dao.DB.WithContext(c).Model(&model.QuestionAttachment{}).
+		Where("attachment_id = ?", attachmentID).Count(&count).Error
+	return count > 0, err
+}
+
+// ListOrphans 获取 before 之前上传且没有被任何题目引用的附件
+func (dao *AttachmentDao) ListOrphans(c context.Context, before time.Time) ([]model.Attachment, error) {
+	var attachments []model.Attachment
+	err := dao.DB.WithContext(c).
+		Where("created_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM question_attachments WHERE question_attachments.attachment_id = attachments.id)").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+// UnusedHashes 返回不再被任何附件使用的文件摘要
+func (dao *AttachmentDao) UnusedHashes(c context.Context, hashes []string) ([]string, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+	var used []string
+	err := dao.DB.WithContext(c).Model(&model.Attachment{}).
+		Where("hash IN ?", hashes).Distinct().Pluck("hash", &used).Error
+	if err != nil {
+		return nil, err
+	}
+	usedSet := make(map[string]struct{}, len(used))
+	for _, hash := range used {
+		usedSet[hash] = struct{}{}
+	}
+	var unused []string
+	for _, hash := range hashes {
+		if _, ok := usedSet[hash]; !ok {
+			unused = append(unused, hash)
+			usedSet[hash] = struct{}{}
+		}
+	}
+	return unused, nil
+}
@@ -0,0 +1,30 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CodeRunDao 代码运行结果缓存
+type CodeRunDao struct {
+	DB *gorm.DB
+}
+
+func NewCodeRunDAO(db *gorm.DB) *CodeRunDao {
+	return &CodeRunDao{DB: db}
+}
+
+func (dao *CodeRunDao) GetCodeRun(c context.Context, hash string) (*model.CodeRun, error) {
+	var run model.CodeRun
+	if err := dao.DB.WithContext(c).Where("hash = ?", hash).Take(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// SaveCodeRun 保存运行结果，同一代码并发运行时后写入的结果覆盖先写入的
+func (dao *CodeRunDao) SaveCodeRun(c context.Context, run *model.CodeRun) error {
+	return dao.DB.WithContext(c).Clauses(clause.OnConflict{UpdateAll: true}).Create(run).Error
+}
@@ -0,0 +1,189 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CollectionDao 题目集合
+type CollectionDao struct {
+	DB *gorm.DB
+}
+
+func NewCollectionDAO(db *gorm.DB) *CollectionDao {
+	return &CollectionDao{DB: db}
+}
+
+func (dao *CollectionDao) CreateCollection(c context.Context, collection *model.Collection) error {
+	return dao.DB.WithContext(c).Create(collection).Error
+}
+
+func (dao *CollectionDao) GetCollection(c context.Context, collectionID int) (*model.Collection, error) {
+	var collection model.Collection
+	if err := dao.DB.WithContext(c).Where("id = ?", collectionID).Take(&collection).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// ListCollections 获取用户的全部集合，按名称排序
+func (dao *CollectionDao) ListCollections(c context.Context, userID int) ([]model.Collection, error) {
+	var collections []model.Collection
+	err := dao.DB.WithContext(c).Where("user_id = ?", userID).Order("name, id").Find(&collections).Error
+	return collections, err
+}
+
+// CountQuestions 统计各集合中未删除的题目数量
+func (dao *CollectionDao) CountQuestions(c context.Context, collectionIDs []int) (map[int]int, error) {
+	var rows []struct {
+		CollectionID int
+		Count        int
+	}
+	err := dao.DB.WithContext(c).Model(&model.CollectionQuestion{}).
+		Select("collection_questions.collection_id, count(*) as count").
+		Joins("JOIN questions ON questions.id = collection_questions.question_id AND questions.deleted_at IS NULL").
+		Where("collection_questions.collection_id IN ?", collectionIDs).
+		Group("collection_questions.collection_id").Scan(&rows).Error
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[row.CollectionID] = row.Count
+	}
+	return counts, err
+}
+
+// UpdateCollection 修改集合的名称、描述与上级集合
+func (dao *CollectionDao) UpdateCollection(c context.Context, collection *model.Collection) error {
+	return dao.DB.WithContext(c).Model(&model.Collection{}).Where("id = ?", collection.ID).
+		Updates(map[string]interface{}{
+			"name":        collection.Name,
+			"description": collection.Description,
+			"parent_id":   collection.ParentID,
+		}).Error
+}
+
+// SubtreeIDs 返回集合自身及其全部下级集合的ID
+func (dao *CollectionDao) SubtreeIDs(c context.Context, collectionID int) ([]int, error) {
+	var ids []int
+	err := dao.DB.WithContext(c).Raw(`WITH RECURSIVE subtree(id) AS (
+		SELECT ? UNION SELECT collections.id FROM collections JOIN subtree ON collections.parent_id = subtree.id
+	) SELECT id FROM subtree`, collectionID).Scan(&ids).Error
+	return ids, err
+}
+
+// DeleteCollections 删除集合及其题目关联(题目本身不受影响)
+func (dao *CollectionDao) DeleteCollections(c context.Context, collectionIDs []int) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id IN ?", collectionIDs).Delete(&model.CollectionQuestion{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", collectionIDs).Delete(&model.Collection{}).Error
+	})
+}
+
+// DeleteCollectionsByUserID 删除用户的全部集合
+func (dao *CollectionDao) DeleteCollectionsByUserID(c context.Context, tx *gorm.DB, userID int) error {
+	err := tx.WithContext(c).
+		Where("collection_id IN (SELECT id FROM collections WHERE user_id = ?)", userID).
+		Delete(&model.CollectionQuestion{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.WithContext(c).Where("user_id = ?", userID).Delete(&model.Collection{}).Error
+}
+
+// AddQuestions 在事务中将题目按顺序追加到集合末尾，已在集合中的题目保持原位置，返回新加入的数量
+func (dao *CollectionDao) AddQuestions(c context.Context, tx *gorm.DB, collectionID int, questionIDs []int) (int, error) {
+	var maxPosition *int
+	err := tx.WithContext(c).Model(&model.CollectionQuestion{}).Select("MAX(position)").
+		Where("collection_id = ?", collectionID).Scan(&maxPosition).Error
+	if err != nil {
+		return 0, err
+	}
+	position := 0
+	if maxPosition != nil {
+		position = *maxPosition
+	}
+	var existing []int
+	err = tx.WithContext(c).Model(&model.CollectionQuestion{}).
+		Where("collection_id = ? AND question_id IN ?", collectionID, questionIDs).
+		Pluck("question_id", &existing).Error
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[int]struct{}, len(questionIDs))
+	for _, id := range existing {
+		seen[id] = struct{}{}
+	}
+	links := make([]model.CollectionQuestion, 0, len(questionIDs))
+	for _, id := range questionIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		position++
+		links = append(links, model.CollectionQuestion{CollectionID: collectionID, QuestionID: id, Position: position})
+	}
+	if len(links) == 0 {
+		return 0, nil
+	}
+	err = tx.WithContext(c).Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+	return len(links), err
+}
+
+// RemoveQuestions 在事务中将题目移出集合，返回移出的数量
+func (dao *CollectionDao) RemoveQuestions(c context.Context, tx *gorm.DB, collectionID int, questionIDs []int) (int64, error) {
+	result := tx.WithContext(c).Where("collection_id = ? AND question_id IN ?", collectionID, questionIDs).
+		Delete(&model.CollectionQuestion{})
+	return result.RowsAffected, result.Error
+}
+
+// ListQuestionIDs 按集合中的顺序返回未删除的题目ID
+func (dao *CollectionDao) ListQuestionIDs(c context.Context, collectionID int) ([]int, error) {
+	var ids []int
+	err := dao.DB.WithContext(c).Model(&model.CollectionQuestion{}).
+		Joins("JOIN questions ON questions.id = collection_questions.question_id AND questions.deleted_at IS NULL").
+		Where("collection_questions.collection_id = ?", collectionID).
+		Order("collection_questions.position").Pluck("collection_questions.question_id", &ids).Error
+	return ids, err
+}
+
+// ReorderQuestions 按给定顺序重排集合中的题目，未列出的题目保持原有相对顺序排在其后
+func (dao *CollectionDao) ReorderQuestions(c context.Context, collectionID int, questionIDs []int) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var current []int
+		err := tx.Model(&model.CollectionQuestion{}).Where("collection_id = ?", collectionID).
+			Order("position").Pluck("question_id", &current).Error
+		if err != nil {
+			return err
+		}
+		listed := make(map[int]struct{}, len(questionIDs))
+		for _, id := range questionIDs {
+			listed[id] = struct{}{}
+		}
+		order := append([]int{}, questionIDs...)
+		for _, id := range current {
+			if _, ok := listed[id]; !ok {
+				order = append(order, id)
+			}
+		}
+		for i, id := range order {
+			err := tx.Model(&model.CollectionQuestion{}).Where("collection_id = ? AND question_id = ?", collectionID, id).
+				Update("position", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetQuestionIDsIn 返回其中已在集合中的题目ID
+func (dao *CollectionDao) GetQuestionIDsIn(c context.Context, collectionID int, questionIDs []int) ([]int, error) {
+	var ids []int
+	err := dao.DB.WithContext(c).Model(&model.CollectionQuestion{}).
+		Where("collection_id = ? AND question_id IN ?", collectionID, questionIDs).
+		Pluck("question_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,153 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils"
+	"aiquiz/utils/enums"
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// DuplicateDao 题目相似度指纹的查询与重复题目的合并，指纹随全文索引一起写入(见 indexQuestions)
+type DuplicateDao struct {
+	DB *gorm.DB
+}
+
+func NewDuplicateDAO(db *gorm.DB) *DuplicateDao {
+	return &DuplicateDao{DB: db}
+}
+
+// saveFingerprints 写入或覆盖题目的相似度指纹
+func saveFingerprints(c context.Context, tx *gorm.DB, questions []model.Question) error {
+	fingerprints := make([]model.QuestionFingerprint, 0, len(questions))
+	for _, q := range questions {
+		fingerprints = append(fingerprints, model.QuestionFingerprint{
+			QuestionID: q.ID,
+			Language:   q.Language,
+			SimHash:    int64(utils.QuestionFingerprint(q.Title, optionList(q.Options))),
+		})
+	}
+	return tx.WithContext(c).Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(fingerprints, 200).Error
+}
+
+// ListFingerprints 获取指定语言题目的指纹，language 为空时获取全部；
+// visibleTo 大于 0 时只包含该用户自己的题目与共享题目
+func (dao *DuplicateDao) ListFingerprints(c context.Context, language string, visibleTo int) ([]model.QuestionFingerprint, error) {
+	query := dao.DB.WithContext(c).Model(&model.QuestionFingerprint{})
+	if language != "" {
+		query = query.Where("question_fingerprints.language = ?", language)
+	}
+	if visibleTo > 0 {
+		query = query.Joins("JOIN questions ON questions.id = question_fingerprints.question_id").
+			Where("questions.user_id = ? OR questions.visibility IN ?", visibleTo, enums.SharedVisibilities)
+	}
+	var fingerprints []model.QuestionFingerprint
+	err := query.Select("question_fingerprints.*").Order("question_fingerprints.question_id").Find(&fingerprints).Error
+	return fingerprints, err
+}
+
+// CountPaperUsage 统计题目被多少份未删除的试卷使用
+func (dao *DuplicateDao) CountPaperUsage(c context.Context, questionIDs []int) (map[int]int, error) {
+	var rows []struct {
+		QuestionID int
+		Count      int
+	}
+	err := dao.DB.WithContext(c).Model(&model.PaperQuestion{}).
+		Select("paper_questions.question_id, count(*) AS count").
+		Joins("JOIN papers ON papers.id = paper_questions.paper_id").
+		Where("paper_questions.question_id IN ? AND papers.deleted_at IS NULL", questionIDs).
+		Group("paper_questions.question_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[int]int, len(rows))
+	for _, row := range rows {
+		usage[row.QuestionID] = row.Count
+	}
+	return usage, nil
+}
+
+// MergeQuestions 合并重复题目: 试卷与集合中对重复题目的引用改为保留的题目(试卷中取消固定版本)，
+// 回收站中的试卷及其关联同样改为引用保留的题目。已包含保留题目的试卷中的重复关联移入回收站，
+// 集合中的重复关联直接删除，最后将重复题目移入回收站。返回改为引用保留题目的试卷关联数量
+func (dao *DuplicateDao) MergeQuestions(c context.Context, survivorID int, duplicateIDs []int) (int, error) {
+	repointed := 0
+	err := dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// 删除时间相同的关联会随试卷一起恢复，按试卷与删除时间判断试卷中是否已有保留的题目
+		type linkGroup struct {
+			paperID   int
+			deletedAt int64
+		}
+		groupOf := func(link *model.PaperQuestion) linkGroup {
+			group := linkGroup{paperID: link.PaperID}
+			if link.DeletedAt.Valid {
+				group.deletedAt = link.DeletedAt.Time.UnixNano()
+			}
+			return group
+		}
+		var survivorLinks []model.PaperQuestion
+		if err := tx.Unscoped().Where("question_id = ?", survivorID).Find(&survivorLinks).Error; err != nil {
+			return err
+		}
+		inPaper := make(map[linkGroup]bool, len(survivorLinks))
+		for i := range survivorLinks {
+			inPaper[groupOf(&survivorLinks[i])] = true
+		}
+		var links []model.PaperQuestion
+		if err := tx.Unscoped().Where("question_id IN ?", duplicateIDs).Order("id").Find(&links).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for i := range links {
+			link := &links[i]
+			group := groupOf(link)
+			updates := map[string]interface{}{"question_id": survivorID, "revision_id": 0}
+			if inPaper[group] {
+				// 未删除的关联移入回收站；已删除的关联改为单独删除，不再随试卷恢复
+				updates["deleted_at"] = now
+			} else {
+				inPaper[group] = true
+				if !link.DeletedAt.Valid {
+					repointed++
+				}
+			}
+			if err := tx.Unscoped().Model(&model.PaperQuestion{}).Where("id = ?", link.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		var collectionIDs []int
+		if err := tx.Model(&model.CollectionQuestion{}).Where("question_id = ?", survivorID).Pluck("collection_id", &collectionIDs).Error; err != nil {
+			return err
+		}
+		inCollection := make(map[int]bool, len(collectionIDs))
+		for _, id := range collectionIDs {
+			inCollection[id] = true
+		}
+		var collectionLinks []model.CollectionQuestion
+		if err := tx.Where("question_id IN ?", duplicateIDs).Order("collection_id, position").Find(&collectionLinks).Error; err != nil {
+			return err
+		}
+		for _, link := range collectionLinks {
+			query := tx.Model(&model.CollectionQuestion{}).Where("collection_id = ? AND question_id = ?", link.CollectionID, link.QuestionID)
+			if inCollection[link.CollectionID] {
+				if err := query.Delete(&model.CollectionQuestion{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := query.Update("question_id", survivorID).Error; err != nil {
+				return err
+			}
+			inCollection[link.CollectionID] = true
+		}
+
+		if err := tx.Where("id IN ?", duplicateIDs).Delete(&model.Question{}).Error; err != nil {
+			return err
+		}
+		return removeFromIndex(c, tx, duplicateIDs)
+	})
+	return repointed, err
+}
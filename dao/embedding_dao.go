@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils"
+	"aiquiz/utils/enums"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmbeddingDao 题目语义向量，题目内容变化时随全文索引一起删除旧向量(见 indexQuestions)，由后台任务重新计算
+type EmbeddingDao struct {
+	DB *gorm.DB
+}
+
+func NewEmbeddingDAO(db *gorm.DB) *EmbeddingDao {
+	return &EmbeddingDao{DB: db}
+}
+
+// PendingEmbedding 待计算向量的题目
+type PendingEmbedding struct {
+	QuestionID  int
+	Text        string
+	ContentHash string
+}
+
+func embeddingContent(q model.Question) (string, string) {
+	text := utils.EmbeddingText(q.Title, optionList(q.Options))
+	sum := sha256.Sum256([]byte(text))
+	return text, hex.EncodeToString(sum[:])
+}
+
+// invalidateEmbeddings 删除内容已变化的题目的向量
+func invalidateEmbeddings(c context.Context, tx *gorm.DB, questions []model.Question) error {
+	for _, q := range questions {
+		_, hash := embeddingContent(q)
+		err := tx.WithContext(c).Where("question_id = ? AND content_hash <> ?", q.ID, hash).Delete(&model.QuestionEmbedding{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListPending 获取还没有向量或向量不是由 embeddingModel 计算的未删除题目，按ID排列，跳过 excludeIDs
+func (dao *EmbeddingDao) ListPending(c context.Context, embeddingModel string, excludeIDs []int, limit int) ([]PendingEmbedding, error) {
+	query := dao.DB.WithContext(c).Model(&model.Question{}).
+		Select("questions.id, questions.title, questions.options").
+		Joins("LEFT JOIN question_embeddings ON question_embeddings.question_id = questions.id").
+		Where("question_embeddings.question_id IS NULL OR question_embeddings.model <> ?", embeddingModel)
+	if len(excludeIDs) > 0 {
+		query = query.Where("questions.id NOT IN ?", excludeIDs)
+	}
+	var questions []model.Question
+	if err := query.Order("questions.id").Limit(limit).Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	pending := make([]PendingEmbedding, 0, len(questions))
+	for _, q := range questions {
+		text, hash := embeddingContent(q)
+		pending = append(pending, PendingEmbedding{QuestionID: q.ID, Text: text, ContentHash: hash})
+	}
+	return pending, nil
+}
+
+// SaveEmbeddings 写入或覆盖题目的向量
+func (dao *EmbeddingDao) SaveEmbeddings(c context.Context, embeddings []model.QuestionEmbedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	return dao.DB.WithContext(c).Clauses(clause.OnConflict{UpdateAll: true}).Create(&embeddings).Error
+}
+
+// GetEmbedding 获取题目由 embeddingModel 计算的向量
+func (dao *EmbeddingDao) GetEmbedding(c context.Context, questionID int, embeddingModel string) (*model.QuestionEmbedding, error) {
+	var embedding model.QuestionEmbedding
+	err := dao.DB.WithContext(c).Where("question_id = ? AND model = ?", questionID, embeddingModel).Take(&embedding).Error
+	if err != nil {
+		return nil, err
+	}
+	return &embedding, nil
+}
+
+// ListEmbeddings 获取未删除题目由 embeddingModel 计算的向量，language 不为空时只包含该语言的题目，
+// visibleTo 大于 0 时只包含该用户自己的题目与共享题目
+func (dao *EmbeddingDao) ListEmbeddings(c context.Context, embeddingModel, language string, visibleTo int) ([]model.QuestionEmbedding, error) {
+	query := dao.DB.WithContext(c).Model(&model.QuestionEmbedding{}).
+		Joins("JOIN questions ON questions.id = question_embeddings.question_id AND questions.deleted_at IS NULL").
+		Where("question_embeddings.model = ?", embeddingModel)
+	if language != "" {
+		query = query.Where("questions.language = ?", language)
+	}
+	if visibleTo > 0 {
+		query = query.Where("questions.user_id = ? OR questions.visibility IN ?", visibleTo, enums.SharedVisibilities)
+	}
+	var embeddings []model.QuestionEmbedding
+	err := query.Select("question_embeddings.*").Find(&embeddings).Error
+	return embeddings, err
+}
@@ -0,0 +1,100 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils/enums"
+	"context"
+	"gorm.io/gorm"
+)
+
+type ExperimentDao struct {
+	DB *gorm.DB
+}
+
+func NewExperimentDAO(db *gorm.DB) *ExperimentDao {
+	return &ExperimentDao{DB: db}
+}
+
+// CreateExperiment 创建实验，开启的实验会同时关闭其他实验
+func (dao *ExperimentDao) CreateExperiment(c context.Context, experiment *model.Experiment) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(experiment).Error; err != nil {
+			return err
+		}
+		if !experiment.Active {
+			return nil
+		}
+		return deactivateOthers(tx, experiment.ID)
+	})
+}
+
+func (dao *ExperimentDao) ListExperiments(c context.Context) ([]model.Experiment, error) {
+	var experiments []model.Experiment
+	err := dao.DB.WithContext(c).Model(&model.Experiment{}).Order("created_at desc").Find(&experiments).Error
+	return experiments, err
+}
+
+func (dao *ExperimentDao) GetExperiment(c context.Context, experimentID int) (*model.Experiment, error) {
+	var experiment model.Experiment
+	err := dao.DB.WithContext(c).Where("id = ?", experimentID).Take(&experiment).Error
+	return &experiment, err
+}
+
+// UpdateExperiment 使用map更新，以便可以把 active 置为 false、traffic_percent 置为 0。
+// 开启实验时同时关闭其他实验
+func (dao *ExperimentDao) UpdateExperiment(c context.Context, experimentID int, fields map[string]interface{}) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Experiment{}).Where("id = ?", experimentID).Updates(fields).Error; err != nil {
+			return err
+		}
+		if active, ok := fields["active"].(bool); !ok || !active {
+			return nil
+		}
+		return deactivateOthers(tx, experimentID)
+	})
+}
+
+// deactivateOthers 关闭除指定实验外的所有实验，保证同时只有一个实验生效
+func deactivateOthers(tx *gorm.DB, experimentID int) error {
+	return tx.Model(&model.Experiment{}).
+		Where("id <> ? AND active = ?", experimentID, true).
+		Update("active", false).Error
+}
+
+func (dao *ExperimentDao) DeleteExperiment(c context.Context, experimentID int) error {
+	return dao.DB.WithContext(c).Delete(&model.Experiment{ID: experimentID}).Error
+}
+
+// GetActiveExperiment 获取当前生效的实验(开启实验时会关闭其他实验，同时只有一个实验生效)，没有时返回 nil
+func (dao *ExperimentDao) GetActiveExperiment(c context.Context) (*model.Experiment, error) {
+	var experiments []model.Experiment
+	err := dao.DB.WithContext(c).Model(&model.Experiment{}).
+		Where("active = ?", true).
+		Order("id desc").
+		Limit(1).
+		Find(&experiments).Error
+	if err != nil || len(experiments) == 0 {
+		return nil, err
+	}
+	return &experiments[0], nil
+}
+
+func (dao *ExperimentDao) AddGenerationRecords(c context.Context, records []model.GenerationRecord) error {
+	return dao.DB.WithContext(c).Create(&records).Error
+}
+
+// GetPendingGenerationRecords 获取用户尚未确认的生成记录
+func (dao *ExperimentDao) GetPendingGenerationRecords(c context.Context, userID int, recordIDs []int) ([]model.GenerationRecord, error) {
+	var records []model.GenerationRecord
+	err := dao.DB.WithContext(c).Model(&model.GenerationRecord{}).
+		Where("user_id = ? AND id IN ? AND outcome = ?", userID, recordIDs, enums.OutcomePending).
+		Find(&records).Error
+	return records, err
+}
+
+// UpdateGenerationOutcome 记录生成题目的确认结果
+func (dao *ExperimentDao) UpdateGenerationOutcome(c context.Context, recordID int, outcome enums.GenerationOutcome, questionID int) error {
+	return dao.DB.WithContext(c).Model(&model.GenerationRecord{}).
+		Where("id = ?", recordID).
+		Updates(map[string]interface{}{"outcome": outcome, "question_id": questionID}).Error
+}
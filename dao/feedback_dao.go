@@ -0,0 +1,176 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils"
+	"aiquiz/utils/enums"
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+// FeedbackDao 题目评论与错误报告
+type FeedbackDao struct {
+	DB *gorm.DB
+}
+
+func NewFeedbackDAO(db *gorm.DB) *FeedbackDao {
+	return &FeedbackDao{DB: db}
+}
+
+func selectUsername(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Select("id, username")
+}
+
+func selectQuestionTitle(db *gorm.DB) *gorm.DB {
+	return db.Select("id, title, user_id")
+}
+
+// AddComment 添加评论或回复
+func (dao *FeedbackDao) AddComment(c context.Context, comment *model.QuestionComment) error {
+	return dao.DB.WithContext(c).Create(comment).Error
+}
+
+// GetComment 获取题目下的评论
+func (dao *FeedbackDao) GetComment(c context.Context, questionID, commentID int) (*model.QuestionComment, error) {
+	var comment model.QuestionComment
+	err := dao.DB.WithContext(c).Where("id = ? AND question_id = ?", commentID, questionID).Take(&comment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListComments 按时间顺序获取题目的全部评论(含回复)
+func (dao *FeedbackDao) ListComments(c context.Context, questionID int) ([]model.QuestionComment, error) {
+	var comments []model.QuestionComment
+	err := dao.DB.WithContext(c).Where("question_id = ?", questionID).
+		Preload("User", selectUsername).Order("id").Find(&comments).Error
+	return comments, err
+}
+
+// DeleteComment 删除评论，删除评论串开头时其回复一并删除
+func (dao *FeedbackDao) DeleteComment(c context.Context, comment *model.QuestionComment) error {
+	query := dao.DB.WithContext(c).Where("id = ?", comment.ID)
+	if comment.ParentID == 0 {
+		query = query.Or("parent_id = ?", comment.ID)
+	}
+	return query.Delete(&model.QuestionComment{}).Error
+}
+
+// UpdateCommentStatus 修改评论串的状态，解决时记录处理人与时间
+func (dao *FeedbackDao) UpdateCommentStatus(c context.Context, commentID int, status enums.FeedbackStatus, userID int) error {
+	return dao.DB.WithContext(c).Model(&model.QuestionComment{}).Where("id = ?", commentID).
+		Updates(feedbackStatusColumns(status, userID)).Error
+}
+
+// feedbackStatusColumns 修改处理状态时需要更新的列，重新打开时清空处理人与时间
+func feedbackStatusColumns(status enums.FeedbackStatus, userID int) map[string]interface{} {
+	columns := map[string]interface{}{"status": string(status), "resolved_by": 0, "resolved_at": nil}
+	if status == enums.FeedbackResolved {
+		now := time.Now()
+		columns["resolved_by"] = userID
+		columns["resolved_at"] = &now
+	}
+	return columns
+}
+
+// AddReport 添加错误报告
+func (dao *FeedbackDao) AddReport(c context.Context, report *model.QuestionReport) error {
+	return dao.DB.WithContext(c).Create(report).Error
+}
+
+// AddReports 批量添加错误报告
+func (dao *FeedbackDao) AddReports(c context.Context, reports []model.QuestionReport) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	return dao.DB.WithContext(c).Create(&reports).Error
+}
+
+// GetReport 获取题目下的错误报告
+func (dao *FeedbackDao) GetReport(c context.Context, questionID, reportID int) (*model.QuestionReport, error) {
+	var report model.QuestionReport
+	err := dao.DB.WithContext(c).Where("id = ? AND question_id = ?", reportID, questionID).Take(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListReports 按时间倒序获取题目的错误报告，status 为空时不限状态
+func (dao *FeedbackDao) ListReports(c context.Context, questionID int, status string) ([]model.QuestionReport, error) {
+	query := dao.DB.WithContext(c).Where("question_id = ?", questionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var reports []model.QuestionReport
+	err := query.Preload("Reporter", selectUsername).Order("id desc").Find(&reports).Error
+	return reports, err
+}
+
+// UpdateReportStatus 修改错误报告的状态与处理说明
+func (dao *FeedbackDao) UpdateReportStatus(c context.Context, reportID int, status enums.FeedbackStatus, resolution string, userID int) error {
+	columns := feedbackStatusColumns(status, userID)
+	columns["resolution"] = resolution
+	return dao.DB.WithContext(c).Model(&model.QuestionReport{}).Where("id = ?", reportID).Updates(columns).Error
+}
+
+// ListInboxReports 分页查询用户题目(未删除)收到的错误报告，ownerID 为 0 时查询全部，status 为空时不限状态
+func (dao *FeedbackDao) ListInboxReports(c context.Context, ownerID int, status string, page utils.Page) ([]model.QuestionReport, int64, error) {
+	query := dao.DB.WithContext(c).Model(&model.QuestionReport{}).
+		Joins("JOIN questions ON questions.id = question_reports.question_id AND questions.deleted_at IS NULL")
+	if ownerID != 0 {
+		query = query.Where("questions.user_id = ?", ownerID)
+	}
+	if status != "" {
+		query = query.Where("question_reports.status = ?", status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var reports []model.QuestionReport
+	err := query.Order("question_reports.id desc").Scopes(utils.Paginate(page)).
+		Preload("Reporter", selectUsername).Preload("Question", selectQuestionTitle).
+		Find(&reports).Error
+	return reports, total, err
+}
+
+// ListInboxComments 分页查询用户题目(未删除)下其他用户发起的评论串，ownerID 为 0 时查询全部，status 为空时不限状态
+func (dao *FeedbackDao) ListInboxComments(c context.Context, ownerID int, status string, page utils.Page) ([]model.QuestionComment, int64, error) {
+	query := dao.DB.WithContext(c).Model(&model.QuestionComment{}).
+		Joins("JOIN questions ON questions.id = question_comments.question_id AND questions.deleted_at IS NULL").
+		Where("question_comments.parent_id = 0")
+	if ownerID != 0 {
+		query = query.Where("questions.user_id = ? AND question_comments.user_id <> ?", ownerID, ownerID)
+	}
+	if status != "" {
+		query = query.Where("question_comments.status = ?", status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var comments []model.QuestionComment
+	err := query.Order("question_comments.id desc").Scopes(utils.Paginate(page)).
+		Preload("User", selectUsername).Preload("Question", selectQuestionTitle).
+		Find(&comments).Error
+	return comments, total, err
+}
+
+// CountReplies 统计评论串的回复数量
+func (dao *FeedbackDao) CountReplies(c context.Context, threadIDs []int) (map[int]int, error) {
+	var rows []struct {
+		ParentID int
+		Count    int
+	}
+	err := dao.DB.WithContext(c).Model(&model.QuestionComment{}).
+		Select("parent_id, count(*) as count").Where("parent_id IN ?", threadIDs).
+		Group("parent_id").Scan(&rows).Error
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[row.ParentID] = row.Count
+	}
+	return counts, err
+}
@@ -0,0 +1,179 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils/enums"
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// KnowledgePointDao 知识点树及题目与知识点的关联
+type KnowledgePointDao struct {
+	DB *gorm.DB
+}
+
+func NewKnowledgePointDAO(db *gorm.DB) *KnowledgePointDao {
+	return &KnowledgePointDao{DB: db}
+}
+
+func (dao *KnowledgePointDao) CreateKnowledgePoint(c context.Context, point *model.KnowledgePoint) error {
+	return dao.DB.WithContext(c).Create(point).Error
+}
+
+func (dao *KnowledgePointDao) GetKnowledgePoint(c context.Context, id int) (*model.KnowledgePoint, error) {
+	var point model.KnowledgePoint
+	if err := dao.DB.WithContext(c).Where("id = ?", id).Take(&point).Error; err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+// ListKnowledgePoints 获取指定语言的全部知识点，language 为空时获取全部语言，按语言与名称排序
+func (dao *KnowledgePointDao) ListKnowledgePoints(c context.Context, language string) ([]model.KnowledgePoint, error) {
+	query := dao.DB.WithContext(c)
+	if language != "" {
+		query = query.Where("language = ?", language)
+	}
+	var points []model.KnowledgePoint
+	err := query.Order("language, name, id").Find(&points).Error
+	return points, err
+}
+
+func (dao *KnowledgePointDao) GetKnowledgePointsByIDs(c context.Context, ids []int) ([]model.KnowledgePoint, error) {
+	var points []model.KnowledgePoint
+	err := dao.DB.WithContext(c).Where("id IN ?", ids).Find(&points).Error
+	return points, err
+}
+
+// ExistsSibling 同一上级知识点下是否已有同名知识点，excludeID 为修改中的知识点自身
+func (dao *KnowledgePointDao) ExistsSibling(c context.Context, language string, parentID int, name string, excludeID int) (bool, error) {
+	var count int64
+	err := dao.DB.WithContext(c).Model(&model.KnowledgePoint{}).
+		Where("language = ? AND parent_id = ? AND name = ? AND id <> ?", language, parentID, name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateKnowledgePoint 修改知识点的名称、描述与上级知识点
+func (dao *KnowledgePointDao) UpdateKnowledgePoint(c context.Context, point *model.KnowledgePoint) error {
+	return dao.DB.WithContext(c).Model(&model.KnowledgePoint{}).Where("id = ?", point.ID).
+		Updates(map[string]interface{}{
+			"name":        point.Name,
+			"description": point.Description,
+			"parent_id":   point.ParentID,
+		}).Error
+}
+
+// SubtreeIDs 返回知识点自身及其全部下级知识点的ID
+func (dao *KnowledgePointDao) SubtreeIDs(c context.Context, id int) ([]int, error) {
+	var ids []int
+	err := dao.DB.WithContext(c).Raw(`WITH RECURSIVE subtree(id) AS (
+		SELECT ? UNION SELECT knowledge_points.id FROM knowledge_points JOIN subtree ON knowledge_points.parent_id = subtree.id
+	) SELECT id FROM subtree`, id).Scan(&ids).Error
+	return ids, err
+}
+
+// DeleteKnowledgePoints 删除知识点及题目与其的关联(题目本身不受影响)
+func (dao *KnowledgePointDao) DeleteKnowledgePoints(c context.Context, ids []int) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("knowledge_point_id IN ?", ids).Delete(&model.QuestionKnowledgePoint{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Delete(&model.KnowledgePoint{}).Error
+	})
+}
+
+// ListQuestionLinks 获取未删除题目与指定语言知识点的关联，language 为空时包含全部语言；
+// visibleTo 大于 0 时只包含该用户自己的题目与共享题目
+func (dao *KnowledgePointDao) ListQuestionLinks(c context.Context, language string, visibleTo int) ([]model.QuestionKnowledgePoint, error) {
+	query := dao.DB.WithContext(c).Model(&model.QuestionKnowledgePoint{}).
+		Joins("JOIN questions ON questions.id = question_knowledge_points.question_id AND questions.deleted_at IS NULL")
+	if language != "" {
+		query = query.Joins("JOIN knowledge_points ON knowledge_points.id = question_knowledge_points.knowledge_point_id").
+			Where("knowledge_points.language = ?", language)
+	}
+	if visibleTo > 0 {
+		query = query.Where("questions.user_id = ? OR questions.visibility IN ?", visibleTo, enums.SharedVisibilities)
+	}
+	var links []model.QuestionKnowledgePoint
+	err := query.Select("question_knowledge_points.*").Find(&links).Error
+	return links, err
+}
+
+// PaperKnowledgeLink 试卷中的题目关联的知识点及该题在试卷中的分值，KnowledgePointID 为 0 表示题目未关联知识点
+type PaperKnowledgeLink struct {
+	QuestionID       int
+	KnowledgePointID int
+	Score            int
+	Language         string
+}
+
+// ListPaperLinks 获取试卷中题目与知识点的关联，未关联知识点的题目也返回一条记录
+func (dao *KnowledgePointDao) ListPaperLinks(c context.Context, paperID int) ([]PaperKnowledgeLink, error) {
+	var links []PaperKnowledgeLink
+	err := dao.DB.WithContext(c).Model(&model.PaperQuestion{}).
+		Select("paper_questions.question_id, COALESCE(question_knowledge_points.knowledge_point_id, 0) AS knowledge_point_id, paper_questions.score, questions.language").
+		Joins("JOIN questions ON questions.id = paper_questions.question_id").
+		Joins("LEFT JOIN question_knowledge_points ON question_knowledge_points.question_id = paper_questions.question_id").
+		Where("paper_questions.paper_id = ?", paperID).
+		Scan(&links).Error
+	return links, err
+}
+
+// QuestionKnowledgePointRow 题目关联的知识点及关联来源
+type QuestionKnowledgePointRow struct {
+	model.KnowledgePoint
+	Source string
+}
+
+// ListQuestionKnowledgePoints 获取各题目关联的知识点
+func (dao *KnowledgePointDao) ListQuestionKnowledgePoints(c context.Context, questionIDs []int) (map[int][]QuestionKnowledgePointRow, error) {
+	var rows []struct {
+		QuestionKnowledgePointRow
+		QuestionID int
+	}
+	err := dao.DB.WithContext(c).Model(&model.QuestionKnowledgePoint{}).
+		Select("knowledge_points.*, question_knowledge_points.source, question_knowledge_points.question_id").
+		Joins("JOIN knowledge_points ON knowledge_points.id = question_knowledge_points.knowledge_point_id").
+		Where("question_knowledge_points.question_id IN ?", questionIDs).
+		Order("knowledge_points.id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int][]QuestionKnowledgePointRow, len(questionIDs))
+	for _, row := range rows {
+		result[row.QuestionID] = append(result[row.QuestionID], row.QuestionKnowledgePointRow)
+	}
+	return result, nil
+}
+
+// AddQuestionKnowledgePoints 在事务中添加题目与知识点的关联，已有的关联保持不变
+func (dao *KnowledgePointDao) AddQuestionKnowledgePoints(c context.Context, tx *gorm.DB, links []model.QuestionKnowledgePoint) error {
+	if len(links) == 0 {
+		return nil
+	}
+	return tx.WithContext(c).Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// SetQuestionKnowledgePoints 整体替换题目关联的知识点
+func (dao *KnowledgePointDao) SetQuestionKnowledgePoints(c context.Context, questionID int, ids []int, source string) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id = ?", questionID).Delete(&model.QuestionKnowledgePoint{}).Error; err != nil {
+			return err
+		}
+		links := make([]model.QuestionKnowledgePoint, 0, len(ids))
+		for _, id := range ids {
+			links = append(links, model.QuestionKnowledgePoint{QuestionID: questionID, KnowledgePointID: id, Source: source})
+		}
+		return dao.AddQuestionKnowledgePoints(c, tx, links)
+	})
+}
+
+// CopyQuestionKnowledgePoints 在事务中将题目关联的知识点复制给另一道题目
+func (dao *KnowledgePointDao) CopyQuestionKnowledgePoints(c context.Context, tx *gorm.DB, fromQuestionID, toQuestionID int) error {
+	return tx.WithContext(c).Exec(`INSERT OR IGNORE INTO question_knowledge_points (question_id, knowledge_point_id, source, created_at)
+		SELECT ?, knowledge_point_id, source, ? FROM question_knowledge_points WHERE question_id = ?`,
+		toQuestionID, time.Now(), fromQuestionID).Error
+}
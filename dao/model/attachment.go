@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Attachment 上传的附件，文件按内容的 SHA-256 摘要(Hash)存储，多个附件可以共用同一份文件
+type Attachment struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	Hash      string    `json:"hash" gorm:"size:64;not null;index"`
+	Filename  string    `json:"filename" gorm:"size:255;not null"`
+	MimeType  string    `json:"mime_type" gorm:"size:100;not null"`
+	Size      int64     `json:"size" gorm:"not null"`
+	UserID    int       `json:"user_id" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
+
+// QuestionAttachment 题目引用的附件，OptionValue 为 0 表示附在题干上，否则附在对应 value 的选项上
+type QuestionAttachment struct {
+	ID           int       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	QuestionID   int       `json:"question_id" gorm:"not null;index"`
+	AttachmentID int       `json:"attachment_id" gorm:"not null;index"`
+	OptionValue  int       `json:"option_value" gorm:"not null;default:0"`
+	Position     int       `json:"position" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	Attachment *Attachment `json:"attachment,omitempty" gorm:"foreignKey:AttachmentID"`
+}
+
+func (QuestionAttachment) TableName() string {
+	return "question_attachments"
+}
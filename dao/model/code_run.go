@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// CodeRun 代码运行结果缓存，Hash 为语言与补全后源码的 SHA-256 摘要，相同代码只运行一次
+type CodeRun struct {
+	Hash         string    `json:"hash" gorm:"primaryKey;size:64;not null"`
+	CompileError string    `json:"compile_error" gorm:"type:text;not null;default:''"`
+	Stdout       string    `json:"stdout" gorm:"type:text;not null;default:''"`
+	Stderr       string    `json:"stderr" gorm:"type:text;not null;default:''"`
+	ExitCode     int       `json:"exit_code" gorm:"not null;default:0"`
+	TimedOut     bool      `json:"timed_out" gorm:"not null;default:false"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (CodeRun) TableName() string {
+	return "code_runs"
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Collection 题目集合(文件夹)，ParentID 为 0 表示顶层集合
+type Collection struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	Name        string    `json:"name" gorm:"size:100;not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	ParentID    int       `json:"parent_id" gorm:"not null;default:0;index"`
+	UserID      int       `json:"user_id" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// CollectionQuestion 集合中的题目，同一道题目可以属于多个集合，Position 为题目在集合中的顺序
+type CollectionQuestion struct {
+	CollectionID int       `json:"collection_id" gorm:"primaryKey"`
+	QuestionID   int       `json:"question_id" gorm:"primaryKey;index"`
+	Position     int       `json:"position" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (CollectionQuestion) TableName() string {
+	return "collection_questions"
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Experiment 提示词/模型 A/B 实验
+type Experiment struct {
+	ID             int            `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	Name           string         `json:"name" gorm:"size:100;not null"`
+	Description    string         `json:"description" gorm:"type:text"`
+	VariantPrompt  string         `json:"variant_prompt" gorm:"size:50"`              // 实验组使用的提示词模板，为空时沿用默认模板
+	VariantModel   string         `json:"variant_model" gorm:"size:50"`               // 实验组使用的模型，为空时沿用请求的模型
+	TrafficPercent int            `json:"traffic_percent" gorm:"not null;default:50"` // 分配到实验组的用户比例(0-100)
+	Active         bool           `json:"active" gorm:"not null;default:false"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+func (Experiment) TableName() string {
+	return "experiments"
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// GenerationRecord 每道AI生成题目的记录，用于跟踪其最终是被原样确认、修改后确认还是被丢弃
+type GenerationRecord struct {
+	ID             int       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	UserID         int       `json:"user_id" gorm:"not null;index"`
+	ExperimentID   int       `json:"experiment_id" gorm:"not null;default:0;index:idx_generation_records_experiment,priority:1"` // 0 表示未参与实验
+	Variant        string    `json:"variant" gorm:"size:20;not null;index:idx_generation_records_experiment,priority:2"`         // control / treatment
+	PromptTemplate string    `json:"prompt_template" gorm:"size:50;not null"`
+	AiModel        string    `json:"ai_model" gorm:"size:50;not null"`
+	ContentHash    string    `json:"content_hash" gorm:"size:64;not null"` // 生成内容的哈希，确认时用于判断是否被修改
+	Outcome        string    `json:"outcome" gorm:"size:20;not null;default:pending"`
+	QuestionID     int       `json:"question_id" gorm:"not null;default:0"` // 确认入库后的题目ID
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (GenerationRecord) TableName() string {
+	return "generation_records"
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// KnowledgePoint 编程语言的知识点，按层级组织(如 Go → 并发 → 通道 → select)，ParentID 为 0 表示顶层知识点
+type KnowledgePoint struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	Language    string    `json:"language" gorm:"size:50;not null;index"`
+	Name        string    `json:"name" gorm:"size:100;not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	ParentID    int       `json:"parent_id" gorm:"not null;default:0;index"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (KnowledgePoint) TableName() string {
+	return "knowledge_points"
+}
+
+// QuestionKnowledgePoint 题目考查的知识点，同一道题目可以关联多个知识点
+type QuestionKnowledgePoint struct {
+	QuestionID       int       `json:"question_id" gorm:"primaryKey"`
+	KnowledgePointID int       `json:"knowledge_point_id" gorm:"primaryKey;index"`
+	Source           string    `json:"source" gorm:"size:20;not null;default:manual"` // manual / ai
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (QuestionKnowledgePoint) TableName() string {
+	return "question_knowledge_points"
+}
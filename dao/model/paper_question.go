@@ -12,12 +12,14 @@ type PaperQuestion struct {
 	QuestionID    int            `json:"question_id" gorm:"not null"`
 	QuestionOrder int            `json:"question_order" gorm:"not null"` // 题目顺序
 	Score         int            `json:"score" gorm:"default:5"`         // 该题分值
+	RevisionID    int            `json:"revision_id" gorm:"default:0"`   // 固定使用的题目版本，0 表示始终使用最新内容
 	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
 	// 关联
-	Question *Question `json:"question" gorm:"foreignKey:QuestionID"`
+	Question *Question         `json:"question" gorm:"foreignKey:QuestionID"`
+	Revision *QuestionRevision `json:"revision" gorm:"foreignKey:RevisionID"`
 }
 
 func (PaperQuestion) TableName() string {
@@ -7,22 +7,32 @@ import (
 
 // Question 题目模型
 type Question struct {
-	ID           int            `json:"id" gorm:"primaryKey;autoIncrement;not null"`
-	Title        string         `json:"title" gorm:"type:text;not null"`
-	QuestionType string         `json:"question_type" gorm:"size:20;not null"` // 'single' 或 'multiple'
-	Options      string         `json:"options" gorm:"type:text;not null"`     // JSON格式存储选项
-	Answer       string         `json:"answer" gorm:"type:text;not null"`
-	Explanation  string         `json:"explanation" gorm:"type:text"`
-	Keywords     string         `json:"keywords" gorm:"size:255"`
-	Language     string         `json:"language" gorm:"size:50;not null"` // 编程语言
-	AiModel      string         `json:"ai_model" gorm:"size:50;not null"` // 使用的AI模型
-	UserID       int            `json:"user_id" gorm:"not null"`
-	CreatedAt    time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt    time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"` // 使用指针表示可为空
+	ID               int            `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	Title            string         `json:"title" gorm:"type:text;not null"`
+	QuestionType     string         `json:"question_type" gorm:"size:20;not null"` // 'single' 或 'multiple'
+	Options          string         `json:"options" gorm:"type:text;not null"`     // JSON格式存储选项
+	Answer           string         `json:"answer" gorm:"type:text;not null"`
+	Explanation      string         `json:"explanation" gorm:"type:text"`
+	Keywords         string         `json:"keywords" gorm:"size:255"`
+	Language         string         `json:"language" gorm:"size:50;not null"`                   // 编程语言
+	AiModel          string         `json:"ai_model" gorm:"size:50;not null"`                   // 使用的AI模型
+	Difficulty       string         `json:"difficulty" gorm:"size:20;not null;default:''"`      // easy / medium / hard，空表示未设置
+	Visibility       string         `json:"visibility" gorm:"size:20;not null;default:private"` // private / organization / public
+	Status           string         `json:"status" gorm:"size:20;not null;default:draft"`       // 审核状态: draft / in_review / approved / rejected
+	ForkedFromID     int            `json:"forked_from_id" gorm:"default:0"`                    // 复制来源题目ID，0 表示原创
+	ForkedFromUserID int            `json:"forked_from_user_id" gorm:"default:0"`               // 复制来源题目的创建者，来源题目删除后仍保留署名
+	UserID           int            `json:"user_id" gorm:"not null"`
+	CreatedAt        time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"index"` // 使用指针表示可为空
 
 	// 关联
 	User *User `json:"user" gorm:"foreignKey:UserID"`
+	Tags []Tag `json:"tags" gorm:"many2many:question_tags"`
+	// 复制来源题目的创建者(不建外键，原创题目为 0)，由查询方法按需填充
+	ForkedFromUser *User `json:"forked_from_user" gorm:"-"`
+	// 未解决的错误报告数量，由查询方法按需填充
+	OpenReports int `json:"open_reports" gorm:"-"`
 }
 
 func (Question) TableName() string {
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// QuestionEmbedding 题目的语义向量(单位长度，float32 小端序)，ContentHash 为计算向量时题目内容的摘要，
+// 内容或模型变化后由后台任务重新计算
+type QuestionEmbedding struct {
+	QuestionID  int       `json:"question_id" gorm:"primaryKey;autoIncrement:false;not null"`
+	Model       string    `json:"model" gorm:"size:100;not null"`
+	ContentHash string    `json:"content_hash" gorm:"size:64;not null"`
+	Dimensions  int       `json:"dimensions" gorm:"not null"`
+	Vector      []byte    `json:"-" gorm:"not null"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (QuestionEmbedding) TableName() string {
+	return "question_embeddings"
+}
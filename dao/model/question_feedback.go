@@ -0,0 +1,52 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// QuestionComment 题目评论，ParentID 为 0 的评论是一个评论串的开头，回复的 ParentID 指向评论串开头
+type QuestionComment struct {
+	ID         int            `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	QuestionID int            `json:"question_id" gorm:"not null;index"`
+	ParentID   int            `json:"parent_id" gorm:"not null;default:0"`
+	UserID     int            `json:"user_id" gorm:"not null"`
+	Content    string         `json:"content" gorm:"type:text;not null"`
+	Status     string         `json:"status" gorm:"size:20;not null;default:open"` // 评论串状态: open / resolved，回复不使用
+	ResolvedBy int            `json:"resolved_by" gorm:"not null;default:0"`
+	ResolvedAt *time.Time     `json:"resolved_at"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// 关联
+	User     *User     `json:"user" gorm:"foreignKey:UserID"`
+	Question *Question `json:"question" gorm:"foreignKey:QuestionID"`
+}
+
+func (QuestionComment) TableName() string {
+	return "question_comments"
+}
+
+// QuestionReport 题目错误报告
+type QuestionReport struct {
+	ID          int        `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	QuestionID  int        `json:"question_id" gorm:"not null;index"`
+	ReporterID  int        `json:"reporter_id" gorm:"not null"`
+	Category    string     `json:"category" gorm:"size:20;not null"` // wrong_answer / ambiguous / outdated / typo / compile_error
+	Description string     `json:"description" gorm:"type:text"`
+	Status      string     `json:"status" gorm:"size:20;not null;default:open"` // open / resolved
+	Resolution  string     `json:"resolution" gorm:"type:text"`                 // 处理说明
+	ResolvedBy  int        `json:"resolved_by" gorm:"not null;default:0"`
+	ResolvedAt  *time.Time `json:"resolved_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// 关联
+	Reporter *User     `json:"reporter" gorm:"foreignKey:ReporterID"`
+	Question *Question `json:"question" gorm:"foreignKey:QuestionID"`
+}
+
+func (QuestionReport) TableName() string {
+	return "question_reports"
+}
@@ -0,0 +1,13 @@
+package model
+
+// QuestionFingerprint 题目内容的 SimHash 指纹(题干与选项)，用于检测相似题目，与全文索引同步维护，
+// 只包含未删除的题目
+type QuestionFingerprint struct {
+	QuestionID int    `json:"question_id" gorm:"primaryKey;autoIncrement:false;not null"`
+	Language   string `json:"language" gorm:"size:50;not null;index"`
+	SimHash    int64  `json:"sim_hash" gorm:"not null"` // 64 位指纹按位存储为有符号整数
+}
+
+func (QuestionFingerprint) TableName() string {
+	return "question_fingerprints"
+}
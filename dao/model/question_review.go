@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// QuestionReview 题目审核记录，每次提交、通过、驳回或因修改退回草稿都会记录一条
+type QuestionReview struct {
+	ID         int       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	QuestionID int       `json:"question_id" gorm:"not null;index"`
+	ReviewerID int       `json:"reviewer_id" gorm:"not null"`         // 操作人，提交审核时为题目作者
+	Action     string    `json:"action" gorm:"size:20;not null"`      // submit / approve / reject / reset
+	FromStatus string    `json:"from_status" gorm:"size:20;not null"` // 操作前的状态
+	ToStatus   string    `json:"to_status" gorm:"size:20;not null"`   // 操作后的状态
+	Comment    string    `json:"comment" gorm:"type:text"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// 关联
+	Reviewer *User `json:"reviewer" gorm:"foreignKey:ReviewerID"`
+}
+
+func (QuestionReview) TableName() string {
+	return "question_reviews"
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// QuestionRevision 题目的历史版本，每次创建、修改或恢复题目都会保存一份完整快照
+type QuestionRevision struct {
+	ID           int       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	QuestionID   int       `json:"question_id" gorm:"not null;uniqueIndex:idx_question_revisions_version,priority:1"`
+	Revision     int       `json:"revision" gorm:"not null;uniqueIndex:idx_question_revisions_version,priority:2"` // 题目内的版本号，从1开始递增
+	Action       string    `json:"action" gorm:"size:20;not null"`                                                 // create / update / restore
+	RestoredFrom int       `json:"restored_from" gorm:"not null;default:0"`                                        // 恢复操作的来源版本号
+	Title        string    `json:"title" gorm:"type:text;not null"`
+	QuestionType string    `json:"question_type" gorm:"size:20;not null"`
+	Options      string    `json:"options" gorm:"type:text;not null"`
+	Answer       string    `json:"answer" gorm:"type:text;not null"`
+	Explanation  string    `json:"explanation" gorm:"type:text"`
+	Keywords     string    `json:"keywords" gorm:"size:255"`
+	Tags         string    `json:"tags" gorm:"type:text"` // 标签展示名称的JSON数组
+	Language     string    `json:"language" gorm:"size:50;not null"`
+	EditorID     int       `json:"editor_id" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// 关联
+	Editor *User `json:"editor" gorm:"foreignKey:EditorID"`
+}
+
+func (QuestionRevision) TableName() string {
+	return "question_revisions"
+}
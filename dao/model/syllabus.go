@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Syllabus 课程大纲，Language 为空表示不限编程语言
+type Syllabus struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	Name        string    `json:"name" gorm:"size:100;not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	Language    string    `json:"language" gorm:"size:50;not null;default:''"`
+	UserID      int       `json:"user_id" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// 关联
+	Topics []SyllabusTopic `json:"topics" gorm:"foreignKey:SyllabusID"`
+}
+
+func (Syllabus) TableName() string {
+	return "syllabi"
+}
+
+// SyllabusTopic 大纲中的主题，题目带有 Tags 中任一标签或关键词包含 Keywords 中任一关键词即属于该主题，
+// Weight 为主题在试卷中应占分值的相对权重
+type SyllabusTopic struct {
+	ID         int    `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	SyllabusID int    `json:"syllabus_id" gorm:"not null;index"`
+	Name       string `json:"name" gorm:"size:100;not null"`
+	Tags       string `json:"tags" gorm:"size:255"`     // 逗号分隔
+	Keywords   string `json:"keywords" gorm:"size:255"` // 逗号分隔
+	Weight     int    `json:"weight" gorm:"not null;default:1"`
+	Position   int    `json:"position" gorm:"not null"`
+}
+
+func (SyllabusTopic) TableName() string {
+	return "syllabus_topics"
+}
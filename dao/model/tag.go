@@ -0,0 +1,45 @@
+package model
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Tag 题目标签，Name 为归一化后的名称(未删除的标签中唯一)，DisplayName 为展示名称
+type Tag struct {
+	ID          int            `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	Name        string         `json:"name" gorm:"size:100;uniqueIndex:idx_tags_name,where:deleted_at IS NULL;not null"`
+	DisplayName string         `json:"display_name" gorm:"size:100;not null"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// 关联
+	Aliases []TagAlias `json:"aliases" gorm:"foreignKey:TagID"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TagAlias 标签别名，Alias 为归一化后的名称，解析时先查别名再查标签名
+type TagAlias struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement;not null"`
+	Alias     string    `json:"alias" gorm:"size:100;uniqueIndex:idx_tag_aliases_alias;not null"`
+	TagID     int       `json:"tag_id" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (TagAlias) TableName() string {
+	return "tag_aliases"
+}
+
+// QuestionTag 题目与标签的多对多关联
+type QuestionTag struct {
+	QuestionID int `json:"question_id" gorm:"primaryKey"`
+	TagID      int `json:"tag_id" gorm:"primaryKey;index"`
+}
+
+func (QuestionTag) TableName() string {
+	return "question_tags"
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"gorm.io/gorm"
+	"time"
 )
 
 type PaperDao struct {
@@ -28,6 +29,8 @@ func (dao *PaperDao) GetPaper(c context.Context, paperID int) (*model.Paper, err
 		Preload("Questions").
 		// 预加载题目一对一关联
 		Preload("Questions.Question").
+		// 预加载固定的题目版本
+		Preload("Questions.Revision").
 		First(&paper).Error
 	return &paper, err
 }
@@ -102,27 +105,41 @@ func (dao *PaperDao) DeletePaperQuestion(c context.Context, paperID, questionID
 
 func (dao *PaperDao) DeletePaper(c context.Context, paperID int) error {
 	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// 试卷与其题目关联使用相同的删除时间，恢复试卷时据此找回一并删除的关联
+		now := time.Now()
 		// 先删除paper_questions表中的关联
-		err := tx.WithContext(c).Where("paper_id = ?", paperID).Delete(&model.PaperQuestion{}).Error
+		err := tx.WithContext(c).Model(&model.PaperQuestion{}).Where("paper_id = ?", paperID).Update("deleted_at", now).Error
 		if err != nil {
 			return err
 		}
 		// 再删除paper表
-		return tx.WithContext(c).Where("id = ?", paperID).Delete(&model.Paper{}).Error
+		return tx.WithContext(c).Model(&model.Paper{}).Where("id = ?", paperID).Update("deleted_at", now).Error
 	})
 }
 
-func (dao *PaperDao) GetPaperQuestionIDs(paperID int) ([]int, error) {
-	var questionIDs []int
-	err := dao.DB.Model(&model.PaperQuestion{}).Select("question_id").Where("paper_id = ?", paperID).Find(&questionIDs).Error
-	return questionIDs, err
+// GetPaperQuestions 获取试卷的全部题目关联
+func (dao *PaperDao) GetPaperQuestions(c context.Context, paperID int) ([]model.PaperQuestion, error) {
+	var paperQuestions []model.PaperQuestion
+	err := dao.DB.WithContext(c).Where("paper_id = ?", paperID).Find(&paperQuestions).Error
+	return paperQuestions, err
+}
+
+// UpdatePaperQuestionRevision 固定试卷中题目使用的版本，revisionID 为 0 时取消固定
+func (dao *PaperDao) UpdatePaperQuestionRevision(c context.Context, paperID, questionID, revisionID int) (bool, error) {
+	result := dao.DB.WithContext(c).Model(&model.PaperQuestion{}).
+		Where("paper_id = ? AND question_id = ?", paperID, questionID).
+		Update("revision_id", revisionID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
 }
 
 // UpdatePaperQuestionOrder 由于有唯一索引（paperID, order），无法使用插入冲突时更新。故选用先删除再新增的方式
 func (dao *PaperDao) UpdatePaperQuestionOrder(c context.Context, paperID int, questions []model.PaperQuestion) error {
 	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
-		// 删除该试卷下所有有效题目关联
-		if err := tx.Where("paper_id = ?", paperID).Delete(&model.PaperQuestion{}).Error; err != nil {
+		// 删除该试卷下所有有效题目关联(随即重建，直接物理删除，不进入回收站)
+		if err := tx.Unscoped().Where("paper_id = ? AND deleted_at IS NULL", paperID).Delete(&model.PaperQuestion{}).Error; err != nil {
 			return errors.New("删除旧题目关联失败")
 		}
 
@@ -145,17 +162,21 @@ func (dao *PaperDao) DeletePaperByUserID(c context.Context, tx *gorm.DB, userID
 		Find(&paperIDs).Error; err != nil {
 		return err
 	}
+	// 与 DeletePaper 一致，试卷与关联使用相同的删除时间
+	now := time.Now()
 	// 若有试卷，批量删除关联表数据
 	if len(paperIDs) > 0 {
 		if err := tx.WithContext(c).
+			Model(&model.PaperQuestion{}).
 			Where("paper_id IN (?)", paperIDs).
-			Delete(&model.PaperQuestion{}).Error; err != nil {
+			Update("deleted_at", now).Error; err != nil {
 			return err
 		}
 	}
 
 	// 删除试卷表数据
 	return tx.WithContext(c).
+		Model(&model.Paper{}).
 		Where("creator_id = ?", userID).
-		Delete(&model.Paper{}).Error
+		Update("deleted_at", now).Error
 }
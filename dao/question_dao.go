@@ -3,8 +3,10 @@ package dao
 import (
 	"aiquiz/dao/model"
 	"aiquiz/utils"
+	"aiquiz/utils/enums"
 	"context"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type QuestionDao struct {
@@ -17,37 +19,111 @@ func NewQuestionDAO(db *gorm.DB) *QuestionDao {
 	}
 }
 
-func (dao *QuestionDao) AddQuestions(c context.Context, questions *[]model.Question) error {
-	return dao.DB.WithContext(c).CreateInBatches(questions, len(*questions)).Error
+// AddQuestions 在事务中保存题目(含标签关联)及其初始版本，并写入全文索引
+func (dao *QuestionDao) AddQuestions(c context.Context, tx *gorm.DB, questions *[]model.Question) error {
+	if err := tx.WithContext(c).CreateInBatches(questions, len(*questions)).Error; err != nil {
+		return err
+	}
+	// 保存初始版本
+	for _, q := range *questions {
+		if err := addRevision(c, tx, q, q.UserID, enums.RevisionCreate, 0); err != nil {
+			return err
+		}
+	}
+	// 同步写入全文索引
+	return indexQuestions(c, tx, *questions)
 }
 
-func (dao *QuestionDao) ListQuestions(
-	c context.Context,
-	userID int,
-	title, questionType, keywords, language, aiModel string,
-	page utils.Page,
-) ([]model.Question, int64, error) {
+// QuestionFilter 题目列表查询条件，零值字段不参与过滤
+type QuestionFilter struct {
+	UserID            int
+	VisibleTo         int // 只查询该用户自己的题目与共享题目
+	Title             string
+	QuestionType      string
+	Keywords          string
+	Language          string
+	AiModel           string
+	Difficulty        string
+	Visibilities      []string
+	Status            string
+	ExcludeReported   bool   // 排除有未解决错误报告的题目
+	CollectionIDs     []int  // 属于其中任一集合
+	CollectionOrder   int    // 按该集合中的顺序排列(非全文检索时)
+	KnowledgePointIDs []int  // 关联其中任一知识点
+	Search            string // 全文检索
+	TagIDs            []int
+	TagMatchAll       bool // true 时须包含全部标签，否则包含任一标签即可
+}
 
-	var questions []model.Question
-	// 构建查询条件
-	query := dao.DB.WithContext(c).Model(&model.Question{}).Order("created_at desc")
-	if userID != 0 {
-		query = query.Where("user_id = ?", userID)
+// filterQuery 按条件构建题目查询(全文索引表中有同名列，需带上表名)
+func (dao *QuestionDao) filterQuery(c context.Context, filter QuestionFilter) *gorm.DB {
+	query := dao.DB.WithContext(c).Model(&model.Question{})
+	if filter.UserID != 0 {
+		query = query.Where("questions.user_id = ?", filter.UserID)
+	}
+	if filter.VisibleTo != 0 {
+		query = query.Where("(questions.user_id = ? OR questions.visibility IN ?)", filter.VisibleTo, enums.SharedVisibilities)
+	}
+	if filter.Status != "" {
+		query = query.Where("questions.status = ?", filter.Status)
+	}
+	if len(filter.Visibilities) > 0 {
+		query = query.Where("questions.visibility IN ?", filter.Visibilities)
+	}
+	if len(filter.CollectionIDs) > 0 {
+		query = query.Where("questions.id IN (SELECT question_id FROM collection_questions WHERE collection_id IN ?)", filter.CollectionIDs)
+	}
+	if len(filter.KnowledgePointIDs) > 0 {
+		query = query.Where("questions.id IN (SELECT question_id FROM question_knowledge_points WHERE knowledge_point_id IN ?)", filter.KnowledgePointIDs)
+	}
+	if filter.ExcludeReported {
+		query = query.Where("questions.id NOT IN (SELECT question_id FROM question_reports WHERE status = ?)", string(enums.FeedbackOpen))
 	}
-	if title != "" {
-		query = query.Where("title LIKE?", "%"+title+"%")
+	if filter.Title != "" {
+		query = query.Where("questions.title LIKE?", "%"+filter.Title+"%")
 	}
-	if questionType != "" {
-		query = query.Where("question_type =?", questionType)
+	if filter.QuestionType != "" {
+		query = query.Where("questions.question_type =?", filter.QuestionType)
 	}
-	if keywords != "" {
-		query = query.Where("keywords LIKE?", "%"+keywords+"%")
+	if filter.Keywords != "" {
+		query = query.Where("questions.keywords LIKE?", "%"+filter.Keywords+"%")
 	}
-	if language != "" {
-		query = query.Where("language =?", language)
+	if filter.Language != "" {
+		query = query.Where("questions.language =?", filter.Language)
 	}
-	if aiModel != "" {
-		query = query.Where("ai_model =?", aiModel)
+	if filter.AiModel != "" {
+		query = query.Where("questions.ai_model =?", filter.AiModel)
+	}
+	if filter.Difficulty != "" {
+		query = query.Where("questions.difficulty =?", filter.Difficulty)
+	}
+	if len(filter.TagIDs) > 0 {
+		if filter.TagMatchAll {
+			query = query.Where("questions.id IN (SELECT question_id FROM question_tags WHERE tag_id IN ? GROUP BY question_id HAVING COUNT(*) = ?)",
+				filter.TagIDs, len(filter.TagIDs))
+		} else {
+			query = query.Where("questions.id IN (SELECT question_id FROM question_tags WHERE tag_id IN ?)", filter.TagIDs)
+		}
+	}
+	if matchQuery := utils.NGramMatchQuery(filter.Search); matchQuery != "" {
+		query = query.Joins("JOIN "+questionFTSTable+" ON "+questionFTSTable+".rowid = questions.id").
+			Where(questionFTSTable+" MATCH ?", matchQuery)
+	}
+	return query
+}
+
+func (dao *QuestionDao) ListQuestions(c context.Context, filter QuestionFilter, page utils.Page) ([]model.Question, int64, error) {
+
+	var questions []model.Question
+	query := dao.filterQuery(c, filter)
+	// 全文检索时按相关度排序，指定集合时按集合中的顺序，否则按创建时间倒序
+	if utils.NGramMatchQuery(filter.Search) != "" {
+		query = query.Order("bm25(" + questionFTSTable + ", " + searchWeights + ")")
+	} else if filter.CollectionOrder != 0 {
+		query = query.Joins("JOIN collection_questions ON collection_questions.question_id = questions.id AND collection_questions.collection_id = ?", filter.CollectionOrder).
+			Order("collection_questions.position")
+	} else {
+		query = query.Order("questions.created_at desc")
 	}
 	// 查询总数
 	var total int64
@@ -58,17 +134,142 @@ func (dao *QuestionDao) ListQuestions(
 	// 使用分页器
 	err = query.Scopes(utils.Paginate(page)).Preload("User", func(db *gorm.DB) *gorm.DB {
 		return db.Select("id, username")
-	}).Find(&questions).Error
+	}).Preload("Tags").Find(&questions).Error
 
 	if err != nil {
 		return nil, 0, err
 	}
+	if err := dao.loadForkSources(c, questions); err != nil {
+		return nil, 0, err
+	}
+	if err := dao.loadOpenReports(c, questions); err != nil {
+		return nil, 0, err
+	}
 
 	return questions, total, nil
 }
 
-func (dao *QuestionDao) UpdateQuestion(c context.Context, q *model.Question) error {
-	return dao.DB.WithContext(c).Model(&model.Question{}).Where("id = ?", q.ID).Updates(q).Error
+// loadForkSources 填充复制来源题目的创建者，已删除的用户仍保留署名
+func (dao *QuestionDao) loadForkSources(c context.Context, questions []model.Question) error {
+	var userIDs []int
+	for _, q := range questions {
+		if q.ForkedFromUserID != 0 {
+			userIDs = append(userIDs, q.ForkedFromUserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	var users []model.User
+	if err := dao.DB.WithContext(c).Unscoped().Select("id, username").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return err
+	}
+	userMap := make(map[int]*model.User, len(users))
+	for i := range users {
+		userMap[users[i].ID] = &users[i]
+	}
+	for i := range questions {
+		questions[i].ForkedFromUser = userMap[questions[i].ForkedFromUserID]
+	}
+	return nil
+}
+
+// loadOpenReports 填充题目未解决的错误报告数量
+func (dao *QuestionDao) loadOpenReports(c context.Context, questions []model.Question) error {
+	if len(questions) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(questions))
+	for _, q := range questions {
+		ids = append(ids, q.ID)
+	}
+	var rows []struct {
+		QuestionID int
+		Count      int
+	}
+	err := dao.DB.WithContext(c).Model(&model.QuestionReport{}).Select("question_id, count(*) as count").
+		Where("question_id IN ? AND status = ?", ids, string(enums.FeedbackOpen)).
+		Group("question_id").Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[row.QuestionID] = row.Count
+	}
+	for i := range questions {
+		questions[i].OpenReports = counts[questions[i].ID]
+	}
+	return nil
+}
+
+// ListQuestionIDs 按条件查询题目ID，最多返回 limit 条
+func (dao *QuestionDao) ListQuestionIDs(c context.Context, filter QuestionFilter, limit int) ([]int, error) {
+	var ids []int
+	err := dao.filterQuery(c, filter).Order("questions.id").Limit(limit).Pluck("questions.id", &ids).Error
+	return ids, err
+}
+
+// GetQuestionsByIDs 批量获取题目及其标签，不存在的题目不返回
+func (dao *QuestionDao) GetQuestionsByIDs(c context.Context, ids []int) ([]model.Question, error) {
+	var questions []model.Question
+	err := dao.DB.WithContext(c).Where("id IN ?", ids).Preload("Tags").Find(&questions).Error
+	return questions, err
+}
+
+// UpdateQuestion 修改题目(零值字段不更新)并保存新版本
+func (dao *QuestionDao) UpdateQuestion(c context.Context, q *model.Question, editorID int) error {
+	return dao.updateQuestion(c, q, editorID, enums.RevisionUpdate, 0)
+}
+
+// RestoreQuestion 用历史版本的内容整体覆盖题目，并保存为一个新版本
+func (dao *QuestionDao) RestoreQuestion(c context.Context, q *model.Question, editorID, fromRevision int) error {
+	return dao.updateQuestion(c, q, editorID, enums.RevisionRestore, fromRevision)
+}
+
+func (dao *QuestionDao) updateQuestion(c context.Context, q *model.Question, editorID int, action enums.RevisionAction, restoredFrom int) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var before model.Question
+		if err := tx.Where("id = ?", q.ID).Take(&before).Error; err != nil {
+			return err
+		}
+		query := tx.Model(&model.Question{}).Where("id = ?", q.ID).Omit("Tags")
+		if action == enums.RevisionRestore {
+			query = query.Select(revisionFields)
+		}
+		if err := query.Updates(q).Error; err != nil {
+			return err
+		}
+		// 传入标签时整体替换题目的标签
+		if q.Tags != nil {
+			if err := tx.Model(&model.Question{ID: q.ID}).Association("Tags").Replace(q.Tags); err != nil {
+				return err
+			}
+		}
+		// 部分字段可能未更新，重新读取完整题目后更新全文索引并保存版本
+		var updated model.Question
+		if err := tx.Where("id = ?", q.ID).Preload("Tags").Take(&updated).Error; err != nil {
+			return err
+		}
+		if err := addRevision(c, tx, updated, editorID, action, restoredFrom); err != nil {
+			return err
+		}
+		// 审核过或待审核的题目修改内容后需重新审核
+		if err := resetReviewStatus(c, tx, before, updated, editorID); err != nil {
+			return err
+		}
+		return indexQuestions(c, tx, []model.Question{updated})
+	})
+}
+
+// GetQuestion 获取题目及其标签
+func (dao *QuestionDao) GetQuestion(c context.Context, questionID int) (*model.Question, error) {
+	var question model.Question
+	err := dao.DB.WithContext(c).Where("id = ?", questionID).Preload("Tags").Take(&question).Error
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
 }
 
 func (dao *QuestionDao) QueryQuestion(c context.Context, userID, questionID int) (*model.Question, error) {
@@ -81,15 +282,22 @@ func (dao *QuestionDao) QueryQuestion(c context.Context, userID, questionID int)
 }
 
 func (dao *QuestionDao) DeleteQuestion(c context.Context, questionID int) error {
-	return dao.DB.WithContext(c).Model(&model.Question{}).Delete(&model.Question{
-		ID: questionID,
-	}).Error
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Question{}).Delete(&model.Question{
+			ID: questionID,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return removeFromIndex(c, tx, []int{questionID})
+	})
 }
 
-// GetExistingQuestionIDs 返回存在于数据库中的题目 ID 列表
+// GetExistingQuestionIDs 返回存在于数据库中且用户可以使用的题目 ID 列表(自己的题目或共享题目)
 func (dao *QuestionDao) GetExistingQuestionIDs(c context.Context, userID int, questionIDList []int) ([]int, error) {
 	var existingIDs []int
-	err := dao.DB.WithContext(c).Model(&model.Question{}).Select("id").Where("user_id = ?", userID).
+	err := dao.DB.WithContext(c).Model(&model.Question{}).Select("id").
+		Where("user_id = ? OR visibility IN ?", userID, enums.SharedVisibilities).
 		Where("id IN ?", questionIDList).Find(&existingIDs).Error
 	if err != nil {
 		return nil, err
@@ -98,8 +306,95 @@ func (dao *QuestionDao) GetExistingQuestionIDs(c context.Context, userID int, qu
 
 }
 
+// GetUnapprovedQuestionIDs 返回其中未审核通过的题目 ID 列表
+func (dao *QuestionDao) GetUnapprovedQuestionIDs(c context.Context, questionIDList []int) ([]int, error) {
+	var ids []int
+	err := dao.DB.WithContext(c).Model(&model.Question{}).Where("id IN ?", questionIDList).
+		Where("status <> ?", string(enums.ReviewApproved)).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetReportedQuestionIDs 返回其中有未解决错误报告的题目 ID 列表
+func (dao *QuestionDao) GetReportedQuestionIDs(c context.Context, questionIDList []int) ([]int, error) {
+	var ids []int
+	err := dao.DB.WithContext(c).Model(&model.QuestionReport{}).Distinct("question_id").
+		Where("question_id IN ? AND status = ?", questionIDList, string(enums.FeedbackOpen)).
+		Order("question_id").Pluck("question_id", &ids).Error
+	return ids, err
+}
+
 func (dao *QuestionDao) DeleteQuestionByUserID(c context.Context, tx *gorm.DB, userID int) error {
+	// 先删除全文索引与相似度指纹
+	err := tx.WithContext(c).
+		Exec("DELETE FROM "+questionFTSTable+" WHERE rowid IN (SELECT id FROM questions WHERE user_id = ?)", userID).Error
+	if err != nil {
+		return err
+	}
+	err = tx.WithContext(c).
+		Exec("DELETE FROM question_fingerprints WHERE question_id IN (SELECT id FROM questions WHERE user_id = ?)", userID).Error
+	if err != nil {
+		return err
+	}
 	return tx.WithContext(c).
 		Where("user_id = ?", userID).
 		Delete(&model.Question{}).Error
 }
+
+// DeleteQuestions 在事务中批量删除题目(移入回收站)并移出全文索引
+func (dao *QuestionDao) DeleteQuestions(c context.Context, tx *gorm.DB, ids []int) error {
+	if err := tx.WithContext(c).Where("id IN ?", ids).Delete(&model.Question{}).Error; err != nil {
+		return err
+	}
+	return removeFromIndex(c, tx, ids)
+}
+
+// UpdateQuestionsColumn 在事务中批量修改题目的单个字段
+func (dao *QuestionDao) UpdateQuestionsColumn(c context.Context, tx *gorm.DB, ids []int, column string, value interface{}) error {
+	return tx.WithContext(c).Model(&model.Question{}).Where("id IN ?", ids).Update(column, value).Error
+}
+
+// AddQuestionTags 在事务中为题目添加标签，已有的关联保持不变
+func (dao *QuestionDao) AddQuestionTags(c context.Context, tx *gorm.DB, ids, tagIDs []int) error {
+	links := make([]model.QuestionTag, 0, len(ids)*len(tagIDs))
+	for _, id := range ids {
+		for _, tagID := range tagIDs {
+			links = append(links, model.QuestionTag{QuestionID: id, TagID: tagID})
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return tx.WithContext(c).Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// RemoveQuestionTags 在事务中移除题目的标签
+func (dao *QuestionDao) RemoveQuestionTags(c context.Context, tx *gorm.DB, ids, tagIDs []int) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	return tx.WithContext(c).Where("question_id IN ? AND tag_id IN ?", ids, tagIDs).Delete(&model.QuestionTag{}).Error
+}
+
+// SnapshotQuestions 在事务中重新读取批量修改后的题目，保存新版本并更新全文索引，
+// before 为修改前的题目，审核过或待审核的题目内容变化后退回草稿
+func (dao *QuestionDao) SnapshotQuestions(c context.Context, tx *gorm.DB, before []*model.Question, editorID int) error {
+	ids := make([]int, 0, len(before))
+	beforeByID := make(map[int]*model.Question, len(before))
+	for _, q := range before {
+		ids = append(ids, q.ID)
+		beforeByID[q.ID] = q
+	}
+	var questions []model.Question
+	if err := tx.WithContext(c).Where("id IN ?", ids).Preload("Tags").Find(&questions).Error; err != nil {
+		return err
+	}
+	for _, q := range questions {
+		if err := addRevision(c, tx, q, editorID, enums.RevisionUpdate, 0); err != nil {
+			return err
+		}
+		if err := resetReviewStatus(c, tx, *beforeByID[q.ID], q, editorID); err != nil {
+			return err
+		}
+	}
+	return indexQuestions(c, tx, questions)
+}
@@ -0,0 +1,65 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils/enums"
+	"context"
+	"gorm.io/gorm"
+)
+
+type QuestionReviewDao struct {
+	DB *gorm.DB
+}
+
+func NewQuestionReviewDAO(db *gorm.DB) *QuestionReviewDao {
+	return &QuestionReviewDao{DB: db}
+}
+
+// UpdateStatus 在事务中将题目从 from 状态改为 to 状态，题目状态已被并发修改时返回 false
+func (dao *QuestionReviewDao) UpdateStatus(c context.Context, tx *gorm.DB, questionID int, from, to enums.ReviewStatus) (bool, error) {
+	result := tx.WithContext(c).Model(&model.Question{}).
+		Where("id = ? AND status = ?", questionID, string(from)).
+		Update("status", string(to))
+	return result.RowsAffected == 1, result.Error
+}
+
+// AddReview 在事务中保存审核记录
+func (dao *QuestionReviewDao) AddReview(c context.Context, tx *gorm.DB, review *model.QuestionReview) error {
+	return tx.WithContext(c).Create(review).Error
+}
+
+// ListReviews 按时间倒序获取题目的审核记录
+func (dao *QuestionReviewDao) ListReviews(c context.Context, questionID int) ([]model.QuestionReview, error) {
+	var reviews []model.QuestionReview
+	err := dao.DB.WithContext(c).Where("question_id = ?", questionID).
+		Preload("Reviewer", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id, username")
+		}).
+		Order("id desc").Find(&reviews).Error
+	return reviews, err
+}
+
+// resetReviewStatus 题目内容被修改后退回草稿并记录，草稿状态的题目不处理
+func resetReviewStatus(c context.Context, tx *gorm.DB, before, after model.Question, editorID int) error {
+	if before.Status == "" || before.Status == string(enums.ReviewDraft) || sameContent(before, after) {
+		return nil
+	}
+	if err := tx.WithContext(c).Model(&model.Question{}).Where("id = ?", after.ID).
+		Update("status", string(enums.ReviewDraft)).Error; err != nil {
+		return err
+	}
+	return tx.WithContext(c).Create(&model.QuestionReview{
+		QuestionID: after.ID,
+		ReviewerID: editorID,
+		Action:     string(enums.ReviewReset),
+		FromStatus: before.Status,
+		ToStatus:   string(enums.ReviewDraft),
+		Comment:    "题目内容已修改，需重新提交审核",
+	}).Error
+}
+
+// sameContent 判断两个版本的题目内容(版本记录中的字段)是否一致
+func sameContent(a, b model.Question) bool {
+	return a.Title == b.Title && a.QuestionType == b.QuestionType && a.Options == b.Options &&
+		a.Answer == b.Answer && a.Explanation == b.Explanation && a.Keywords == b.Keywords && a.Language == b.Language
+}
@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils/enums"
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+)
+
+// revisionFields 恢复历史版本时需要整体覆盖的题目字段(零值也要写入)
+var revisionFields = []string{"title", "question_type", "options", "answer", "explanation", "keywords", "language"}
+
+type QuestionRevisionDao struct {
+	DB *gorm.DB
+}
+
+func NewQuestionRevisionDAO(db *gorm.DB) *QuestionRevisionDao {
+	return &QuestionRevisionDao{DB: db}
+}
+
+// addRevision 为题目当前内容保存一份快照，q 需已加载标签
+func addRevision(c context.Context, tx *gorm.DB, q model.Question, editorID int, action enums.RevisionAction, restoredFrom int) error {
+	var maxRevision *int
+	err := tx.WithContext(c).Model(&model.QuestionRevision{}).
+		Select("MAX(revision)").Where("question_id = ?", q.ID).Scan(&maxRevision).Error
+	if err != nil {
+		return err
+	}
+	next := 1
+	if maxRevision != nil {
+		next = *maxRevision + 1
+	}
+	tagNames := make([]string, 0, len(q.Tags))
+	for _, tag := range q.Tags {
+		tagNames = append(tagNames, tag.DisplayName)
+	}
+	tags, err := json.Marshal(tagNames)
+	if err != nil {
+		return err
+	}
+	return tx.WithContext(c).Create(&model.QuestionRevision{
+		QuestionID:   q.ID,
+		Revision:     next,
+		Action:       string(action),
+		RestoredFrom: restoredFrom,
+		Title:        q.Title,
+		QuestionType: q.QuestionType,
+		Options:      q.Options,
+		Answer:       q.Answer,
+		Explanation:  q.Explanation,
+		Keywords:     q.Keywords,
+		Tags:         string(tags),
+		Language:     q.Language,
+		EditorID:     editorID,
+	}).Error
+}
+
+// ListRevisions 按版本号倒序获取题目的全部版本
+func (dao *QuestionRevisionDao) ListRevisions(c context.Context, questionID int) ([]model.QuestionRevision, error) {
+	var revisions []model.QuestionRevision
+	err := dao.DB.WithContext(c).Where("question_id = ?", questionID).
+		Order("revision desc").
+		Preload("Editor", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id, username")
+		}).
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// GetRevision 获取题目的指定版本，revision 为 0 时获取最新版本
+func (dao *QuestionRevisionDao) GetRevision(c context.Context, questionID, revision int) (*model.QuestionRevision, error) {
+	query := dao.DB.WithContext(c).Where("question_id = ?", questionID)
+	if revision > 0 {
+		query = query.Where("revision = ?", revision)
+	} else {
+		query = query.Order("revision desc")
+	}
+	var r model.QuestionRevision
+	if err := query.Take(&r).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// BackfillRevisions 为还没有任何版本的历史题目补充初始版本(迁移使用)
+func (dao *QuestionRevisionDao) BackfillRevisions(c context.Context, tx *gorm.DB) error {
+	var questions []model.Question
+	err := tx.WithContext(c).Unscoped().
+		Where("id NOT IN (SELECT question_id FROM question_revisions)").
+		Preload("Tags").
+		Find(&questions).Error
+	if err != nil {
+		return err
+	}
+	for _, q := range questions {
+		if err := addRevision(c, tx, q, q.UserID, enums.RevisionCreate, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
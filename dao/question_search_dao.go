@@ -0,0 +1,112 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils"
+	"context"
+	"encoding/json"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// 全文索引表，rowid 与 questions.id 一致
+const questionFTSTable = "questions_fts"
+
+// searchWeights bm25 中各列的权重，依次为 title, options, explanation, keywords
+const searchWeights = "10.0, 3.0, 2.0, 5.0"
+
+// optionList 提取选项JSON中的各选项内容，无法解析时整体作为一项
+func optionList(options string) []string {
+	var opts []struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal([]byte(options), &opts); err != nil {
+		return []string{options}
+	}
+	contents := make([]string, 0, len(opts))
+	for _, opt := range opts {
+		contents = append(contents, opt.Content)
+	}
+	return contents
+}
+
+// optionContents 提取选项JSON中的选项内容
+func optionContents(options string) string {
+	return strings.Join(optionList(options), "\n")
+}
+
+// indexQuestions 写入或覆盖题目的全文索引与相似度指纹，并删除内容已变化的题目的语义向量
+func indexQuestions(c context.Context, tx *gorm.DB, questions []model.Question) error {
+	if len(questions) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(questions))
+	for _, q := range questions {
+		ids = append(ids, q.ID)
+	}
+	if err := removeFromIndex(c, tx, ids); err != nil {
+		return err
+	}
+	for _, q := range questions {
+		err := tx.WithContext(c).Exec(
+			"INSERT INTO "+questionFTSTable+" (rowid, title, options, explanation, keywords) VALUES (?, ?, ?, ?, ?)",
+			q.ID,
+			utils.NGramTokenize(q.Title),
+			utils.NGramTokenize(optionContents(q.Options)),
+			utils.NGramTokenize(q.Explanation),
+			utils.NGramTokenize(q.Keywords),
+		).Error
+		if err != nil {
+			return err
+		}
+	}
+	if err := invalidateEmbeddings(c, tx, questions); err != nil {
+		return err
+	}
+	return saveFingerprints(c, tx, questions)
+}
+
+// removeFromIndex 从全文索引与相似度指纹中删除题目
+func removeFromIndex(c context.Context, tx *gorm.DB, questionIDs []int) error {
+	if len(questionIDs) == 0 {
+		return nil
+	}
+	if err := tx.WithContext(c).Exec("DELETE FROM "+questionFTSTable+" WHERE rowid IN ?", questionIDs).Error; err != nil {
+		return err
+	}
+	return tx.WithContext(c).Where("question_id IN ?", questionIDs).Delete(&model.QuestionFingerprint{}).Error
+}
+
+// EnsureSearchIndex 启动时校验全文索引与相似度指纹，与题目表数量不一致(如旧库首次升级)时重建
+func (dao *QuestionDao) EnsureSearchIndex(c context.Context) error {
+	var questionCount, indexedCount, fingerprintCount int64
+	if err := dao.DB.WithContext(c).Model(&model.Question{}).Count(&questionCount).Error; err != nil {
+		return err
+	}
+	if err := dao.DB.WithContext(c).Raw("SELECT count(*) FROM " + questionFTSTable).Scan(&indexedCount).Error; err != nil {
+		return err
+	}
+	if err := dao.DB.WithContext(c).Model(&model.QuestionFingerprint{}).Count(&fingerprintCount).Error; err != nil {
+		return err
+	}
+	if questionCount == indexedCount && questionCount == fingerprintCount {
+		return nil
+	}
+	return dao.RebuildSearchIndex(c)
+}
+
+// RebuildSearchIndex 清空并分批重建全文索引与相似度指纹
+func (dao *QuestionDao) RebuildSearchIndex(c context.Context) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM " + questionFTSTable).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM question_fingerprints").Error; err != nil {
+			return err
+		}
+		var batch []model.Question
+		return tx.Model(&model.Question{}).FindInBatches(&batch, 200, func(batchTx *gorm.DB, _ int) error {
+			return indexQuestions(c, tx, batch)
+		}).Error
+	})
+}
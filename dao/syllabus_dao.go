@@ -0,0 +1,146 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils/enums"
+	"context"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// SyllabusDao 课程大纲
+type SyllabusDao struct {
+	DB *gorm.DB
+}
+
+func NewSyllabusDAO(db *gorm.DB) *SyllabusDao {
+	return &SyllabusDao{DB: db}
+}
+
+func orderTopics(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
+
+// CreateSyllabus 创建大纲及其主题
+func (dao *SyllabusDao) CreateSyllabus(c context.Context, syllabus *model.Syllabus) error {
+	return dao.DB.WithContext(c).Create(syllabus).Error
+}
+
+func (dao *SyllabusDao) GetSyllabus(c context.Context, syllabusID int) (*model.Syllabus, error) {
+	var syllabus model.Syllabus
+	err := dao.DB.WithContext(c).Preload("Topics", orderTopics).Where("id = ?", syllabusID).Take(&syllabus).Error
+	if err != nil {
+		return nil, err
+	}
+	return &syllabus, nil
+}
+
+// ListSyllabi 获取用户的全部大纲，按名称排序
+func (dao *SyllabusDao) ListSyllabi(c context.Context, userID int) ([]model.Syllabus, error) {
+	var syllabi []model.Syllabus
+	err := dao.DB.WithContext(c).Preload("Topics", orderTopics).Where("user_id = ?", userID).
+		Order("name, id").Find(&syllabi).Error
+	return syllabi, err
+}
+
+// UpdateSyllabus 修改大纲的名称、描述与语言，并以新的主题列表整体替换原有主题
+func (dao *SyllabusDao) UpdateSyllabus(c context.Context, syllabus *model.Syllabus) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Syllabus{}).Where("id = ?", syllabus.ID).
+			Updates(map[string]interface{}{
+				"name":        syllabus.Name,
+				"description": syllabus.Description,
+				"language":    syllabus.Language,
+			}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("syllabus_id = ?", syllabus.ID).Delete(&model.SyllabusTopic{}).Error; err != nil {
+			return err
+		}
+		for i := range syllabus.Topics {
+			syllabus.Topics[i].SyllabusID = syllabus.ID
+		}
+		if len(syllabus.Topics) == 0 {
+			return nil
+		}
+		return tx.Create(&syllabus.Topics).Error
+	})
+}
+
+// DeleteSyllabus 删除大纲及其主题
+func (dao *SyllabusDao) DeleteSyllabus(c context.Context, syllabusID int) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("syllabus_id = ?", syllabusID).Delete(&model.SyllabusTopic{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", syllabusID).Delete(&model.Syllabus{}).Error
+	})
+}
+
+// DeleteSyllabiByUserID 删除用户的全部大纲
+func (dao *SyllabusDao) DeleteSyllabiByUserID(c context.Context, tx *gorm.DB, userID int) error {
+	err := tx.WithContext(c).
+		Where("syllabus_id IN (SELECT id FROM syllabi WHERE user_id = ?)", userID).
+		Delete(&model.SyllabusTopic{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.WithContext(c).Where("user_id = ?", userID).Delete(&model.Syllabus{}).Error
+}
+
+// GetPaper 获取试卷及其题目与题目标签，已移入回收站的题目同样返回
+func (dao *SyllabusDao) GetPaper(c context.Context, paperID int) (*model.Paper, error) {
+	var paper model.Paper
+	err := dao.DB.WithContext(c).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("question_order") }).
+		Preload("Questions.Question", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Questions.Question.Tags").
+		Where("id = ?", paperID).Take(&paper).Error
+	if err != nil {
+		return nil, err
+	}
+	return &paper, nil
+}
+
+// TopicCandidateFilter 为主题查找候选题目的条件
+type TopicCandidateFilter struct {
+	VisibleTo  int // 只查询该用户自己的题目与共享题目，0 表示不限
+	Language   string
+	TagIDs     []int
+	Keywords   []string // 归一化后的关键词，题目关键词包含其中任一即可
+	ExcludeIDs []int
+	Limit      int
+}
+
+// ListTopicCandidates 查找带有任一标签或关键词匹配的题目，审核通过的题目优先，其次按创建时间倒序
+func (dao *SyllabusDao) ListTopicCandidates(c context.Context, filter TopicCandidateFilter) ([]model.Question, error) {
+	var questions []model.Question
+	if len(filter.TagIDs) == 0 && len(filter.Keywords) == 0 {
+		return questions, nil
+	}
+	var conds []string
+	var args []interface{}
+	if len(filter.TagIDs) > 0 {
+		conds = append(conds, "questions.id IN (SELECT question_id FROM question_tags WHERE tag_id IN ?)")
+		args = append(args, filter.TagIDs)
+	}
+	for _, keyword := range filter.Keywords {
+		conds = append(conds, "LOWER(questions.keywords) LIKE ?")
+		args = append(args, "%"+keyword+"%")
+	}
+	query := dao.DB.WithContext(c).Model(&model.Question{}).Preload("Tags").
+		Where("("+strings.Join(conds, " OR ")+")", args...)
+	if filter.VisibleTo != 0 {
+		query = query.Where("(questions.user_id = ? OR questions.visibility IN ?)", filter.VisibleTo, enums.SharedVisibilities)
+	}
+	if filter.Language != "" {
+		query = query.Where("questions.language = ?", filter.Language)
+	}
+	if len(filter.ExcludeIDs) > 0 {
+		query = query.Where("questions.id NOT IN ?", filter.ExcludeIDs)
+	}
+	err := query.Order(gorm.Expr("CASE WHEN questions.status = ? THEN 0 ELSE 1 END, questions.created_at DESC", string(enums.ReviewApproved))).
+		Limit(filter.Limit).Find(&questions).Error
+	return questions, err
+}
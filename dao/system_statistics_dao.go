@@ -3,6 +3,7 @@ package dao
 import (
 	"aiquiz/dao/model"
 	"aiquiz/models/dto"
+	"aiquiz/utils/enums"
 	"context"
 	"gorm.io/gorm"
 	"time"
@@ -54,6 +55,32 @@ func (dao *SystemStatisticsDao) GetLanguageDistribution(c context.Context) ([]dt
 	return distribution, err
 }
 
+// GetStatusDistribution 获取题目审核状态分布
+func (dao *SystemStatisticsDao) GetStatusDistribution(c context.Context) ([]dto.StatusDistribution, error) {
+	var distribution []dto.StatusDistribution
+	err := dao.DB.WithContext(c).
+		Model(&model.Question{}).
+		Select("status, count(*) as count").
+		Group("status").
+		Scan(&distribution).Error
+	return distribution, err
+}
+
+// GetTagDistribution 获取题目数量最多的 limit 个标签
+func (dao *SystemStatisticsDao) GetTagDistribution(c context.Context, limit int) ([]dto.TagDistribution, error) {
+	var distribution []dto.TagDistribution
+	err := dao.DB.WithContext(c).
+		Model(&model.QuestionTag{}).
+		Joins("JOIN questions ON questions.id = question_tags.question_id AND questions.deleted_at IS NULL").
+		Joins("JOIN tags ON tags.id = question_tags.tag_id AND tags.deleted_at IS NULL").
+		Select("tags.display_name as tag, count(*) as count").
+		Group("tags.id").
+		Order("count desc").
+		Limit(limit).
+		Scan(&distribution).Error
+	return distribution, err
+}
+
 // GetAIModelUsage 获取AI模型使用情况
 func (dao *SystemStatisticsDao) GetAIModelUsage(c context.Context) ([]dto.AIModelDistribution, error) {
 	var usage []dto.AIModelDistribution
@@ -66,6 +93,28 @@ func (dao *SystemStatisticsDao) GetAIModelUsage(c context.Context) ([]dto.AIMode
 	return usage, err
 }
 
+// GetExperimentResults 获取各实验分组的生成题目去向，discardBefore 之前生成且仍未确认的题目计为丢弃
+func (dao *SystemStatisticsDao) GetExperimentResults(c context.Context, discardBefore time.Time) ([]dto.ExperimentResult, error) {
+	var results []dto.ExperimentResult
+	err := dao.DB.WithContext(c).
+		Model(&model.GenerationRecord{}).
+		Select(`generation_records.experiment_id, experiments.name as experiment_name, generation_records.variant,
+			count(*) as generated,
+			sum(case when generation_records.outcome = ? then 1 else 0 end) as unedited,
+			sum(case when generation_records.outcome = ? then 1 else 0 end) as edited,
+			sum(case when generation_records.outcome = ? and generation_records.created_at < ? then 1 else 0 end) as discarded,
+			sum(case when generation_records.outcome = ? and generation_records.created_at >= ? then 1 else 0 end) as pending`,
+			enums.OutcomeUnedited, enums.OutcomeEdited,
+			enums.OutcomePending, discardBefore,
+			enums.OutcomePending, discardBefore).
+		Joins("LEFT JOIN experiments ON experiments.id = generation_records.experiment_id").
+		Where("generation_records.experiment_id != 0").
+		Group("generation_records.experiment_id, experiments.name, generation_records.variant").
+		Order("generation_records.experiment_id desc, generation_records.variant").
+		Scan(&results).Error
+	return results, err
+}
+
 // GetPaperQuestionDistribution 获取试卷题目数量分布
 func (dao *SystemStatisticsDao) GetPaperQuestionDistribution(c context.Context) ([]dto.PaperQuestionDistribution, error) {
 	// 先查询每个试卷的题目数量
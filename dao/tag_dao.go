@@ -0,0 +1,321 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+)
+
+type TagDao struct {
+	DB *gorm.DB
+}
+
+func NewTagDAO(db *gorm.DB) *TagDao {
+	return &TagDao{DB: db}
+}
+
+// ResolveTags 将标签名解析为标签：先按别名、再按归一化名称查找，不存在的标签会被创建。
+// 结果按输入顺序去重，tx 为 nil 时使用默认连接
+func (dao *TagDao) ResolveTags(c context.Context, tx *gorm.DB, names []string) ([]model.Tag, error) {
+	if tx == nil {
+		tx = dao.DB
+	}
+	tags := make([]model.Tag, 0, len(names))
+	seen := make(map[int]struct{}, len(names))
+	for _, name := range names {
+		tag, err := dao.findTag(c, tx, name)
+		if err != nil {
+			return nil, err
+		}
+		if tag == nil {
+			normalized := utils.NormalizeTag(name)
+			if normalized == "" {
+				continue
+			}
+			tag = &model.Tag{Name: normalized, DisplayName: name}
+			if err := tx.WithContext(c).Create(tag).Error; err != nil {
+				return nil, err
+			}
+		}
+		if _, ok := seen[tag.ID]; ok {
+			continue
+		}
+		seen[tag.ID] = struct{}{}
+		tags = append(tags, *tag)
+	}
+	return tags, nil
+}
+
+// FindTagIDs 查找已存在的标签ID(不创建)，返回与输入一一对应的ID，不存在的为0
+func (dao *TagDao) FindTagIDs(c context.Context, names []string) ([]int, error) {
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		tag, err := dao.findTag(c, dao.DB, name)
+		if err != nil {
+			return nil, err
+		}
+		if tag == nil {
+			ids = append(ids, 0)
+			continue
+		}
+		ids = append(ids, tag.ID)
+	}
+	return ids, nil
+}
+
+// findTag 按别名或归一化名称查找标签，不存在时返回 nil
+func (dao *TagDao) findTag(c context.Context, tx *gorm.DB, name string) (*model.Tag, error) {
+	normalized := utils.NormalizeTag(name)
+	if normalized == "" {
+		return nil, nil
+	}
+	// 别名优先于名称
+	var tag model.Tag
+	err := tx.WithContext(c).Where("id = (SELECT tag_id FROM tag_aliases WHERE alias = ?)", normalized).Take(&tag).Error
+	if err == nil {
+		return &tag, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	err = tx.WithContext(c).Where("name = ?", normalized).Take(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (dao *TagDao) ListTags(c context.Context, name string, page utils.Page) ([]dto.TagRes, int64, error) {
+	query := dao.DB.WithContext(c).Model(&model.Tag{})
+	if name != "" {
+		query = query.Where("tags.name LIKE ? OR tags.display_name LIKE ?", "%"+utils.NormalizeTag(name)+"%", "%"+name+"%")
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var tags []model.Tag
+	err := query.Order("tags.name").Scopes(utils.Paginate(page)).Preload("Aliases").Find(&tags).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 统计每个标签下未删除的题目数量
+	tagIDs := make([]int, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	var counts []struct {
+		TagID int
+		Count int
+	}
+	err = dao.DB.WithContext(c).Model(&model.QuestionTag{}).
+		Select("question_tags.tag_id, count(*) as count").
+		Joins("JOIN questions ON questions.id = question_tags.question_id AND questions.deleted_at IS NULL").
+		Where("question_tags.tag_id IN ?", tagIDs).
+		Group("question_tags.tag_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	countMap := make(map[int]int, len(counts))
+	for _, item := range counts {
+		countMap[item.TagID] = item.Count
+	}
+
+	list := make([]dto.TagRes, 0, len(tags))
+	for _, tag := range tags {
+		aliases := make([]dto.TagAliasRes, 0, len(tag.Aliases))
+		for _, alias := range tag.Aliases {
+			aliases = append(aliases, dto.TagAliasRes{ID: alias.ID, Alias: alias.Alias})
+		}
+		list = append(list, dto.TagRes{
+			ID:            tag.ID,
+			Name:          tag.Name,
+			DisplayName:   tag.DisplayName,
+			Aliases:       aliases,
+			QuestionCount: countMap[tag.ID],
+		})
+	}
+	return list, total, nil
+}
+
+func (dao *TagDao) GetTag(c context.Context, tagID int) (*model.Tag, error) {
+	var tag model.Tag
+	err := dao.DB.WithContext(c).Where("id = ?", tagID).Take(&tag).Error
+	return &tag, err
+}
+
+// CreateTag 创建标签，名称(或别名)已存在时返回错误
+func (dao *TagDao) CreateTag(c context.Context, displayName string) (*model.Tag, error) {
+	existing, err := dao.findTag(c, dao.DB, displayName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("标签已存在: " + existing.DisplayName)
+	}
+	tag := &model.Tag{Name: utils.NormalizeTag(displayName), DisplayName: displayName}
+	if tag.Name == "" {
+		return nil, errors.New("标签名称不能为空")
+	}
+	return tag, dao.DB.WithContext(c).Create(tag).Error
+}
+
+// RenameTag 修改标签名称。归一化名称变化时旧名称保留为别名，
+// 题目关键词中的旧名称仍能解析到该标签(批量移除标签、按标签匹配大纲主题等)
+func (dao *TagDao) RenameTag(c context.Context, tagID int, displayName string) error {
+	normalized := utils.NormalizeTag(displayName)
+	if normalized == "" {
+		return errors.New("标签名称不能为空")
+	}
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var tag model.Tag
+		if err := tx.Where("id = ?", tagID).Take(&tag).Error; err != nil {
+			return err
+		}
+		existing, err := dao.findTag(c, tx, displayName)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ID != tagID {
+			return errors.New("标签已存在: " + existing.DisplayName)
+		}
+		if tag.Name != normalized {
+			// 新名称原本是该标签的别名时移除该别名，避免别名与名称相同
+			if err := tx.Where("tag_id = ? AND alias = ?", tagID, normalized).Delete(&model.TagAlias{}).Error; err != nil {
+				return err
+			}
+			var count int64
+			if err := tx.Model(&model.TagAlias{}).Where("alias = ?", tag.Name).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				if err := tx.Create(&model.TagAlias{Alias: tag.Name, TagID: tagID}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Model(&model.Tag{}).Where("id = ?", tagID).
+			Updates(map[string]interface{}{"name": normalized, "display_name": displayName}).Error
+	})
+}
+
+// DeleteTag 删除标签及其别名与题目关联
+func (dao *TagDao) DeleteTag(c context.Context, tagID int) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", tagID).Delete(&model.QuestionTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", tagID).Delete(&model.TagAlias{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Tag{ID: tagID}).Error
+	})
+}
+
+// AddAlias 为标签添加别名。若别名恰好是另一个已存在的标签，则把该标签合并进来：
+// 题目关联与别名改挂到目标标签，原标签删除
+func (dao *TagDao) AddAlias(c context.Context, tagID int, alias string) error {
+	normalized := utils.NormalizeTag(alias)
+	if normalized == "" {
+		return errors.New("别名不能为空")
+	}
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var target model.Tag
+		if err := tx.Where("id = ?", tagID).Take(&target).Error; err != nil {
+			return err
+		}
+		if target.Name == normalized {
+			return errors.New("别名不能与标签名称相同")
+		}
+		existing, err := dao.findTag(c, tx, alias)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ID == tagID {
+			return errors.New("别名已存在")
+		}
+		if existing != nil {
+			if err := mergeTag(c, tx, existing.ID, tagID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&model.TagAlias{Alias: normalized, TagID: tagID}).Error
+	})
+}
+
+// mergeTag 将 fromID 标签合并到 toID 标签
+func mergeTag(c context.Context, tx *gorm.DB, fromID, toID int) error {
+	// 两个标签都挂在同一题目上时，先删除重复的关联再改挂
+	err := tx.WithContext(c).Exec(`DELETE FROM question_tags WHERE tag_id = ? AND question_id IN
+		(SELECT question_id FROM question_tags WHERE tag_id = ?)`, fromID, toID).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.WithContext(c).Model(&model.QuestionTag{}).Where("tag_id = ?", fromID).Update("tag_id", toID).Error; err != nil {
+		return err
+	}
+	if err := tx.WithContext(c).Model(&model.TagAlias{}).Where("tag_id = ?", fromID).Update("tag_id", toID).Error; err != nil {
+		return err
+	}
+	return tx.WithContext(c).Delete(&model.Tag{ID: fromID}).Error
+}
+
+func (dao *TagDao) DeleteAlias(c context.Context, tagID, aliasID int) (bool, error) {
+	result := dao.DB.WithContext(c).Where("id = ? AND tag_id = ?", aliasID, tagID).Delete(&model.TagAlias{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MergeDuplicateTags 合并归一化名称相同的未删除标签，保留ID最小的标签(迁移使用)
+func (dao *TagDao) MergeDuplicateTags(c context.Context, tx *gorm.DB) error {
+	var tags []model.Tag
+	err := tx.WithContext(c).Model(&model.Tag{}).
+		Where("name IN (SELECT name FROM tags WHERE deleted_at IS NULL GROUP BY name HAVING count(*) > 1)").
+		Order("name, id").Find(&tags).Error
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]int, len(tags))
+	for _, tag := range tags {
+		toID, ok := keep[tag.Name]
+		if !ok {
+			keep[tag.Name] = tag.ID
+			continue
+		}
+		if err := mergeTag(c, tx, tag.ID, toID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SplitKeywordsToTags 把历史题目的关键词拆分为标签(迁移使用，只处理还没有标签的题目)
+func (dao *TagDao) SplitKeywordsToTags(c context.Context, tx *gorm.DB) error {
+	var questions []model.Question
+	err := tx.WithContext(c).Model(&model.Question{}).
+		Select("id", "keywords").
+		Where("keywords != '' AND id NOT IN (SELECT question_id FROM question_tags)").
+		Find(&questions).Error
+	if err != nil {
+		return err
+	}
+	for _, q := range questions {
+		tags, err := dao.ResolveTags(c, tx, utils.SplitKeywords(q.Keywords))
+		if err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if err := tx.WithContext(c).Create(&model.QuestionTag{QuestionID: q.ID, TagID: tag.ID}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package dao
+
+import (
+	"aiquiz/dao/model"
+	"aiquiz/utils"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"sort"
+	"time"
+)
+
+// TrashDao 回收站：查询、恢复与彻底删除已软删除的题目、试卷及试卷题目关联
+type TrashDao struct {
+	DB *gorm.DB
+}
+
+func NewTrashDAO(db *gorm.DB) *TrashDao {
+	return &TrashDao{DB: db}
+}
+
+// ListDeletedQuestions 分页查询已删除的题目，userID 为 0 时查询全部
+func (dao *TrashDao) ListDeletedQuestions(c context.Context, userID int, page utils.Page) ([]model.Question, int64, error) {
+	query := dao.DB.WithContext(c).Unscoped().Model(&model.Question{}).Where("deleted_at IS NOT NULL")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var questions []model.Question
+	err := query.Order("deleted_at desc").Scopes(utils.Paginate(page)).
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id, username")
+		}).
+		Find(&questions).Error
+	return questions, total, err
+}
+
+// ListDeletedPapers 分页查询已删除的试卷，userID 为 0 时查询全部
+func (dao *TrashDao) ListDeletedPapers(c context.Context, userID int, page utils.Page) ([]model.Paper, int64, error) {
+	query := dao.DB.WithContext(c).Unscoped().Model(&model.Paper{}).Where("deleted_at IS NOT NULL")
+	if userID != 0 {
+		query = query.Where("creator_id = ?", userID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var papers []model.Paper
+	err := query.Order("deleted_at desc").Scopes(utils.Paginate(page)).
+		Preload("Creator", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id, username")
+		}).
+		Find(&papers).Error
+	return papers, total, err
+}
+
+// DeletedPaperQuestion 回收站中的试卷题目关联及试卷、题目标题
+type DeletedPaperQuestion struct {
+	ID            int
+	PaperID       int
+	PaperTitle    string
+	QuestionID    int
+	QuestionTitle string
+	QuestionOrder int
+	Score         int
+	DeletedAt     time.Time
+}
+
+// ListDeletedPaperQuestions 分页查询未删除试卷中被移除的题目关联(随试卷一起删除的关联随试卷恢复)，
+// userID 为 0 时查询全部，paperID 为 0 时不限试卷
+func (dao *TrashDao) ListDeletedPaperQuestions(c context.Context, userID, paperID int, page utils.Page) ([]DeletedPaperQuestion, int64, error) {
+	query := dao.DB.WithContext(c).Unscoped().Model(&model.PaperQuestion{}).
+		Joins("JOIN papers ON papers.id = paper_questions.paper_id AND papers.deleted_at IS NULL").
+		Joins("LEFT JOIN questions ON questions.id = paper_questions.question_id").
+		Where("paper_questions.deleted_at IS NOT NULL")
+	if userID != 0 {
+		query = query.Where("papers.creator_id = ?", userID)
+	}
+	if paperID != 0 {
+		query = query.Where("paper_questions.paper_id = ?", paperID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []DeletedPaperQuestion
+	err := query.Select("paper_questions.id, paper_questions.paper_id, papers.title as paper_title, " +
+		"paper_questions.question_id, questions.title as question_title, paper_questions.question_order, " +
+		"paper_questions.score, paper_questions.deleted_at").
+		Order("paper_questions.deleted_at desc").
+		Scopes(utils.Paginate(page)).
+		Scan(&rows).Error
+	return rows, total, err
+}
+
+// GetDeletedQuestion 获取回收站中的题目
+func (dao *TrashDao) GetDeletedQuestion(c context.Context, questionID int) (*model.Question, error) {
+	var question model.Question
+	err := dao.DB.WithContext(c).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", questionID).Take(&question).Error
+	return &question, err
+}
+
+// GetDeletedPaper 获取回收站中的试卷
+func (dao *TrashDao) GetDeletedPaper(c context.Context, paperID int) (*model.Paper, error) {
+	var paper model.Paper
+	err := dao.DB.WithContext(c).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", paperID).Take(&paper).Error
+	return &paper, err
+}
+
+// GetDeletedPaperQuestion 获取回收站中的试卷题目关联，同时返回所属试卷(试卷已删除时返回错误)
+func (dao *TrashDao) GetDeletedPaperQuestion(c context.Context, linkID int) (*model.PaperQuestion, *model.Paper, error) {
+	var link model.PaperQuestion
+	err := dao.DB.WithContext(c).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", linkID).Take(&link).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	var paper model.Paper
+	if err := dao.DB.WithContext(c).Where("id = ?", link.PaperID).Take(&paper).Error; err != nil {
+		return nil, nil, err
+	}
+	return &link, &paper, nil
+}
+
+// RestoreQuestion 恢复题目并重新写入全文索引
+func (dao *TrashDao) RestoreQuestion(c context.Context, questionID int) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&model.Question{}).Where("id = ?", questionID).Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		var question model.Question
+		if err := tx.Where("id = ?", questionID).Take(&question).Error; err != nil {
+			return err
+		}
+		return indexQuestions(c, tx, []model.Question{question})
+	})
+}
+
+// RestorePaper 恢复试卷，以及与试卷同时删除的题目关联
+func (dao *TrashDao) RestorePaper(c context.Context, paper *model.Paper) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&model.Paper{}).Where("id = ?", paper.ID).Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		var linkIDs []int
+		err = tx.Unscoped().Model(&model.PaperQuestion{}).
+			Where("paper_id = ? AND deleted_at = ?", paper.ID, paper.DeletedAt).
+			Pluck("id", &linkIDs).Error
+		if err != nil {
+			return err
+		}
+		return restoreLinks(c, tx, paper.ID, linkIDs)
+	})
+}
+
+// RestorePaperQuestion 将题目关联恢复到试卷中，题目已删除或试卷中已有该题目时返回错误
+func (dao *TrashDao) RestorePaperQuestion(c context.Context, link *model.PaperQuestion) error {
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Question{}).Where("id = ?", link.QuestionID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("题目已被删除，请先恢复题目")
+		}
+		err := tx.Model(&model.PaperQuestion{}).
+			Where("paper_id = ? AND question_id = ?", link.PaperID, link.QuestionID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("试卷中已存在该题目")
+		}
+		return restoreLinks(c, tx, link.PaperID, []int{link.ID})
+	})
+}
+
+// restoreLinks 恢复试卷中的题目关联并重排顺序：恢复的关联按原顺序号插回现有题目之间(顺序号相同时排在现有题目之后)，
+// 最终顺序号重新从1连续编号。为避免违反 idx_paper_order_active，先把涉及的关联改为互不相同的负数再逐个写入最终值
+func restoreLinks(c context.Context, tx *gorm.DB, paperID int, linkIDs []int) error {
+	if len(linkIDs) == 0 {
+		return nil
+	}
+	var links []model.PaperQuestion
+	err := tx.WithContext(c).Unscoped().
+		Where("paper_id = ? AND (deleted_at IS NULL OR id IN ?)", paperID, linkIDs).
+		Find(&links).Error
+	if err != nil {
+		return err
+	}
+	restoring := make(map[int]bool, len(linkIDs))
+	for _, id := range linkIDs {
+		restoring[id] = true
+	}
+	sort.SliceStable(links, func(i, j int) bool {
+		if links[i].QuestionOrder != links[j].QuestionOrder {
+			return links[i].QuestionOrder < links[j].QuestionOrder
+		}
+		return !restoring[links[i].ID] && restoring[links[j].ID]
+	})
+
+	for _, link := range links {
+		err := tx.WithContext(c).Unscoped().Model(&model.PaperQuestion{}).Where("id = ?", link.ID).
+			Updates(map[string]interface{}{"question_order": -link.ID, "deleted_at": nil}).Error
+		if err != nil {
+			return err
+		}
+	}
+	for i, link := range links {
+		err := tx.WithContext(c).Model(&model.PaperQuestion{}).Where("id = ?", link.ID).
+			Update("question_order", i+1).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeQuestions 彻底删除题目及其标签关联、历史版本、试卷与集合关联、评论、错误报告以及对附件的引用
+// (不再被引用的附件由附件清理任务删除)
+func (dao *TrashDao) PurgeQuestions(c context.Context, questionIDs []int) error {
+	if len(questionIDs) == 0 {
+		return nil
+	}
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id IN ?", questionIDs).Delete(&model.QuestionTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN ?", questionIDs).Delete(&model.QuestionRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("question_id IN ?", questionIDs).Delete(&model.PaperQuestion{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN ?", questionIDs).Delete(&model.CollectionQuestion{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("question_id IN ?", questionIDs).Delete(&model.QuestionComment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN ?", questionIDs).Delete(&model.QuestionReport{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN ?", questionIDs).Delete(&model.QuestionReview{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN ?", questionIDs).Delete(&model.QuestionAttachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN ?", questionIDs).Delete(&model.QuestionEmbedding{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN ?", questionIDs).Delete(&model.QuestionKnowledgePoint{}).Error; err != nil {
+			return err
+		}
+		if err := removeFromIndex(c, tx, questionIDs); err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", questionIDs).Delete(&model.Question{}).Error
+	})
+}
+
+// PurgePapers 彻底删除试卷及其全部题目关联
+func (dao *TrashDao) PurgePapers(c context.Context, paperIDs []int) error {
+	if len(paperIDs) == 0 {
+		return nil
+	}
+	return dao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("paper_id IN ?", paperIDs).Delete(&model.PaperQuestion{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", paperIDs).Delete(&model.Paper{}).Error
+	})
+}
+
+// PurgePaperQuestions 彻底删除试卷题目关联
+func (dao *TrashDao) PurgePaperQuestions(c context.Context, linkIDs []int) error {
+	if len(linkIDs) == 0 {
+		return nil
+	}
+	return dao.DB.WithContext(c).Unscoped().Where("id IN ?", linkIDs).Delete(&model.PaperQuestion{}).Error
+}
+
+// PurgeExpired 彻底删除 before 之前进入回收站的试卷、试卷题目关联与题目，返回各自删除的数量
+func (dao *TrashDao) PurgeExpired(c context.Context, before time.Time) (papers, links, questions int, err error) {
+	var paperIDs, linkIDs, questionIDs []int
+	db := dao.DB.WithContext(c).Unscoped().Session(&gorm.Session{})
+	if err = db.Model(&model.Paper{}).Where("deleted_at < ?", before).Pluck("id", &paperIDs).Error; err != nil {
+		return
+	}
+	if err = dao.PurgePapers(c, paperIDs); err != nil {
+		return
+	}
+	if err = db.Model(&model.PaperQuestion{}).Where("deleted_at < ?", before).Pluck("id", &linkIDs).Error; err != nil {
+		return
+	}
+	if err = dao.PurgePaperQuestions(c, linkIDs); err != nil {
+		return
+	}
+	if err = db.Model(&model.Question{}).Where("deleted_at < ?", before).Pluck("id", &questionIDs).Error; err != nil {
+		return
+	}
+	if err = dao.PurgeQuestions(c, questionIDs); err != nil {
+		return
+	}
+	return len(paperIDs), len(linkIDs), len(questionIDs), nil
+}
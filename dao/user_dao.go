@@ -81,6 +81,18 @@ func (dao *UserDao) UpdateUser(c context.Context, id int, username string, passw
 	}).Error
 }
 
+// UpdateUserRole 修改用户角色
+func (dao *UserDao) UpdateUserRole(c context.Context, id int, role string) error {
+	result := dao.DB.WithContext(c).Model(&model.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (dao *UserDao) GetUserByID(c context.Context, userID int) (*model.User, error) {
 	var user model.User
 	err := dao.DB.WithContext(c).Where("id = ?", userID).First(&user).Error
@@ -48,6 +48,18 @@ func (dao *UserStatisticsDao) GetQuestionTypeDistribution(c context.Context, use
 	return distribution, err
 }
 
+// GetStatusDistribution 统计题目审核状态分布
+func (dao *UserStatisticsDao) GetStatusDistribution(c context.Context, userID int) ([]dto.StatusDistribution, error) {
+	var distribution []dto.StatusDistribution
+	err := dao.DB.WithContext(c).
+		Model(&model.Question{}).
+		Where("user_id = ?", userID).
+		Select("status, count(*) as count").
+		Group("status").
+		Scan(&distribution).Error
+	return distribution, err
+}
+
 // GetLanguageTypeDistribution 统计语言类型分布
 func (dao *UserStatisticsDao) GetLanguageTypeDistribution(c context.Context, userID int) ([]dto.LanguageDistribution, error) {
 	var distribution []dto.LanguageDistribution
@@ -60,6 +72,22 @@ func (dao *UserStatisticsDao) GetLanguageTypeDistribution(c context.Context, use
 	return distribution, err
 }
 
+// GetTagDistribution 统计题目数量最多的 limit 个标签
+func (dao *UserStatisticsDao) GetTagDistribution(c context.Context, userID, limit int) ([]dto.TagDistribution, error) {
+	var distribution []dto.TagDistribution
+	err := dao.DB.WithContext(c).
+		Model(&model.QuestionTag{}).
+		Joins("JOIN questions ON questions.id = question_tags.question_id AND questions.deleted_at IS NULL").
+		Joins("JOIN tags ON tags.id = question_tags.tag_id AND tags.deleted_at IS NULL").
+		Where("questions.user_id = ?", userID).
+		Select("tags.display_name as tag, count(*) as count").
+		Group("tags.id").
+		Order("count desc").
+		Limit(limit).
+		Scan(&distribution).Error
+	return distribution, err
+}
+
 // GetActiveTimeData 获取用户活跃时间原始数据
 func (dao *UserStatisticsDao) GetActiveTimeData(c context.Context, userID int, startTime time.Time) ([]time.Time, error) {
 	// 查询指定时间范围内的题目创建时间
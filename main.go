@@ -1,35 +1,78 @@
 package main
 
 import (
+	"aiquiz/ai"
 	"aiquiz/config"
 	"aiquiz/controllers"
 	"aiquiz/dao"
 	"aiquiz/migrations"
 	"aiquiz/routes"
 	"aiquiz/services"
+	"aiquiz/utils/storage"
+	"context"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"log"
 )
 
 type AppDependencies struct {
-	DB          *gorm.DB
-	UserDAO     *dao.UserDao
-	QuestionDAO *dao.QuestionDao
-	PaperDAO    *dao.PaperDao
-	statsDAO    *dao.UserStatisticsDao
-	systemDAO   *dao.SystemStatisticsDao
+	DB                *gorm.DB
+	UserDAO           *dao.UserDao
+	QuestionDAO       *dao.QuestionDao
+	PaperDAO          *dao.PaperDao
+	statsDAO          *dao.UserStatisticsDao
+	systemDAO         *dao.SystemStatisticsDao
+	ExperimentDAO     *dao.ExperimentDao
+	TagDAO            *dao.TagDao
+	RevisionDAO       *dao.QuestionRevisionDao
+	TrashDAO          *dao.TrashDao
+	ReviewDAO         *dao.QuestionReviewDao
+	FeedbackDAO       *dao.FeedbackDao
+	CollectionDAO     *dao.CollectionDao
+	AttachmentDAO     *dao.AttachmentDao
+	CodeRunDAO        *dao.CodeRunDao
+	DuplicateDAO      *dao.DuplicateDao
+	EmbeddingDAO      *dao.EmbeddingDao
+	KnowledgePointDAO *dao.KnowledgePointDao
+	SyllabusDAO       *dao.SyllabusDao
 
-	UserService     *services.UserService
-	QuestionService *services.QuestionService
-	PaperService    *services.PaperService
-	statsService    *services.StatisticsService
+	UserService           *services.UserService
+	QuestionService       *services.QuestionService
+	PaperService          *services.PaperService
+	statsService          *services.StatisticsService
+	ExperimentService     *services.ExperimentService
+	TagService            *services.TagService
+	TrashService          *services.TrashService
+	ImportService         *services.ImportService
+	ExportService         *services.ExportService
+	ReviewService         *services.ReviewService
+	FeedbackService       *services.FeedbackService
+	CollectionService     *services.CollectionService
+	AttachmentService     *services.AttachmentService
+	CodeRunService        *services.CodeRunService
+	DuplicateService      *services.DuplicateService
+	SemanticService       *services.SemanticService
+	KnowledgePointService *services.KnowledgePointService
+	SyllabusService       *services.SyllabusService
 
-	AuthController      *controllers.AuthController
-	UserController      *controllers.UserController
-	QuestionController  *controllers.QuestionController
-	PaperController     *controllers.PaperController
-	StatisticController *controllers.StatisticController
+	AuthController           *controllers.AuthController
+	UserController           *controllers.UserController
+	QuestionController       *controllers.QuestionController
+	PaperController          *controllers.PaperController
+	StatisticController      *controllers.StatisticController
+	ExperimentController     *controllers.ExperimentController
+	TagController            *controllers.TagController
+	TrashController          *controllers.TrashController
+	ImportController         *controllers.ImportController
+	ExportController         *controllers.ExportController
+	ReviewController         *controllers.ReviewController
+	FeedbackController       *controllers.FeedbackController
+	CollectionController     *controllers.CollectionController
+	AttachmentController     *controllers.AttachmentController
+	DuplicateController      *controllers.DuplicateController
+	SemanticController       *controllers.SemanticController
+	KnowledgePointController *controllers.KnowledgePointController
+	SyllabusController       *controllers.SyllabusController
 }
 
 // GetAuthController 获取认证控制器
@@ -47,7 +90,7 @@ func (d *AppDependencies) GetUserController() *controllers.UserController {
 }
 func (d *AppDependencies) GetQuestionController() *controllers.QuestionController {
 	if d.QuestionController == nil {
-		d.QuestionController = controllers.NewQuestionController(d.QuestionService)
+		d.QuestionController = controllers.NewQuestionController(d.QuestionService, d.CodeRunService, d.DuplicateService)
 	}
 	return d.QuestionController
 }
@@ -63,6 +106,85 @@ func (d *AppDependencies) GetStatisticController() *controllers.StatisticControl
 	}
 	return d.StatisticController
 }
+func (d *AppDependencies) GetExperimentController() *controllers.ExperimentController {
+	if d.ExperimentController == nil {
+		d.ExperimentController = controllers.NewExperimentController(d.ExperimentService)
+	}
+	return d.ExperimentController
+}
+func (d *AppDependencies) GetTagController() *controllers.TagController {
+	if d.TagController == nil {
+		d.TagController = controllers.NewTagController(d.TagService)
+	}
+	return d.TagController
+}
+func (d *AppDependencies) GetTrashController() *controllers.TrashController {
+	if d.TrashController == nil {
+		d.TrashController = controllers.NewTrashController(d.TrashService)
+	}
+	return d.TrashController
+}
+func (d *AppDependencies) GetImportController() *controllers.ImportController {
+	if d.ImportController == nil {
+		d.ImportController = controllers.NewImportController(d.ImportService)
+	}
+	return d.ImportController
+}
+func (d *AppDependencies) GetExportController() *controllers.ExportController {
+	if d.ExportController == nil {
+		d.ExportController = controllers.NewExportController(d.ExportService)
+	}
+	return d.ExportController
+}
+func (d *AppDependencies) GetReviewController() *controllers.ReviewController {
+	if d.ReviewController == nil {
+		d.ReviewController = controllers.NewReviewController(d.ReviewService, d.QuestionService)
+	}
+	return d.ReviewController
+}
+func (d *AppDependencies) GetFeedbackController() *controllers.FeedbackController {
+	if d.FeedbackController == nil {
+		d.FeedbackController = controllers.NewFeedbackController(d.FeedbackService, d.QuestionService)
+	}
+	return d.FeedbackController
+}
+func (d *AppDependencies) GetCollectionController() *controllers.CollectionController {
+	if d.CollectionController == nil {
+		d.CollectionController = controllers.NewCollectionController(d.CollectionService)
+	}
+	return d.CollectionController
+}
+func (d *AppDependencies) GetAttachmentController() *controllers.AttachmentController {
+	if d.AttachmentController == nil {
+		d.AttachmentController = controllers.NewAttachmentController(d.AttachmentService, d.QuestionService)
+	}
+	return d.AttachmentController
+}
+
+func (d *AppDependencies) GetDuplicateController() *controllers.DuplicateController {
+	if d.DuplicateController == nil {
+		d.DuplicateController = controllers.NewDuplicateController(d.DuplicateService)
+	}
+	return d.DuplicateController
+}
+func (d *AppDependencies) GetSemanticController() *controllers.SemanticController {
+	if d.SemanticController == nil {
+		d.SemanticController = controllers.NewSemanticController(d.SemanticService, d.QuestionService)
+	}
+	return d.SemanticController
+}
+func (d *AppDependencies) GetKnowledgePointController() *controllers.KnowledgePointController {
+	if d.KnowledgePointController == nil {
+		d.KnowledgePointController = controllers.NewKnowledgePointController(d.KnowledgePointService, d.QuestionService)
+	}
+	return d.KnowledgePointController
+}
+func (d *AppDependencies) GetSyllabusController() *controllers.SyllabusController {
+	if d.SyllabusController == nil {
+		d.SyllabusController = controllers.NewSyllabusController(d.SyllabusService)
+	}
+	return d.SyllabusController
+}
 
 func (d *AppDependencies) GetDB() *gorm.DB {
 	return d.DB
@@ -83,6 +205,18 @@ func main() {
 	// 初始化依赖
 	deps := initDependencies(db)
 
+	// 校验题目全文索引与相似度指纹，旧库首次升级时会自动重建
+	if err := deps.QuestionDAO.EnsureSearchIndex(context.Background()); err != nil {
+		log.Fatalf("初始化全文索引失败: %v", err)
+	}
+
+	// 定期彻底删除回收站中过期的条目
+	go deps.TrashService.RunRetentionJob(context.Background())
+	// 定期清理未被题目引用的附件
+	go deps.AttachmentService.RunGCJob(context.Background())
+	// 补算题目向量
+	go deps.SemanticService.RunBackfillJob(context.Background())
+
 	// 设置路由
 	router := routes.InitRouter(deps)
 
@@ -108,22 +242,76 @@ func initDependencies(db *gorm.DB) *AppDependencies {
 	paperDao := dao.NewPaperDAO(db)
 	statsDao := dao.NewUserStatisticsDao(db)
 	systemStatisticsDao := dao.NewSystemStatisticsDao(db)
+	experimentDao := dao.NewExperimentDAO(db)
+	tagDao := dao.NewTagDAO(db)
+	revisionDao := dao.NewQuestionRevisionDAO(db)
+	trashDao := dao.NewTrashDAO(db)
+	reviewDao := dao.NewQuestionReviewDAO(db)
+	feedbackDao := dao.NewFeedbackDAO(db)
+	collectionDao := dao.NewCollectionDAO(db)
+	attachmentDao := dao.NewAttachmentDAO(db)
+	codeRunDao := dao.NewCodeRunDAO(db)
+	duplicateDao := dao.NewDuplicateDAO(db)
+	embeddingDao := dao.NewEmbeddingDAO(db)
+	knowledgePointDao := dao.NewKnowledgePointDAO(db)
+	syllabusDao := dao.NewSyllabusDAO(db)
 
 	// 初始化服务
-	userService := services.NewUserService(userDAO, questionDao, paperDao)
-	questionService := services.NewQuestionService(questionDao)
-	paperService := services.NewPaperService(paperDao, questionDao)
+	userService := services.NewUserService(userDAO, questionDao, paperDao, collectionDao, syllabusDao)
+	codeRunService := services.NewCodeRunService(codeRunDao)
+	duplicateService := services.NewDuplicateService(duplicateDao, questionDao)
+	knowledgePointService := services.NewKnowledgePointService(knowledgePointDao)
+	questionService := services.NewQuestionService(questionDao, experimentDao, tagDao, revisionDao, collectionDao, attachmentDao, feedbackDao, codeRunService, duplicateService, knowledgePointService)
+	paperService := services.NewPaperService(paperDao, questionDao, revisionDao, collectionDao)
 	statsService := services.NewStatisticService(userDAO, statsDao, systemStatisticsDao)
+	experimentService := services.NewExperimentService(experimentDao)
+	tagService := services.NewTagService(tagDao)
+	trashService := services.NewTrashService(trashDao, userDAO)
+	importService := services.NewImportService(questionService)
+	exportService := services.NewExportService(questionService, questionDao, paperDao)
+	reviewService := services.NewReviewService(questionService, questionDao, reviewDao)
+	feedbackService := services.NewFeedbackService(feedbackDao)
+	collectionService := services.NewCollectionService(collectionDao, questionDao)
+	semanticService := services.NewSemanticService(embeddingDao, questionDao, ai.NewOpenAIEmbedder())
+	syllabusService := services.NewSyllabusService(syllabusDao, tagDao)
+	attachmentService := services.NewAttachmentService(attachmentDao, storage.NewLocalStorage(config.GetConfig(false).AttachmentDir))
 
 	return &AppDependencies{
-		DB:              db,
-		UserDAO:         userDAO,
-		QuestionDAO:     questionDao,
-		PaperDAO:        paperDao,
-		statsDAO:        statsDao,
-		UserService:     userService,
-		QuestionService: questionService,
-		PaperService:    paperService,
-		statsService:    statsService,
+		DB:                    db,
+		UserDAO:               userDAO,
+		QuestionDAO:           questionDao,
+		PaperDAO:              paperDao,
+		statsDAO:              statsDao,
+		UserService:           userService,
+		QuestionService:       questionService,
+		PaperService:          paperService,
+		statsService:          statsService,
+		ExperimentDAO:         experimentDao,
+		ExperimentService:     experimentService,
+		TagDAO:                tagDao,
+		RevisionDAO:           revisionDao,
+		TrashDAO:              trashDao,
+		TrashService:          trashService,
+		TagService:            tagService,
+		ImportService:         importService,
+		ExportService:         exportService,
+		ReviewDAO:             reviewDao,
+		ReviewService:         reviewService,
+		FeedbackDAO:           feedbackDao,
+		FeedbackService:       feedbackService,
+		CollectionDAO:         collectionDao,
+		CollectionService:     collectionService,
+		AttachmentDAO:         attachmentDao,
+		AttachmentService:     attachmentService,
+		CodeRunDAO:            codeRunDao,
+		CodeRunService:        codeRunService,
+		DuplicateDAO:          duplicateDao,
+		DuplicateService:      duplicateService,
+		EmbeddingDAO:          embeddingDao,
+		SemanticService:       semanticService,
+		KnowledgePointDAO:     knowledgePointDao,
+		KnowledgePointService: knowledgePointService,
+		SyllabusDAO:           syllabusDao,
+		SyllabusService:       syllabusService,
 	}
 }
@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"aiquiz/utils"
+	"aiquiz/utils/enums"
 	"github.com/gin-gonic/gin"
 )
 
@@ -43,6 +44,21 @@ func AdminMiddleware() gin.HandlerFunc {
 		}
 		if role != "admin" {
 			utils.FailMsg(c, utils.ERROR_NOT_PERMISSION, "无管理员权限")
+			c.Abort()
+			return
+		}
+	}
+}
+
+// ReviewerMiddleware 审核员权限中间件，审核员与管理员可以访问
+func ReviewerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		if role != string(enums.RoleReviewer) && role != string(enums.RoleAdmin) {
+			utils.FailMsg(c, utils.ERROR_NOT_PERMISSION, "无审核权限")
+			c.Abort()
+			return
 		}
+		c.Next()
 	}
 }
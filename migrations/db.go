@@ -46,5 +46,10 @@ func InitDB(dbPath string) *gorm.DB {
 		panic(fmt.Errorf("执行 init.sql 失败: %v", err))
 	}
 
+	// 执行程序化的数据迁移
+	if err := runMigrations(db); err != nil {
+		panic(err)
+	}
+
 	return db
 }
@@ -1,3 +1,172 @@
+-- ----------------------------
+-- Table structure for syllabi
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "syllabi" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "name" text NOT NULL,
+    "description" text,
+    "language" text NOT NULL DEFAULT '',
+    "user_id" integer NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime
+);
+
+CREATE INDEX IF NOT EXISTS "idx_syllabi_user_id"
+    ON "syllabi" ("user_id" ASC);
+
+CREATE TABLE IF NOT EXISTS "syllabus_topics" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "syllabus_id" integer NOT NULL,
+    "name" text NOT NULL,
+    "tags" text,
+    "keywords" text,
+    "weight" integer NOT NULL DEFAULT 1,
+    "position" integer NOT NULL,
+    CONSTRAINT "fk_syllabus_topics_syllabus" FOREIGN KEY ("syllabus_id") REFERENCES "syllabi" ("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_syllabus_topics_syllabus_id"
+    ON "syllabus_topics" ("syllabus_id" ASC);
+
+-- ----------------------------
+-- Table structure for knowledge_points
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "knowledge_points" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "language" text NOT NULL,
+    "name" text NOT NULL,
+    "description" text,
+    "parent_id" integer NOT NULL DEFAULT 0,
+    "created_at" datetime,
+    "updated_at" datetime
+);
+
+CREATE INDEX IF NOT EXISTS "idx_knowledge_points_language"
+    ON "knowledge_points" ("language" ASC);
+
+CREATE INDEX IF NOT EXISTS "idx_knowledge_points_parent_id"
+    ON "knowledge_points" ("parent_id" ASC);
+
+CREATE TABLE IF NOT EXISTS "question_knowledge_points" (
+    "question_id" integer NOT NULL,
+    "knowledge_point_id" integer NOT NULL,
+    "source" text NOT NULL DEFAULT 'manual',
+    "created_at" datetime,
+    PRIMARY KEY ("question_id", "knowledge_point_id"),
+    CONSTRAINT "fk_question_knowledge_points_question" FOREIGN KEY ("question_id") REFERENCES "questions" ("id") ON DELETE CASCADE ON UPDATE NO ACTION,
+    CONSTRAINT "fk_question_knowledge_points_knowledge_point" FOREIGN KEY ("knowledge_point_id") REFERENCES "knowledge_points" ("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_question_knowledge_points_knowledge_point_id"
+    ON "question_knowledge_points" ("knowledge_point_id" ASC);
+
+-- ----------------------------
+-- Table structure for question_embeddings
+-- ----------------------------
+-- 内容由后台任务写入：题目的语义向量，题目内容变化后删除并重新计算
+CREATE TABLE IF NOT EXISTS "question_embeddings" (
+    "question_id" integer PRIMARY KEY NOT NULL,
+    "model" text NOT NULL,
+    "content_hash" text NOT NULL,
+    "dimensions" integer NOT NULL,
+    "vector" blob NOT NULL,
+    "updated_at" datetime
+);
+
+-- ----------------------------
+-- Table structure for question_fingerprints
+-- ----------------------------
+-- 内容由程序写入：题干与选项的 SimHash 指纹，与全文索引同步维护
+CREATE TABLE IF NOT EXISTS "question_fingerprints" (
+    "question_id" integer PRIMARY KEY NOT NULL,
+    "language" text NOT NULL,
+    "sim_hash" integer NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS "idx_question_fingerprints_language"
+    ON "question_fingerprints" ("language" ASC);
+
+-- ----------------------------
+-- Table structure for code_runs
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "code_runs" (
+    "hash" text PRIMARY KEY NOT NULL,
+    "compile_error" text NOT NULL DEFAULT '',
+    "stdout" text NOT NULL DEFAULT '',
+    "stderr" text NOT NULL DEFAULT '',
+    "exit_code" integer NOT NULL DEFAULT 0,
+    "timed_out" numeric NOT NULL DEFAULT false,
+    "created_at" datetime
+);
+
+-- ----------------------------
+-- Table structure for attachments
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "attachments" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "hash" text NOT NULL,
+    "filename" text NOT NULL,
+    "mime_type" text NOT NULL,
+    "size" integer NOT NULL,
+    "user_id" integer NOT NULL,
+    "created_at" datetime
+);
+
+CREATE INDEX IF NOT EXISTS "idx_attachments_hash"
+    ON "attachments" ("hash" ASC);
+
+CREATE INDEX IF NOT EXISTS "idx_attachments_user_id"
+    ON "attachments" ("user_id" ASC);
+
+CREATE TABLE IF NOT EXISTS "question_attachments" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "question_id" integer NOT NULL,
+    "attachment_id" integer NOT NULL,
+    "option_value" integer NOT NULL DEFAULT 0,
+    "position" integer NOT NULL,
+    "created_at" datetime,
+    CONSTRAINT "fk_question_attachments_question" FOREIGN KEY ("question_id") REFERENCES "questions" ("id") ON DELETE CASCADE ON UPDATE NO ACTION,
+    CONSTRAINT "fk_question_attachments_attachment" FOREIGN KEY ("attachment_id") REFERENCES "attachments" ("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_question_attachments_question_id"
+    ON "question_attachments" ("question_id" ASC);
+
+CREATE INDEX IF NOT EXISTS "idx_question_attachments_attachment_id"
+    ON "question_attachments" ("attachment_id" ASC);
+
+-- ----------------------------
+-- Table structure for collections
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "collections" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "name" text NOT NULL,
+    "description" text,
+    "parent_id" integer NOT NULL DEFAULT 0,
+    "user_id" integer NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime
+);
+
+CREATE INDEX IF NOT EXISTS "idx_collections_user_id"
+    ON "collections" ("user_id" ASC);
+
+CREATE INDEX IF NOT EXISTS "idx_collections_parent_id"
+    ON "collections" ("parent_id" ASC);
+
+CREATE TABLE IF NOT EXISTS "collection_questions" (
+    "collection_id" integer NOT NULL,
+    "question_id" integer NOT NULL,
+    "position" integer NOT NULL,
+    "created_at" datetime,
+    PRIMARY KEY ("collection_id", "question_id"),
+    CONSTRAINT "fk_collection_questions_collection" FOREIGN KEY ("collection_id") REFERENCES "collections" ("id") ON DELETE CASCADE ON UPDATE NO ACTION,
+    CONSTRAINT "fk_collection_questions_question" FOREIGN KEY ("question_id") REFERENCES "questions" ("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_collection_questions_question_id"
+    ON "collection_questions" ("question_id" ASC);
+
 -- ----------------------------
 -- Table structure for paper_questions
 -- ----------------------------
@@ -7,6 +176,7 @@ CREATE TABLE IF NOT EXISTS "paper_questions" (
     "question_id" integer NOT NULL,
     "question_order" integer NOT NULL,
     "score" integer DEFAULT 5,
+    "revision_id" integer NOT NULL DEFAULT 0,
     "created_at" datetime,
     "updated_at" datetime,
     "deleted_at" datetime,
@@ -55,6 +225,11 @@ CREATE TABLE IF NOT EXISTS "questions" (
     "keywords" text,
     "language" text NOT NULL,
     "ai_model" text NOT NULL,
+    "difficulty" text NOT NULL DEFAULT '',
+    "visibility" text NOT NULL DEFAULT 'private',
+    "forked_from_id" integer NOT NULL DEFAULT 0,
+    "forked_from_user_id" integer NOT NULL DEFAULT 0,
+    "status" text NOT NULL DEFAULT 'draft',
     "user_id" integer NOT NULL,
     "created_at" datetime,
     "updated_at" datetime,
@@ -62,6 +237,151 @@ CREATE TABLE IF NOT EXISTS "questions" (
     CONSTRAINT "fk_questions_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE NO ACTION ON UPDATE NO ACTION
 );
 
+-- ----------------------------
+-- Full-text index for questions
+-- ----------------------------
+-- 内容由程序写入：中文预先切分为二元组，rowid 与 questions.id 一致
+CREATE VIRTUAL TABLE IF NOT EXISTS "questions_fts" USING fts5(
+    "title",
+    "options",
+    "explanation",
+    "keywords",
+    tokenize = 'unicode61'
+);
+
+-- ----------------------------
+-- Table structure for tags
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "tags" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "name" text NOT NULL,
+    "display_name" text NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime
+);
+
+-- 归一化后的标签名唯一（仅未删除记录生效）
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tags_name"
+    ON "tags" ("name" ASC)
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS "tag_aliases" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "alias" text NOT NULL,
+    "tag_id" integer NOT NULL,
+    "created_at" datetime,
+    CONSTRAINT "fk_tag_aliases_tag" FOREIGN KEY ("tag_id") REFERENCES "tags" ("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tag_aliases_alias"
+    ON "tag_aliases" ("alias" ASC);
+
+CREATE TABLE IF NOT EXISTS "question_tags" (
+    "question_id" integer NOT NULL,
+    "tag_id" integer NOT NULL,
+    PRIMARY KEY ("question_id", "tag_id"),
+    CONSTRAINT "fk_question_tags_question" FOREIGN KEY ("question_id") REFERENCES "questions" ("id") ON DELETE CASCADE ON UPDATE NO ACTION,
+    CONSTRAINT "fk_question_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags" ("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_question_tags_tag_id"
+    ON "question_tags" ("tag_id" ASC);
+
+-- ----------------------------
+-- Table structure for question_revisions
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "question_revisions" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "question_id" integer NOT NULL,
+    "revision" integer NOT NULL,
+    "action" text NOT NULL,
+    "restored_from" integer NOT NULL DEFAULT 0,
+    "title" text NOT NULL,
+    "question_type" text NOT NULL,
+    "options" text NOT NULL,
+    "answer" text NOT NULL,
+    "explanation" text,
+    "keywords" text,
+    "tags" text,
+    "language" text NOT NULL,
+    "editor_id" integer NOT NULL,
+    "created_at" datetime,
+    CONSTRAINT "fk_question_revisions_question" FOREIGN KEY ("question_id") REFERENCES "questions" ("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_question_revisions_version"
+    ON "question_revisions" ("question_id" ASC, "revision" ASC);
+
+-- ----------------------------
+-- Table structure for question_reviews
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "question_reviews" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "question_id" integer NOT NULL,
+    "reviewer_id" integer NOT NULL,
+    "action" text NOT NULL,
+    "from_status" text NOT NULL,
+    "to_status" text NOT NULL,
+    "comment" text,
+    "created_at" datetime,
+    CONSTRAINT "fk_question_reviews_question" FOREIGN KEY ("question_id") REFERENCES "questions" ("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_question_reviews_question_id"
+    ON "question_reviews" ("question_id" ASC);
+
+-- ----------------------------
+-- Table structure for question_comments
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "question_comments" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "question_id" integer NOT NULL,
+    "parent_id" integer NOT NULL DEFAULT 0,
+    "user_id" integer NOT NULL,
+    "content" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'open',
+    "resolved_by" integer NOT NULL DEFAULT 0,
+    "resolved_at" datetime,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    CONSTRAINT "fk_question_comments_question" FOREIGN KEY ("question_id") REFERENCES "questions" ("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_question_comments_question_id"
+    ON "question_comments" ("question_id" ASC);
+
+-- ----------------------------
+-- Table structure for question_reports
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "question_reports" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "question_id" integer NOT NULL,
+    "reporter_id" integer NOT NULL,
+    "category" text NOT NULL,
+    "description" text,
+    "status" text NOT NULL DEFAULT 'open',
+    "resolution" text,
+    "resolved_by" integer NOT NULL DEFAULT 0,
+    "resolved_at" datetime,
+    "created_at" datetime,
+    "updated_at" datetime,
+    CONSTRAINT "fk_question_reports_question" FOREIGN KEY ("question_id") REFERENCES "questions" ("id") ON DELETE CASCADE ON UPDATE NO ACTION
+);
+
+CREATE INDEX IF NOT EXISTS "idx_question_reports_question_status"
+    ON "question_reports" ("question_id" ASC, "status" ASC);
+
+-- ----------------------------
+-- Table structure for schema_migrations
+-- ----------------------------
+-- 记录已执行的程序化迁移（见 migrations/migrate.go）
+CREATE TABLE IF NOT EXISTS "schema_migrations" (
+    "version" text PRIMARY KEY,
+    "applied_at" datetime
+);
+
 -- ----------------------------
 -- Table structure for users
 -- ----------------------------
@@ -76,6 +396,45 @@ CREATE TABLE IF NOT EXISTS "users" (
     CONSTRAINT "uni_users_username" UNIQUE ("username" ASC)
 );
 
+-- ----------------------------
+-- Table structure for experiments
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "experiments" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "name" text NOT NULL,
+    "description" text,
+    "variant_prompt" text,
+    "variant_model" text,
+    "traffic_percent" integer NOT NULL DEFAULT 50,
+    "active" numeric NOT NULL DEFAULT false,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime
+);
+
+-- ----------------------------
+-- Table structure for generation_records
+-- ----------------------------
+CREATE TABLE IF NOT EXISTS "generation_records" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" integer NOT NULL,
+    "experiment_id" integer NOT NULL DEFAULT 0,
+    "variant" text NOT NULL,
+    "prompt_template" text NOT NULL,
+    "ai_model" text NOT NULL,
+    "content_hash" text NOT NULL,
+    "outcome" text NOT NULL DEFAULT 'pending',
+    "question_id" integer NOT NULL DEFAULT 0,
+    "created_at" datetime,
+    "updated_at" datetime
+);
+
+CREATE INDEX IF NOT EXISTS "idx_generation_records_experiment"
+    ON "generation_records" ("experiment_id" ASC, "variant" ASC);
+
+CREATE INDEX IF NOT EXISTS "idx_generation_records_user_id"
+    ON "generation_records" ("user_id" ASC);
+
 -- ----------------------------
 -- Indexes
 -- ----------------------------
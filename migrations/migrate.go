@@ -0,0 +1,128 @@
+package migrations
+
+import (
+	"aiquiz/dao"
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"strings"
+	"time"
+)
+
+// migration 无法用 init.sql 表达的数据迁移，按 version 顺序执行且只执行一次
+type migration struct {
+	version string
+	name    string
+	up      func(tx *gorm.DB) error
+}
+
+var migrationList = []migration{
+	{
+		version: "20260601_split_keywords_to_tags",
+		name:    "将题目关键词拆分为标签",
+		up: func(tx *gorm.DB) error {
+			return dao.NewTagDAO(tx).SplitKeywordsToTags(context.Background(), tx)
+		},
+	},
+	{
+		version: "20260615_paper_questions_revision_id",
+		name:    "试卷题目增加固定版本字段",
+		up: func(tx *gorm.DB) error {
+			return addColumn(tx, "paper_questions", "revision_id", "integer NOT NULL DEFAULT 0")
+		},
+	},
+	{
+		version: "20260615_backfill_question_revisions",
+		name:    "为已有题目生成初始版本",
+		up: func(tx *gorm.DB) error {
+			return dao.NewQuestionRevisionDAO(tx).BackfillRevisions(context.Background(), tx)
+		},
+	},
+	{
+		version: "20260701_questions_difficulty",
+		name:    "题目增加难度字段",
+		up: func(tx *gorm.DB) error {
+			return addColumn(tx, "questions", "difficulty", "text NOT NULL DEFAULT ''")
+		},
+	},
+	{
+		version: "20260710_questions_visibility",
+		name:    "题目增加可见范围与复制来源字段",
+		up: func(tx *gorm.DB) error {
+			if err := addColumn(tx, "questions", "visibility", "text NOT NULL DEFAULT 'private'"); err != nil {
+				return err
+			}
+			if err := addColumn(tx, "questions", "forked_from_id", "integer NOT NULL DEFAULT 0"); err != nil {
+				return err
+			}
+			return addColumn(tx, "questions", "forked_from_user_id", "integer NOT NULL DEFAULT 0")
+		},
+	},
+	{
+		version: "20260720_questions_status",
+		name:    "题目增加审核状态字段",
+		up: func(tx *gorm.DB) error {
+			return addColumn(tx, "questions", "status", "text NOT NULL DEFAULT 'draft'")
+		},
+	},
+	{
+		version: "20261019_tags_name_unique",
+		name:    "标签名称索引改为唯一索引",
+		up: func(tx *gorm.DB) error {
+			// 由 AutoMigrate 建表的旧库中该索引不是唯一索引，init.sql 因索引已存在而跳过
+			var definition string
+			err := tx.Raw("SELECT sql FROM sqlite_master WHERE type = 'index' AND name = 'idx_tags_name'").Scan(&definition).Error
+			if err != nil {
+				return err
+			}
+			if strings.Contains(strings.ToUpper(definition), "UNIQUE") {
+				return nil
+			}
+			if err := dao.NewTagDAO(tx).MergeDuplicateTags(context.Background(), tx); err != nil {
+				return err
+			}
+			if err := tx.Exec(`DROP INDEX IF EXISTS "idx_tags_name"`).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`CREATE UNIQUE INDEX "idx_tags_name" ON "tags" ("name" ASC) WHERE deleted_at IS NULL`).Error
+		},
+	},
+}
+
+// addColumn 为旧库补充 init.sql 中新增的列，新库建表时已包含该列则跳过
+func addColumn(tx *gorm.DB, table, column, definition string) error {
+	if tx.Migrator().HasColumn(table, column) {
+		return nil
+	}
+	return tx.Exec(fmt.Sprintf(`ALTER TABLE "%s" ADD COLUMN "%s" %s`, table, column, definition)).Error
+}
+
+// runMigrations 依次执行尚未执行的迁移，每个迁移在独立事务中完成并写入 schema_migrations
+func runMigrations(db *gorm.DB) error {
+	var applied []string
+	if err := db.Table("schema_migrations").Pluck("version", &applied).Error; err != nil {
+		return fmt.Errorf("查询迁移记录失败: %v", err)
+	}
+	done := make(map[string]struct{}, len(applied))
+	for _, version := range applied {
+		done[version] = struct{}{}
+	}
+
+	for _, m := range migrationList {
+		if _, ok := done[m.version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			return tx.Exec("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)", m.version, time.Now()).Error
+		})
+		if err != nil {
+			return fmt.Errorf("执行迁移 %s 失败: %v", m.version, err)
+		}
+		log.Printf("已执行迁移 %s: %s", m.version, m.name)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package dto
+
+// AttachmentRes 附件信息返回结构体
+type AttachmentRes struct {
+	ID        int    `json:"id"`
+	Filename  string `json:"filename"`
+	MimeType  string `json:"mime_type"`
+	Size      int64  `json:"size"`
+	URL       string `json:"url"` // 下载地址
+	CreatedAt string `json:"created_at"`
+}
+
+// QuestionAttachmentItem 题目引用的一个附件，OptionValue 为 0 表示附在题干上
+type QuestionAttachmentItem struct {
+	AttachmentID int `json:"attachment_id" validate:"required"`
+	OptionValue  int `json:"option_value"`
+}
+
+// SetQuestionAttachmentsReq 整体设置题目引用的附件，同一位置的附件按传入顺序排列
+type SetQuestionAttachmentsReq struct {
+	Attachments []QuestionAttachmentItem `json:"attachments"`
+}
+
+// QuestionAttachmentRes 题目引用的附件
+type QuestionAttachmentRes struct {
+	OptionValue int `json:"option_value"`
+	AttachmentRes
+}
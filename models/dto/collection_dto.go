@@ -0,0 +1,36 @@
+package dto
+
+// CollectionReq 创建或修改题目集合请求，ParentID 为 0 表示顶层集合
+type CollectionReq struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+	ParentID    int    `json:"parent_id"`
+}
+
+// CollectionQuestionsReq 向集合添加题目或调整集合中题目顺序的请求
+type CollectionQuestionsReq struct {
+	QuestionIDs []int `json:"question_ids" validate:"required"`
+}
+
+// CollectionQuestionsRes 向集合添加题目的结果
+type CollectionQuestionsRes struct {
+	Added int `json:"added"` // 新加入的题目数量，已在集合中的题目不重复加入
+}
+
+// AddCollectionToPaperReq 将集合中的题目按顺序加入试卷
+type AddCollectionToPaperReq struct {
+	CollectionID int `json:"collection_id" validate:"required"`
+	Score        int `json:"score"` // 每道题目的分值
+}
+
+// CollectionRes 题目集合返回结构体，列表中以树形结构返回下级集合
+type CollectionRes struct {
+	ID            int             `json:"id"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	ParentID      int             `json:"parent_id"`
+	QuestionCount int             `json:"question_count"` // 集合中直接包含的题目数量(不含下级集合)
+	CreatedAt     string          `json:"created_at"`
+	UpdatedAt     string          `json:"updated_at"`
+	Children      []CollectionRes `json:"children"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+// DuplicateRes 可能重复的已有题目，Similarity 为由指纹汉明距离换算的相似度(0~1)
+type DuplicateRes struct {
+	ID         int     `json:"id"`
+	Title      string  `json:"title"`
+	Similarity float64 `json:"similarity"`
+}
+
+// ConfirmQuestionRes 确认入库的题目及题库中与之可能重复的题目
+type ConfirmQuestionRes struct {
+	ID         int            `json:"id"`
+	Duplicates []DuplicateRes `json:"duplicates,omitempty"`
+}
+
+// DuplicateClustersReq 查询重复题目分组请求参数
+type DuplicateClustersReq struct {
+	Language string `form:"language"` // 为空时查询全部语言
+}
+
+// DuplicateQuestionRes 重复题目分组中的一道题目
+type DuplicateQuestionRes struct {
+	ID         int    `json:"id"`
+	Title      string `json:"title"`
+	Language   string `json:"language"`
+	UserID     int    `json:"user_id"`
+	Status     string `json:"status"`
+	PaperCount int    `json:"paper_count"` // 使用该题目的试卷数量
+	CreatedAt  string `json:"created_at"`
+}
+
+// DuplicateClusterRes 一组相互相似的题目，第一道为建议保留的题目(被试卷使用最多、其次创建最早)
+type DuplicateClusterRes struct {
+	Questions     []DuplicateQuestionRes `json:"questions"`
+	MinSimilarity float64                `json:"min_similarity"` // 组内直接相似的题目间最低的相似度
+}
+
+// MergeQuestionsReq 合并重复题目请求，重复题目在试卷与集合中的引用改为保留的题目，之后移入回收站
+type MergeQuestionsReq struct {
+	SurvivorID   int   `json:"survivor_id" validate:"required"`
+	DuplicateIDs []int `json:"duplicate_ids" validate:"required"`
+}
+
+// MergeQuestionsRes 合并结果
+type MergeQuestionsRes struct {
+	Merged          int `json:"merged"`           // 移入回收站的重复题目数量
+	RepointedPapers int `json:"repointed_papers"` // 改为引用保留题目的试卷关联数量
+}
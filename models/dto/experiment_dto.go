@@ -0,0 +1,34 @@
+package dto
+
+// CreateExperimentReq 创建实验请求参数
+type CreateExperimentReq struct {
+	Name           string `json:"name" validate:"required"`
+	Description    string `json:"description"`
+	VariantPrompt  string `json:"variant_prompt"`
+	VariantModel   string `json:"variant_model"`
+	TrafficPercent int    `json:"traffic_percent"`
+	Active         bool   `json:"active"` // 同时只有一个实验生效，开启时会关闭其他实验
+}
+
+// UpdateExperimentReq 更新实验请求参数，未传的字段不更新
+type UpdateExperimentReq struct {
+	Name           *string `json:"name"`
+	Description    *string `json:"description"`
+	VariantPrompt  *string `json:"variant_prompt"`
+	VariantModel   *string `json:"variant_model"`
+	TrafficPercent *int    `json:"traffic_percent"`
+	Active         *bool   `json:"active"` // 开启时会关闭其他实验
+}
+
+// ExperimentRes 实验信息返回结构体
+type ExperimentRes struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	VariantPrompt  string `json:"variant_prompt"`
+	VariantModel   string `json:"variant_model"`
+	TrafficPercent int    `json:"traffic_percent"`
+	Active         bool   `json:"active"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
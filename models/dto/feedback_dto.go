@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"aiquiz/utils"
+	"aiquiz/utils/enums"
+)
+
+// AddCommentReq 发表评论请求，ParentID 不为 0 时为回复该评论所在的评论串
+type AddCommentReq struct {
+	Content  string `json:"content" validate:"required"`
+	ParentID int    `json:"parent_id"`
+}
+
+// AddReportReq 报告题目错误请求
+type AddReportReq struct {
+	Category    enums.ReportCategory `json:"category" validate:"required"` // wrong_answer / ambiguous / outdated / typo / compile_error
+	Description string               `json:"description"`
+}
+
+// UpdateFeedbackStatusReq 修改评论串或错误报告的处理状态
+type UpdateFeedbackStatusReq struct {
+	Status     enums.FeedbackStatus `json:"status" validate:"required"` // open / resolved
+	Resolution string               `json:"resolution"`                 // 处理说明，仅错误报告使用
+}
+
+// FeedbackListReq 分页查询评论或错误报告
+type FeedbackListReq struct {
+	utils.Page
+	Status enums.FeedbackStatus `form:"status"` // 为空时不限状态
+}
+
+// CommentRes 评论返回结构体，评论串开头附带回复
+type CommentRes struct {
+	ID            int          `json:"id"`
+	QuestionID    int          `json:"question_id"`
+	QuestionTitle string       `json:"question_title,omitempty"`
+	ParentID      int          `json:"parent_id"`
+	UserID        int          `json:"user_id"`
+	UserName      string       `json:"username"`
+	Content       string       `json:"content"`
+	Status        string       `json:"status,omitempty"` // 评论串状态，回复不返回
+	ResolvedBy    int          `json:"resolved_by,omitempty"`
+	ResolvedAt    string       `json:"resolved_at,omitempty"`
+	CreatedAt     string       `json:"created_at"`
+	ReplyCount    int          `json:"reply_count"`
+	Replies       []CommentRes `json:"replies,omitempty"`
+}
+
+// ReportRes 错误报告返回结构体
+type ReportRes struct {
+	ID            int    `json:"id"`
+	QuestionID    int    `json:"question_id"`
+	QuestionTitle string `json:"question_title,omitempty"`
+	Category      string `json:"category"`
+	Description   string `json:"description"`
+	Status        string `json:"status"`
+	Resolution    string `json:"resolution"`
+	ReporterID    int    `json:"reporter_id"`
+	ReporterName  string `json:"reporter_name"`
+	ResolvedBy    int    `json:"resolved_by,omitempty"`
+	ResolvedAt    string `json:"resolved_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import "aiquiz/utils/enums"
+
+// ImportQuestionsReq 导入题目请求(multipart表单，文件字段为 file)
+type ImportQuestionsReq struct {
+	Format      string `form:"format"`       // json / csv / xlsx / moodle_xml / gift / qti，不传时按文件扩展名判断
+	Mapping     string `form:"mapping"`      // 列映射(JSON格式的 ImportColumnMapping)，仅 csv / xlsx 使用
+	DryRun      bool   `form:"dry_run"`      // 只校验不入库
+	SkipInvalid bool   `form:"skip_invalid"` // 跳过校验失败的题目(如不支持的题型)，只导入其余题目
+	// 以下为文件中未提供对应内容时使用的默认值
+	QuestionType enums.QuestionType `form:"question_type"` // 仍为空时按正确选项数量判断单选或多选
+	Language     string             `form:"language"`
+	Keywords     string             `form:"keywords"`
+	Difficulty   enums.Difficulty   `form:"difficulty"`
+}
+
+// ImportColumnMapping 表格列与题目字段的对应关系，值为表头名称或列字母(如 "C")，
+// 未指定的字段按默认表头匹配(如 title/题目、answer/答案，选项为以 option/选项 开头的列)
+type ImportColumnMapping struct {
+	Title        string   `json:"title"`
+	Options      []string `json:"options"` // 按顺序依次为选项A、B、C...
+	Answer       string   `json:"answer"`  // 字母(如 "A,C")或位掩码
+	Explanation  string   `json:"explanation"`
+	QuestionType string   `json:"question_type"`
+	Language     string   `json:"language"`
+	Keywords     string   `json:"keywords"`
+	Tags         string   `json:"tags"` // 多个标签用逗号分隔
+	Difficulty   string   `json:"difficulty"`
+}
+
+// ImportRowError 导入文件中某一行(JSON为第几道题)的错误
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportQuestionsRes 导入结果，存在错误且未指定跳过时不会导入任何题目
+type ImportQuestionsRes struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`
+	Valid    int              `json:"valid"`
+	Imported int              `json:"imported"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// ExportQuestionsReq 按筛选条件导出题目
+type ExportQuestionsReq struct {
+	ListQuestionsReq
+	Format     string `form:"format"`      // moodle_xml / gift / qti / html
+	QTIVersion string `form:"qti_version"` // 2.1 / 3.0，默认 2.1
+	Answers    bool   `form:"answers"`     // html 格式是否包含答案与解析
+}
+
+// ExportPaperReq 导出试卷中的题目
+type ExportPaperReq struct {
+	Format     string `form:"format"`      // moodle_xml / gift / qti / html
+	QTIVersion string `form:"qti_version"` // 2.1 / 3.0，默认 2.1
+	Answers    bool   `form:"answers"`     // html 格式是否包含答案与解析
+}
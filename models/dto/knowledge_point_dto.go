@@ -0,0 +1,53 @@
+package dto
+
+// KnowledgePointReq 创建或修改知识点请求，ParentID 为 0 表示顶层知识点；修改时不能更改语言
+type KnowledgePointReq struct {
+	Language    string `json:"language" validate:"required"`
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+	ParentID    int    `json:"parent_id"`
+}
+
+// KnowledgePointTreeReq 查询知识点树请求参数
+type KnowledgePointTreeReq struct {
+	Language string `form:"language"` // 为空时返回全部语言
+}
+
+// KnowledgePointRes 知识点返回结构体，以树形结构返回下级知识点及覆盖的题目数量
+type KnowledgePointRes struct {
+	ID            int                 `json:"id"`
+	Language      string              `json:"language"`
+	Name          string              `json:"name"`
+	Description   string              `json:"description"`
+	ParentID      int                 `json:"parent_id"`
+	QuestionCount int                 `json:"question_count"`        // 直接关联该知识点的题目数量
+	TotalCount    int                 `json:"total_count"`           // 关联该知识点或其下级知识点的题目数量(去重)
+	Score         int                 `json:"score,omitempty"`       // 按试卷统计时，直接关联该知识点的题目分值之和
+	TotalScore    int                 `json:"total_score,omitempty"` // 按试卷统计时，关联该知识点或其下级知识点的题目分值之和(去重)
+	CreatedAt     string              `json:"created_at"`
+	UpdatedAt     string              `json:"updated_at"`
+	Children      []KnowledgePointRes `json:"children"`
+}
+
+// KnowledgePointRefRes 题目关联的知识点，Path 为从顶层知识点开始的完整路径
+type KnowledgePointRefRes struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Source string `json:"source,omitempty"` // manual / ai
+}
+
+// QuestionKnowledgePointsReq 设置题目关联的知识点请求，传入空数组表示清除
+type QuestionKnowledgePointsReq struct {
+	KnowledgePointIDs []int `json:"knowledge_point_ids"`
+}
+
+// PaperKnowledgeCoverageRes 试卷的知识点覆盖情况，未关联知识点的题目不计入知识点树
+type PaperKnowledgeCoverageRes struct {
+	PaperID         int                 `json:"paper_id"`
+	QuestionCount   int                 `json:"question_count"`   // 试卷中的题目数量
+	TotalScore      int                 `json:"total_score"`      // 试卷总分
+	ClassifiedCount int                 `json:"classified_count"` // 已关联知识点的题目数量
+	ClassifiedScore int                 `json:"classified_score"` // 已关联知识点的题目分值之和
+	KnowledgePoints []KnowledgePointRes `json:"knowledge_points"`
+}
@@ -25,6 +25,12 @@ type PaperListReq struct {
 type AddPaperQuestionsReq struct {
 	QuestionID int `json:"question_id" validate:"required"`
 	Score      int `json:"score"`
+	Revision   int `json:"revision"` // 固定使用的题目版本号，不传则始终使用最新内容
+}
+
+// PinQuestionRevisionReq 固定试卷中题目的版本，revision 为 0 时取消固定
+type PinQuestionRevisionReq struct {
+	Revision int `json:"revision"`
 }
 
 // PaperListRes 返回列表时的试卷结构
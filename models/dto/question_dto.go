@@ -30,24 +30,39 @@ type GenerateQuestionReq struct {
 
 // ConfirmQuestionReq 确认题目请求结构体
 type ConfirmQuestionReq struct {
-	Title        string             `json:"title" validate:"required"`
-	Options      []Option           `json:"options" validate:"required"`
-	Answer       int                `json:"answer" validate:"required"`
-	Explanation  string             `json:"explanation" validate:"required"`
-	QuestionType enums.QuestionType `json:"question_type" validate:"required"`
-	Language     string             `json:"language" validate:"required"`
-	AiModel      enums.AiModel      `json:"ai_model" validate:"required"`
-	Keywords     string             `json:"keywords" validate:"required"`
+	Title             string             `json:"title" validate:"required"`
+	Options           []Option           `json:"options" validate:"required"`
+	Answer            int                `json:"answer" validate:"required"`
+	Explanation       string             `json:"explanation" validate:"required"`
+	QuestionType      enums.QuestionType `json:"question_type" validate:"required"`
+	Language          string             `json:"language" validate:"required"`
+	AiModel           enums.AiModel      `json:"ai_model" validate:"required"`
+	Keywords          string             `json:"keywords"`
+	Tags              []string           `json:"tags"`                // 不传时由关键词拆分得到，关键词为空时由标签拼接
+	Difficulty        enums.Difficulty   `json:"difficulty"`          // 可选: easy / medium / hard
+	Visibility        enums.Visibility   `json:"visibility"`          // 可选: private(默认) / organization / public
+	GenerationID      int                `json:"generation_id"`       // 生成时返回的记录ID，手动录入的题目不传
+	KnowledgePointIDs []int              `json:"knowledge_point_ids"` // 考查的知识点ID，须属于题目的语言，生成的题目可原样带回推荐的知识点
 }
 
-// ListQuestionsReq 分页获取题目列表（根据条件选择）
+// ListQuestionsReq 分页获取题目列表（根据条件选择），也作为批量操作的筛选条件
 type ListQuestionsReq struct {
 	utils.Page
-	Title        string             `form:"title"`
-	QuestionType enums.QuestionType `form:"question_type"`
-	Language     string             `form:"language"`
-	AiModel      enums.AiModel      `form:"ai_model"`
-	Keywords     string             `form:"keywords"`
+	Title            string             `json:"title" form:"title"`
+	QuestionType     enums.QuestionType `json:"question_type" form:"question_type"`
+	Language         string             `json:"language" form:"language"`
+	AiModel          enums.AiModel      `json:"ai_model" form:"ai_model"`
+	Keywords         string             `json:"keywords" form:"keywords"`
+	Difficulty       enums.Difficulty   `json:"difficulty" form:"difficulty"`
+	Visibility       enums.Visibility   `json:"visibility" form:"visibility"`
+	Status           enums.ReviewStatus `json:"status" form:"status"`                         // 审核状态
+	ExcludeReported  bool               `json:"exclude_reported" form:"exclude_reported"`     // 排除有未解决错误报告的题目
+	CollectionID     int                `json:"collection_id" form:"collection_id"`           // 集合，结果按题目在集合中的顺序排列
+	Subcollections   bool               `json:"subcollections" form:"subcollections"`         // 同时包含下级集合中的题目
+	Q                string             `json:"q" form:"q"`                                   // 全文检索，结果按相关度排序并返回高亮摘要
+	Tags             string             `json:"tags" form:"tags"`                             // 标签，多个用逗号分隔
+	TagMode          string             `json:"tag_mode" form:"tag_mode"`                     // 多个标签的匹配方式: and(全部包含) / or(任一包含，默认)
+	KnowledgePointID int                `json:"knowledge_point_id" form:"knowledge_point_id"` // 知识点，包含关联其下级知识点的题目
 }
 
 type UpdateQuestionReq struct {
@@ -55,26 +70,138 @@ type UpdateQuestionReq struct {
 	QuestionType enums.QuestionType `json:"question_type"`
 	Language     string             `json:"language"`
 	Keywords     string             `json:"keywords"`
+	Tags         []string           `json:"tags"` // 传入时整体替换题目的标签
+	Difficulty   enums.Difficulty   `json:"difficulty"`
+	Visibility   enums.Visibility   `json:"visibility"`
 }
 
 // QuestionRes 查询题目列表返回结构体
 type QuestionRes struct {
 	ID int `json:"id"`
 	Question
-	QuestionType string `json:"question_type"`
-	Language     string `json:"language"`
-	Keywords     string `json:"keywords"`
-	AiModel      string `json:"ai_model"`
-	CreateAt     string `json:"created_at"`
-	UserName     string `json:"username"`
-	UserID       int    `json:"user_id"`
+	QuestionType string   `json:"question_type"`
+	Language     string   `json:"language"`
+	Keywords     string   `json:"keywords"`
+	AiModel      string   `json:"ai_model"`
+	Difficulty   string   `json:"difficulty"`
+	Visibility   string   `json:"visibility"`
+	Status       string   `json:"status"`                     // 审核状态
+	Reported     bool     `json:"reported"`                   // 是否有未解决的错误报告
+	OpenReports  int      `json:"open_reports"`               // 未解决的错误报告数量
+	ForkedFromID int      `json:"forked_from_id,omitempty"`   // 复制来源题目ID
+	ForkedFrom   string   `json:"forked_from_user,omitempty"` // 复制来源题目的创建者用户名
+	CreateAt     string   `json:"created_at"`
+	UserName     string   `json:"username"`
+	UserID       int      `json:"user_id"`
+	Tags         []string `json:"tags"`
+	Revision     int      `json:"revision,omitempty"` // 试卷中固定的题目版本号
+	Snippet      string   `json:"snippet,omitempty"`  // 全文检索命中的高亮摘要(HTML，已转义)
 }
 
 // GenerateQuestionRes 生成题目返回结构体
 type GenerateQuestionRes struct {
 	Question
-	QuestionType string `json:"question_type"`
-	Language     string `json:"language"`
-	Keywords     string `json:"keywords"`
-	AiModel      string `json:"ai_model"`
+	GenerationID    int                    `json:"generation_id"`              // 确认入库时需原样带回
+	Flagged         bool                   `json:"flagged"`                    // 是否被审核标记
+	FlagReasons     []string               `json:"flag_reasons,omitempty"`     // 被标记的原因
+	Duplicates      []DuplicateRes         `json:"duplicates,omitempty"`       // 题库中可能重复的题目
+	KnowledgePoints []KnowledgePointRefRes `json:"knowledge_points,omitempty"` // 模型推荐的考查知识点
+	QuestionType    string                 `json:"question_type"`
+	Language        string                 `json:"language"`
+	Keywords        string                 `json:"keywords"`
+	AiModel         string                 `json:"ai_model"`
+}
+
+// BulkQuestionReq 题目批量操作请求，IDs 与 Filter 二选一
+type BulkQuestionReq struct {
+	IDs              []int               `json:"ids"`
+	Filter           *ListQuestionsReq   `json:"filter"` // 对筛选结果的全部题目执行操作(忽略分页)
+	Operation        enums.BulkOperation `json:"operation" validate:"required"`
+	Language         string              `json:"language"`           // set_language 使用
+	Tags             []string            `json:"tags"`               // add_tags / remove_tags 使用
+	Difficulty       enums.Difficulty    `json:"difficulty"`         // set_difficulty 使用
+	Visibility       enums.Visibility    `json:"visibility"`         // set_visibility 使用
+	CollectionID     int                 `json:"collection_id"`      // move_to_collection 使用
+	FromCollectionID int                 `json:"from_collection_id"` // move_to_collection 使用，不为 0 时同时移出该集合
+}
+
+// BulkItemResult 批量操作中单道题目的处理结果
+type BulkItemResult struct {
+	ID      int    `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkQuestionRes 批量操作结果
+type BulkQuestionRes struct {
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// ForkQuestionRes 复制题目返回结构体
+type ForkQuestionRes struct {
+	ID           int `json:"id"`             // 新题目ID
+	ForkedFromID int `json:"forked_from_id"` // 来源题目ID
+}
+
+// ReviewQuestionReq 提交审核、审核通过或驳回请求
+type ReviewQuestionReq struct {
+	Comment string `json:"comment"` // 审核意见，驳回时必填
+}
+
+// QuestionReviewRes 题目审核记录返回结构体
+type QuestionReviewRes struct {
+	ID           int    `json:"id"`
+	QuestionID   int    `json:"question_id"`
+	Action       string `json:"action"`
+	FromStatus   string `json:"from_status"`
+	ToStatus     string `json:"to_status"`
+	Comment      string `json:"comment"`
+	ReviewerID   int    `json:"reviewer_id"`
+	ReviewerName string `json:"reviewer_name"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// RenderReq 渲染 Markdown 内容请求结构体
+type RenderReq struct {
+	Content string `json:"content" validate:"required"`
+}
+
+// RenderRes 渲染结果，html 为只包含白名单标签的安全片段
+type RenderRes struct {
+	HTML string `json:"html"`
+}
+
+// VerifyOutputReq 核对输出类题目答案请求结构体
+type VerifyOutputReq struct {
+	Language string   `json:"language" validate:"required"`
+	Question Question `json:"question"`
+}
+
+// OutputCheckRes 运行题干代码核对答案的结果，Checked 为 false 表示不是可核对的输出类题目
+type OutputCheckRes struct {
+	Checked       bool   `json:"checked"`
+	Matched       bool   `json:"matched"`                  // 运行结果与正确选项一致
+	Actual        string `json:"actual,omitempty"`         // 实际运行结果(输出、编译错误或 panic 等)
+	MatchedOption string `json:"matched_option,omitempty"` // 与运行结果一致的选项，如 B
+	Message       string `json:"message,omitempty"`
+}
+
+// RenderedOption 渲染后的选项
+type RenderedOption struct {
+	Value int    `json:"value"`
+	HTML  string `json:"html"`
+}
+
+// RenderedQuestionRes 渲染后的题目，用于展示与打印
+type RenderedQuestionRes struct {
+	ID           int              `json:"id"`
+	QuestionType string           `json:"question_type"`
+	Language     string           `json:"language"`
+	Title        string           `json:"title"`
+	Options      []RenderedOption `json:"options"`
+	Answer       int              `json:"answer"`
+	Explanation  string           `json:"explanation"`
 }
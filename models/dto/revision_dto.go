@@ -0,0 +1,39 @@
+package dto
+
+// QuestionRevisionRes 题目版本返回结构体
+type QuestionRevisionRes struct {
+	ID           int    `json:"id"`
+	QuestionID   int    `json:"question_id"`
+	Revision     int    `json:"revision"`
+	Action       string `json:"action"`                  // create / update / restore
+	RestoredFrom int    `json:"restored_from,omitempty"` // 恢复操作的来源版本号
+	Question
+	QuestionType string   `json:"question_type"`
+	Language     string   `json:"language"`
+	Keywords     string   `json:"keywords"`
+	Tags         []string `json:"tags"`
+	EditorID     int      `json:"editor_id"`
+	EditorName   string   `json:"editor_name"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+// RevisionDiffReq 对比两个版本，未传 from 时为 to 的上一版本，未传 to 时为最新版本
+type RevisionDiffReq struct {
+	From int `form:"from"`
+	To   int `form:"to"`
+}
+
+// RevisionFieldDiff 单个字段的差异
+type RevisionFieldDiff struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// RevisionDiffRes 两个版本的逐字段差异，只包含有变化的字段
+type RevisionDiffRes struct {
+	QuestionID int                 `json:"question_id"`
+	From       int                 `json:"from"`
+	To         int                 `json:"to"`
+	Changes    []RevisionFieldDiff `json:"changes"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+// SemanticSearchReq 语义检索请求参数
+type SemanticSearchReq struct {
+	Q        string `form:"q"`
+	Language string `form:"language"` // 为空时检索全部语言
+	Limit    int    `form:"limit"`    // 默认 10，最多 50
+}
+
+// SimilarQuestionsReq 相似题目请求参数
+type SimilarQuestionsReq struct {
+	Limit int `form:"limit"` // 默认 10，最多 50
+}
+
+// SemanticQuestionRes 语义检索结果，Score 为向量的余弦相似度
+type SemanticQuestionRes struct {
+	ID           int     `json:"id"`
+	Title        string  `json:"title"`
+	QuestionType string  `json:"question_type"`
+	Language     string  `json:"language"`
+	Keywords     string  `json:"keywords"`
+	Difficulty   string  `json:"difficulty"`
+	UserID       int     `json:"user_id"`
+	Score        float64 `json:"score"`
+}
@@ -8,7 +8,9 @@ type UserStatisticsRes struct {
 	PaperCount               int                    `json:"paper_count"`       // 试卷数量
 	QuestionTypeDistribution []TypeDistribution     `json:"type_distribution"` // 题目类型分布
 	LanguageDistribution     []LanguageDistribution `json:"language_distribution"`
-	ActiveTimeAnalysis       ActiveTimeAnalysis     `json:"active_time"` // 活跃时间分析
+	TagDistribution          []TagDistribution      `json:"tag_distribution"`    // 标签分布(题目数量前20)
+	StatusDistribution       []StatusDistribution   `json:"status_distribution"` // 审核状态分布
+	ActiveTimeAnalysis       ActiveTimeAnalysis     `json:"active_time"`         // 活跃时间分析
 }
 
 // TypeDistribution 题目类型分布
@@ -17,6 +19,12 @@ type TypeDistribution struct {
 	Count int    `json:"count"` // 数量
 }
 
+// StatusDistribution 审核状态分布
+type StatusDistribution struct {
+	Status string `json:"status"` // draft / in_review / approved / rejected
+	Count  int    `json:"count"`  // 数量
+}
+
 // LanguageDistribution 语言类型
 type LanguageDistribution struct {
 	Language string `json:"language"` // 语言类型（Go、Java）
@@ -36,7 +44,10 @@ type SystemStatisticsRes struct {
 	TotalQuestionCount        int                         `json:"total_question_count"`        // 总题目数
 	TotalPaperCount           int                         `json:"total_paper_count"`           // 总试卷数
 	LanguageDistribution      []LanguageDistribution      `json:"language_distribution"`       // 编程语言分布
+	TagDistribution           []TagDistribution           `json:"tag_distribution"`            // 标签分布(题目数量前20)
+	StatusDistribution        []StatusDistribution        `json:"status_distribution"`         // 审核状态分布
 	AIModelUsage              []AIModelDistribution       `json:"ai_model_usage"`              // AI模型使用情况
+	ExperimentResults         []ExperimentResult          `json:"experiment_results"`          // 提示词/模型实验结果
 	PaperQuestionDistribution []PaperQuestionDistribution `json:"paper_question_distribution"` // 试卷题目数量分布
 	ActivityAnalysis          SystemActivityAnalysis      `json:"activity_analysis"`           // 活跃度分析
 }
@@ -47,6 +58,20 @@ type AIModelDistribution struct {
 	Count     int    `json:"count"`      // 使用次数
 }
 
+// ExperimentResult 实验各分组的生成题目采纳情况
+type ExperimentResult struct {
+	ExperimentID   int     `json:"experiment_id"`
+	ExperimentName string  `json:"experiment_name"`
+	Variant        string  `json:"variant"`         // control / treatment
+	Generated      int     `json:"generated"`       // 生成题目数
+	Unedited       int     `json:"unedited"`        // 原样确认数
+	Edited         int     `json:"edited"`          // 修改后确认数
+	Discarded      int     `json:"discarded"`       // 丢弃数(超时未确认)
+	Pending        int     `json:"pending"`         // 仍在等待确认的数量
+	AcceptanceRate float64 `json:"acceptance_rate"` // (原样+修改)/已有结果的题目数
+	UneditedRate   float64 `json:"unedited_rate"`   // 原样/已有结果的题目数
+}
+
 // PaperQuestionDistribution 试卷题目数量分布
 type PaperQuestionDistribution struct {
 	Range string `json:"range"` // 题目数量范围（如"1-10"）
@@ -0,0 +1,84 @@
+package dto
+
+// SyllabusTopicReq 大纲主题，题目带有 Tags 中任一标签或关键词包含 Keywords 中任一关键词即属于该主题
+type SyllabusTopicReq struct {
+	Name     string   `json:"name" validate:"required"`
+	Tags     []string `json:"tags"`
+	Keywords []string `json:"keywords"`
+	Weight   int      `json:"weight" validate:"required"` // 相对权重，主题的目标分值按权重占比分配试卷总分
+}
+
+// SyllabusReq 创建或修改课程大纲请求，修改时以 Topics 整体替换原有主题
+type SyllabusReq struct {
+	Name        string             `json:"name" validate:"required"`
+	Description string             `json:"description"`
+	Language    string             `json:"language"` // 为空表示不限编程语言
+	Topics      []SyllabusTopicReq `json:"topics" validate:"required"`
+}
+
+// SyllabusTopicRes 大纲主题返回结构体
+type SyllabusTopicRes struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Tags     []string `json:"tags"`
+	Keywords []string `json:"keywords"`
+	Weight   int      `json:"weight"`
+}
+
+// SyllabusRes 课程大纲返回结构体
+type SyllabusRes struct {
+	ID          int                `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Language    string             `json:"language"`
+	UserID      int                `json:"user_id"`
+	CreatedAt   string             `json:"created_at"`
+	UpdatedAt   string             `json:"updated_at"`
+	Topics      []SyllabusTopicRes `json:"topics"`
+}
+
+// SyllabusCoverageReq 试卷大纲覆盖报告请求参数
+type SyllabusCoverageReq struct {
+	SyllabusID int `form:"syllabus_id" validate:"required"`
+}
+
+// SyllabusSuggestionRes 可用于补足主题分值的题库题目
+type SyllabusSuggestionRes struct {
+	ID           int      `json:"id"`
+	Title        string   `json:"title"`
+	QuestionType string   `json:"question_type"`
+	Language     string   `json:"language"`
+	Keywords     string   `json:"keywords"`
+	Difficulty   string   `json:"difficulty"`
+	Status       string   `json:"status"`
+	UserID       int      `json:"user_id"`
+	Tags         []string `json:"tags"`
+}
+
+// TopicCoverageRes 主题的覆盖情况，同一道题目属于多个主题时分值在各主题中均计入
+type TopicCoverageRes struct {
+	TopicID       int                     `json:"topic_id"`
+	Name          string                  `json:"name"`
+	Weight        int                     `json:"weight"`
+	TargetScore   float64                 `json:"target_score"`   // 按权重占比分配的目标分值
+	ActualScore   int                     `json:"actual_score"`   // 试卷中属于该主题的题目分值之和
+	Gap           float64                 `json:"gap"`            // 目标分值与实际分值之差，负数表示超出
+	ActualPercent float64                 `json:"actual_percent"` // 实际分值占试卷总分的百分比
+	TargetPercent float64                 `json:"target_percent"` // 权重占比(百分比)
+	Status        string                  `json:"status"`         // missing / under / ok / over
+	QuestionIDs   []int                   `json:"question_ids"`
+	Suggestions   []SyllabusSuggestionRes `json:"suggestions,omitempty"` // 未覆盖或分值不足时推荐的题库题目
+}
+
+// SyllabusCoverageRes 试卷对课程大纲的覆盖报告
+type SyllabusCoverageRes struct {
+	PaperID            int                `json:"paper_id"`
+	SyllabusID         int                `json:"syllabus_id"`
+	SyllabusName       string             `json:"syllabus_name"`
+	TotalScore         int                `json:"total_score"`    // 计算目标分值使用的试卷总分
+	QuestionScore      int                `json:"question_score"` // 试卷中题目分值之和
+	QuestionCount      int                `json:"question_count"`
+	UnmatchedScore     int                `json:"unmatched_score"` // 不属于任何主题的题目分值之和
+	UnmatchedQuestions []int              `json:"unmatched_questions"`
+	Topics             []TopicCoverageRes `json:"topics"`
+}
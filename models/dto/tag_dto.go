@@ -0,0 +1,40 @@
+package dto
+
+import "aiquiz/utils"
+
+// ListTagsReq 分页查询标签
+type ListTagsReq struct {
+	utils.Page
+	Name string `form:"name"`
+}
+
+// TagReq 创建/修改标签请求参数
+type TagReq struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// TagAliasReq 添加别名请求参数
+type TagAliasReq struct {
+	Alias string `json:"alias" validate:"required"`
+}
+
+// TagAliasRes 标签别名返回结构体
+type TagAliasRes struct {
+	ID    int    `json:"id"`
+	Alias string `json:"alias"`
+}
+
+// TagRes 标签返回结构体
+type TagRes struct {
+	ID            int           `json:"id"`
+	Name          string        `json:"name"`
+	DisplayName   string        `json:"display_name"`
+	Aliases       []TagAliasRes `json:"aliases"`
+	QuestionCount int           `json:"question_count"` // 关联的题目数量
+}
+
+// TagDistribution 标签下的题目数量
+type TagDistribution struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
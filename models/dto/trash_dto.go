@@ -0,0 +1,47 @@
+package dto
+
+import "aiquiz/utils"
+
+// TrashListReq 分页查询回收站
+type TrashListReq struct {
+	utils.Page
+	PaperID int `form:"paper_id"` // 仅查询试卷题目关联时有效，按试卷过滤
+}
+
+// TrashQuestionRes 回收站中的题目
+type TrashQuestionRes struct {
+	ID           int    `json:"id"`
+	Title        string `json:"title"`
+	QuestionType string `json:"question_type"`
+	Language     string `json:"language"`
+	Keywords     string `json:"keywords"`
+	UserID       int    `json:"user_id"`
+	UserName     string `json:"username"`
+	DeletedAt    string `json:"deleted_at"`
+	ExpireAt     string `json:"expire_at,omitempty"` // 到期后被自动彻底删除，未开启自动清理时为空
+}
+
+// TrashPaperRes 回收站中的试卷
+type TrashPaperRes struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	TotalScore  int    `json:"total_score"`
+	UserID      int    `json:"user_id"`
+	UserName    string `json:"username"`
+	DeletedAt   string `json:"deleted_at"`
+	ExpireAt    string `json:"expire_at,omitempty"`
+}
+
+// TrashPaperQuestionRes 回收站中的试卷题目关联
+type TrashPaperQuestionRes struct {
+	ID            int    `json:"id"`
+	PaperID       int    `json:"paper_id"`
+	PaperTitle    string `json:"paper_title"`
+	QuestionID    int    `json:"question_id"`
+	QuestionTitle string `json:"question_title"`
+	QuestionOrder int    `json:"question_order"` // 删除前的顺序号，恢复时据此插回原位置
+	Score         int    `json:"score"`
+	DeletedAt     string `json:"deleted_at"`
+	ExpireAt      string `json:"expire_at,omitempty"`
+}
@@ -1,6 +1,9 @@
 package dto
 
-import "aiquiz/utils"
+import (
+	"aiquiz/utils"
+	"aiquiz/utils/enums"
+)
 
 // RegisterReq 注册请求
 type RegisterReq struct {
@@ -24,6 +27,11 @@ type UpdateUserReq struct {
 	Password string `json:"password"`
 }
 
+// UpdateUserRoleReq 修改用户角色请求
+type UpdateUserRoleReq struct {
+	Role enums.Role `json:"role" validate:"required"` // user / reviewer / admin
+}
+
 // UsersRes 用户信息响应
 type UsersRes struct {
 	ID        int    `json:"id"`
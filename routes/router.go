@@ -13,6 +13,19 @@ type AppDependencies interface {
 	GetQuestionController() *controllers.QuestionController
 	GetPaperController() *controllers.PaperController
 	GetStatisticController() *controllers.StatisticController
+	GetExperimentController() *controllers.ExperimentController
+	GetTagController() *controllers.TagController
+	GetTrashController() *controllers.TrashController
+	GetImportController() *controllers.ImportController
+	GetExportController() *controllers.ExportController
+	GetReviewController() *controllers.ReviewController
+	GetFeedbackController() *controllers.FeedbackController
+	GetCollectionController() *controllers.CollectionController
+	GetAttachmentController() *controllers.AttachmentController
+	GetDuplicateController() *controllers.DuplicateController
+	GetSemanticController() *controllers.SemanticController
+	GetKnowledgePointController() *controllers.KnowledgePointController
+	GetSyllabusController() *controllers.SyllabusController
 	GetDB() *gorm.DB
 }
 
@@ -27,6 +40,19 @@ func InitRouter(deps AppDependencies) *gin.Engine {
 		questionController := deps.GetQuestionController()
 		paperController := deps.GetPaperController()
 		statisticController := deps.GetStatisticController()
+		experimentController := deps.GetExperimentController()
+		tagController := deps.GetTagController()
+		trashController := deps.GetTrashController()
+		importController := deps.GetImportController()
+		exportController := deps.GetExportController()
+		reviewController := deps.GetReviewController()
+		feedbackController := deps.GetFeedbackController()
+		collectionController := deps.GetCollectionController()
+		attachmentController := deps.GetAttachmentController()
+		duplicateController := deps.GetDuplicateController()
+		semanticController := deps.GetSemanticController()
+		knowledgePointController := deps.GetKnowledgePointController()
+		syllabusController := deps.GetSyllabusController()
 		DB := deps.GetDB()
 
 		// 认证相关路由（无需认证）
@@ -35,15 +61,21 @@ func InitRouter(deps AppDependencies) *gin.Engine {
 			auth.POST("/login", authController.Login)
 			auth.POST("/register", authController.Register)
 		}
+		// 公开题目(无需认证)
+		api.GET("/public/questions", questionController.ListPublicQuestions)
 
 		// 需要认证的路由
 		authorized := api.Group("/", middlewares.JWTAuth())
 		{
+			// 渲染 Markdown 内容(预览)
+			authorized.POST("/render", questionController.RenderContent)
+
 			// 用户相关路由
 			users := authorized.Group("/users")
 			{
 				users.GET("/", userController.ListUsers)
 				users.PUT("/:id", userController.UpdateUser)
+				users.PUT("/:id/role", userController.UpdateUserRole)
 				users.DELETE("/:id", userController.DeleteUser)
 			}
 
@@ -53,9 +85,40 @@ func InitRouter(deps AppDependencies) *gin.Engine {
 				questions.POST("/generate", questionController.GenerateQuestion)
 				questions.POST("/confirm", questionController.ConfirmQuestions)
 				questions.GET("/", questionController.ListQuestions)
+				questions.GET("/shared", questionController.ListSharedQuestions)
+				questions.GET("/search/semantic", semanticController.Search)
+				questions.POST("/bulk", questionController.BulkOperate)
+				// 运行代码核对输出仅限审核员与管理员
+				questions.POST("/verify-output", middlewares.ReviewerMiddleware(), questionController.VerifyOutput)
+				questions.POST("/import", importController.ImportQuestions)
+				questions.GET("/export", exportController.ExportQuestions)
 				// 需要判断是否为该用户的题目，由于方法较少故未抽象为中间件
 				questions.PUT("/:question_id", questionController.UpdateQuestion)
 				questions.DELETE("/:question_id", questionController.DeleteQuestion)
+				questions.POST("/:question_id/fork", questionController.ForkQuestion)
+				questions.GET("/:question_id/render", questionController.RenderQuestion)
+				questions.GET("/:question_id/similar", semanticController.Similar)
+				// 题目引用的附件
+				questions.GET("/:question_id/attachments", attachmentController.ListQuestionAttachments)
+				questions.PUT("/:question_id/attachments", attachmentController.SetQuestionAttachments)
+				// 题目考查的知识点
+				questions.GET("/:question_id/knowledge-points", knowledgePointController.ListQuestionKnowledgePoints)
+				questions.PUT("/:question_id/knowledge-points", knowledgePointController.SetQuestionKnowledgePoints)
+				// 题目审核
+				questions.POST("/:question_id/submit-review", reviewController.SubmitReview)
+				questions.GET("/:question_id/reviews", reviewController.ListReviews)
+				// 评论与错误报告
+				questions.GET("/:question_id/comments", feedbackController.ListComments)
+				questions.POST("/:question_id/comments", feedbackController.AddComment)
+				questions.DELETE("/:question_id/comments/:comment_id", feedbackController.DeleteComment)
+				questions.PUT("/:question_id/comments/:comment_id/status", feedbackController.UpdateCommentStatus)
+				questions.GET("/:question_id/reports", feedbackController.ListReports)
+				questions.POST("/:question_id/reports", feedbackController.AddReport)
+				questions.PUT("/:question_id/reports/:report_id/status", feedbackController.UpdateReportStatus)
+				// 题目版本
+				questions.GET("/:question_id/revisions", questionController.ListRevisions)
+				questions.GET("/:question_id/revisions/diff", questionController.DiffRevisions)
+				questions.POST("/:question_id/revisions/:revision/restore", questionController.RestoreRevision)
 			}
 			// 试卷相关路由
 			papers := authorized.Group("/papers")
@@ -68,15 +131,74 @@ func InitRouter(deps AppDependencies) *gin.Engine {
 					paperAuth.GET("/", paperController.GetPaper)
 					paperAuth.PUT("/", paperController.UpdatePaper)
 					paperAuth.DELETE("/", paperController.DeletePaper)
+					paperAuth.GET("/export", exportController.ExportPaper)
+					paperAuth.GET("/knowledge-points", knowledgePointController.PaperCoverage)
+					paperAuth.GET("/syllabus-coverage", syllabusController.PaperCoverage)
 					// 试卷题目相关
 					paperQuestion := paperAuth.Group("/questions")
 					{
 						paperQuestion.POST("/", paperController.AddPaperQuestions)
+						paperQuestion.POST("/collection", paperController.AddCollectionQuestions)
 						paperQuestion.DELETE("/:question_id", paperController.DeletePaperQuestions)
 						paperQuestion.PUT("/order", paperController.UpdatePaperQuestionOrder)
+						paperQuestion.PUT("/:question_id/revision", paperController.PinQuestionRevision)
 					}
 				}
 			}
+			// 题目集合相关路由(集合所有者与管理员)
+			collections := authorized.Group("/collections")
+			{
+				collections.POST("/", collectionController.CreateCollection)
+				collections.GET("/", collectionController.ListCollections)
+				collections.GET("/:collection_id", collectionController.GetCollection)
+				collections.PUT("/:collection_id", collectionController.UpdateCollection)
+				collections.DELETE("/:collection_id", collectionController.DeleteCollection)
+				collections.POST("/:collection_id/questions", collectionController.AddQuestions)
+				collections.PUT("/:collection_id/questions/order", collectionController.ReorderQuestions)
+				collections.DELETE("/:collection_id/questions/:question_id", collectionController.RemoveQuestion)
+			}
+			// 课程大纲相关路由(大纲所有者与管理员)
+			syllabi := authorized.Group("/syllabi")
+			{
+				syllabi.POST("/", syllabusController.CreateSyllabus)
+				syllabi.GET("/", syllabusController.ListSyllabi)
+				syllabi.GET("/:syllabus_id", syllabusController.GetSyllabus)
+				syllabi.PUT("/:syllabus_id", syllabusController.UpdateSyllabus)
+				syllabi.DELETE("/:syllabus_id", syllabusController.DeleteSyllabus)
+			}
+			// 附件相关路由
+			attachments := authorized.Group("/attachments")
+			{
+				attachments.POST("/", attachmentController.Upload)
+				attachments.GET("/:attachment_id", attachmentController.Download)
+				attachments.DELETE("/:attachment_id", attachmentController.DeleteAttachment)
+			}
+			// 收件箱: 自己题目收到的评论与错误报告(管理员查看全部)
+			inbox := authorized.Group("/inbox")
+			{
+				inbox.GET("/comments", feedbackController.InboxComments)
+				inbox.GET("/reports", feedbackController.InboxReports)
+			}
+			// 审核相关路由(审核员与管理员)
+			reviews := authorized.Group("/reviews", middlewares.ReviewerMiddleware())
+			{
+				reviews.GET("/queue", reviewController.ListQueue)
+				reviews.POST("/questions/:question_id/approve", reviewController.ApproveQuestion)
+				reviews.POST("/questions/:question_id/reject", reviewController.RejectQuestion)
+			}
+			// 回收站相关路由(普通用户只能查看和操作自己的资源)
+			trash := authorized.Group("/trash")
+			{
+				trash.GET("/questions", trashController.ListQuestions)
+				trash.POST("/questions/:question_id/restore", trashController.RestoreQuestion)
+				trash.DELETE("/questions/:question_id", trashController.PurgeQuestion)
+				trash.GET("/papers", trashController.ListPapers)
+				trash.POST("/papers/:paper_id/restore", trashController.RestorePaper)
+				trash.DELETE("/papers/:paper_id", trashController.PurgePaper)
+				trash.GET("/paper-questions", trashController.ListPaperQuestions)
+				trash.POST("/paper-questions/:id/restore", trashController.RestorePaperQuestion)
+				trash.DELETE("/paper-questions/:id", trashController.PurgePaperQuestion)
+			}
 			// 统计相关路由
 			statistics := authorized.Group("/statistics", middlewares.AdminMiddleware())
 			{
@@ -84,6 +206,47 @@ func InitRouter(deps AppDependencies) *gin.Engine {
 				statistics.GET("/users/:user_id", statisticController.GetUserStatistics)
 				// 整体统计路由
 				statistics.GET("/overview", statisticController.GetSystemStatistics)
+				// 提示词/模型实验统计路由
+				statistics.GET("/experiments", statisticController.GetExperimentStatistics)
+			}
+			// 重复题目检测与合并路由
+			duplicates := authorized.Group("/duplicates", middlewares.AdminMiddleware())
+			{
+				duplicates.GET("/", duplicateController.ListClusters)
+				duplicates.POST("/merge", duplicateController.MergeQuestions)
+			}
+			// 提示词/模型实验管理路由
+			experiments := authorized.Group("/experiments", middlewares.AdminMiddleware())
+			{
+				experiments.POST("/", experimentController.CreateExperiment)
+				experiments.GET("/", experimentController.ListExperiments)
+				experiments.PUT("/:experiment_id", experimentController.UpdateExperiment)
+				experiments.DELETE("/:experiment_id", experimentController.DeleteExperiment)
+			}
+			// 知识点树(各语言)，维护知识点需要管理员权限
+			knowledgePoints := authorized.Group("/knowledge-points")
+			{
+				knowledgePoints.GET("/", knowledgePointController.ListKnowledgePoints)
+				knowledgePoints.GET("/:knowledge_point_id", knowledgePointController.GetKnowledgePoint)
+				knowledgeAdmin := knowledgePoints.Group("/", middlewares.AdminMiddleware())
+				{
+					knowledgeAdmin.POST("/", knowledgePointController.CreateKnowledgePoint)
+					knowledgeAdmin.PUT("/:knowledge_point_id", knowledgePointController.UpdateKnowledgePoint)
+					knowledgeAdmin.DELETE("/:knowledge_point_id", knowledgePointController.DeleteKnowledgePoint)
+				}
+			}
+			// 标签相关路由(查询对所有用户开放，维护仅限管理员)
+			tags := authorized.Group("/tags")
+			{
+				tags.GET("/", tagController.ListTags)
+				tagAdmin := tags.Group("/", middlewares.AdminMiddleware())
+				{
+					tagAdmin.POST("/", tagController.CreateTag)
+					tagAdmin.PUT("/:tag_id", tagController.UpdateTag)
+					tagAdmin.DELETE("/:tag_id", tagController.DeleteTag)
+					tagAdmin.POST("/:tag_id/aliases", tagController.AddAlias)
+					tagAdmin.DELETE("/:tag_id/aliases/:alias_id", tagController.DeleteAlias)
+				}
 			}
 		}
 
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"aiquiz/config"
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils/storage"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gabriel-vasile/mimetype"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// attachmentGCInterval 清理未引用附件的执行间隔
+	attachmentGCInterval = time.Hour
+	// maxQuestionAttachments 单道题目最多引用的附件数量
+	maxQuestionAttachments = 20
+	// maxFilenameLength 附件文件名保留的最大长度(字符数)
+	maxFilenameLength = 100
+)
+
+type AttachmentService struct {
+	attachmentDao *dao.AttachmentDao
+	storage       storage.Storage
+	// 上传与清理互斥，避免清理时删除刚被新上传的附件复用的文件
+	mu sync.Mutex
+}
+
+func NewAttachmentService(attachmentDao *dao.AttachmentDao, store storage.Storage) *AttachmentService {
+	return &AttachmentService{attachmentDao: attachmentDao, storage: store}
+}
+
+// MaxSize 单个附件的大小上限(字节)
+func (s *AttachmentService) MaxSize() int64 {
+	return int64(config.GetConfig(false).AttachmentMaxSizeMB) << 20
+}
+
+// Upload 识别文件类型并按内容摘要保存，相同内容的文件只保存一份
+func (s *AttachmentService) Upload(c context.Context, userID int, filename string, r io.Reader) (*model.Attachment, error) {
+	cfg := config.GetConfig(false)
+	maxSize := s.MaxSize()
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("附件大小不能超过%dMB", cfg.AttachmentMaxSizeMB)
+	}
+	if len(data) == 0 {
+		return nil, errors.New("附件内容为空")
+	}
+	// 以文件内容识别类型，不信任客户端提供的 Content-Type 与扩展名
+	mime := mimetype.Detect(data)
+	allowed := false
+	for _, t := range cfg.AttachmentAllowedTypes {
+		if mime.Is(t) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("不支持的附件类型 %s，仅支持 %s", mime.String(), strings.Join(cfg.AttachmentAllowedTypes, "、"))
+	}
+	sum := sha256.Sum256(data)
+	attachment := &model.Attachment{
+		Hash:     hex.EncodeToString(sum[:]),
+		Filename: cleanFilename(filename, mime.Extension()),
+		MimeType: strings.SplitN(mime.String(), ";", 2)[0],
+		Size:     int64(len(data)),
+		UserID:   userID,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.storage.Put(attachment.Hash, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("保存文件失败: %w", err)
+	}
+	if err := s.attachmentDao.CreateAttachment(c, attachment); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// cleanFilename 去掉路径与控制字符，截取过长的文件名，文件名为空时使用 attachment 加识别出的扩展名
+func cleanFilename(filename, ext string) string {
+	filename = filepath.Base(strings.ReplaceAll(filename, "\\", "/"))
+	filename = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, filename)
+	filename = strings.TrimSpace(filename)
+	if filename == "" || filename == "." || filename == "/" {
+		return "attachment" + ext
+	}
+	if utf8.RuneCountInString(filename) > maxFilenameLength {
+		runes := []rune(filename)
+		filename = string(runes[len(runes)-maxFilenameLength:])
+	}
+	return filename
+}
+
+func (s *AttachmentService) GetAttachment(c context.Context, attachmentID int) (*model.Attachment, error) {
+	return s.attachmentDao.GetAttachment(c, attachmentID)
+}
+
+// Open 读取附件文件
+func (s *AttachmentService) Open(attachment *model.Attachment) (io.ReadCloser, error) {
+	return s.storage.Open(attachment.Hash)
+}
+
+// CanDownload 上传者、可以查看全部题目的用户(管理员、审核员)，以及能看到引用该附件的题目的用户可以下载
+func (s *AttachmentService) CanDownload(c context.Context, userID int, canViewAll bool, attachment *model.Attachment) (bool, error) {
+	if canViewAll || attachment.UserID == userID {
+		return true, nil
+	}
+	return s.attachmentDao.IsReferencedByVisibleQuestion(c, attachment.ID, userID)
+}
+
+// DeleteAttachment 删除未被题目引用的附件，没有其他附件使用同一文件时一并删除文件
+func (s *AttachmentService) DeleteAttachment(c context.Context, attachment *model.Attachment) error {
+	referenced, err := s.attachmentDao.IsReferenced(c, attachment.ID)
+	if err != nil {
+		return err
+	}
+	if referenced {
+		return errors.New("附件已被题目引用，请先从题目中移除")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deleteAttachments(c, []model.Attachment{*attachment})
+}
+
+// deleteAttachments 删除附件记录以及不再被使用的文件，调用方需持有 mu
+func (s *AttachmentService) deleteAttachments(c context.Context, attachments []model.Attachment) error {
+	ids := make([]int, 0, len(attachments))
+	hashes := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		ids = append(ids, attachment.ID)
+		hashes = append(hashes, attachment.Hash)
+	}
+	if err := s.attachmentDao.DeleteAttachments(c, ids); err != nil {
+		return err
+	}
+	unused, err := s.attachmentDao.UnusedHashes(c, hashes)
+	if err != nil {
+		return err
+	}
+	for _, hash := range unused {
+		if err := s.storage.Delete(hash); err != nil {
+			return fmt.Errorf("删除文件失败: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *AttachmentService) ListQuestionAttachments(c context.Context, questionID int) ([]model.QuestionAttachment, error) {
+	return s.attachmentDao.ListQuestionAttachments(c, questionID)
+}
+
+// SetQuestionAttachments 整体设置题目引用的附件: 附件须由当前用户上传(管理员不限)或已被该题目引用，
+// 附在选项上时选项必须存在
+func (s *AttachmentService) SetQuestionAttachments(c context.Context, userID int, isAdmin bool, question *model.Question, req *dto.SetQuestionAttachmentsReq) error {
+	if len(req.Attachments) > maxQuestionAttachments {
+		return fmt.Errorf("单道题目最多引用%d个附件", maxQuestionAttachments)
+	}
+	var options []dto.Option
+	if err := json.Unmarshal([]byte(question.Options), &options); err != nil {
+		return errors.New("选项反序列化失败")
+	}
+	optionValues := map[int]struct{}{0: {}}
+	for _, opt := range options {
+		optionValues[opt.Value] = struct{}{}
+	}
+
+	current, err := s.attachmentDao.ListQuestionAttachments(c, question.ID)
+	if err != nil {
+		return err
+	}
+	linked := make(map[int]struct{}, len(current))
+	for _, link := range current {
+		linked[link.AttachmentID] = struct{}{}
+	}
+	ids := make([]int, 0, len(req.Attachments))
+	for _, item := range req.Attachments {
+		ids = append(ids, item.AttachmentID)
+	}
+	attachments, err := s.attachmentDao.GetAttachments(c, ids)
+	if err != nil {
+		return err
+	}
+	owners := make(map[int]int, len(attachments))
+	for _, attachment := range attachments {
+		owners[attachment.ID] = attachment.UserID
+	}
+
+	links := make([]model.QuestionAttachment, 0, len(req.Attachments))
+	positions := make(map[int]int)
+	seen := make(map[[2]int]struct{}, len(req.Attachments))
+	for _, item := range req.Attachments {
+		owner, ok := owners[item.AttachmentID]
+		if !ok {
+			return fmt.Errorf("附件 %d 不存在", item.AttachmentID)
+		}
+		if _, ok := linked[item.AttachmentID]; !ok && !isAdmin && owner != userID {
+			return fmt.Errorf("附件 %d 不是您上传的", item.AttachmentID)
+		}
+		if _, ok := optionValues[item.OptionValue]; !ok {
+			return fmt.Errorf("选项 %d 不存在", item.OptionValue)
+		}
+		// 同一位置重复引用同一附件时只保留一次
+		key := [2]int{item.AttachmentID, item.OptionValue}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		positions[item.OptionValue]++
+		links = append(links, model.QuestionAttachment{
+			QuestionID:   question.ID,
+			AttachmentID: item.AttachmentID,
+			OptionValue:  item.OptionValue,
+			Position:     positions[item.OptionValue],
+		})
+	}
+	return s.attachmentDao.ReplaceQuestionAttachments(c, question.ID, links)
+}
+
+// CollectGarbage 清理上传后超过保留时间仍未被任何题目引用的附件及其文件。
+// 回收站中的题目仍保留引用，题目被彻底删除后其附件才会被清理
+func (s *AttachmentService) CollectGarbage(c context.Context) error {
+	hours := config.GetConfig(false).AttachmentOrphanHours
+	if hours <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orphans, err := s.attachmentDao.ListOrphans(c, time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil || len(orphans) == 0 {
+		return err
+	}
+	if err := s.deleteAttachments(c, orphans); err != nil {
+		return err
+	}
+	log.Printf("附件清理完成: %d个未引用的附件", len(orphans))
+	return nil
+}
+
+// RunGCJob 定期清理未引用的附件，直到 ctx 结束
+func (s *AttachmentService) RunGCJob(ctx context.Context) {
+	ticker := time.NewTicker(attachmentGCInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.CollectGarbage(ctx); err != nil {
+			log.Printf("附件清理失败: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"aiquiz/config"
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils/codecheck"
+	"aiquiz/utils/coderun"
+	"aiquiz/utils/markdown"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxConcurrentRuns 同时编译运行的代码数量上限
+	maxConcurrentRuns = 2
+	// maxActualLength 提示信息中展示的实际输出最大长度(字符数)
+	maxActualLength = 200
+)
+
+var (
+	// outputQuestion 题干(代码块之外的文本)中询问运行结果的说法
+	outputQuestion = regexp.MustCompile(`(?i)输出|打印|运行结果|执行结果|output|print`)
+	// 选项中描述运行结果而非具体输出的说法
+	expectCompileError = regexp.MustCompile(`(?i)编译错误|编译失败|编译不通过|无法编译|不能编译|compil(e|ation) error|does not compile`)
+	expectDeadlock     = regexp.MustCompile(`(?i)死锁|deadlock`)
+	expectPanic        = regexp.MustCompile(`(?i)panic|运行时错误|runtime error`)
+	expectTimeout      = regexp.MustCompile(`(?i)死循环|无限循环|不会结束|永不结束|infinite loop`)
+	expectNoOutput     = regexp.MustCompile(`(?i)^(无输出|没有输出|不输出|什么都不输出|no output)[。.]?$`)
+)
+
+// CodeRunService 运行题目中的代码，核对“代码输出什么”类题目的答案，运行结果按代码摘要缓存
+type CodeRunService struct {
+	codeRunDao *dao.CodeRunDao
+	slots      chan struct{}
+}
+
+func NewCodeRunService(codeRunDao *dao.CodeRunDao) *CodeRunService {
+	return &CodeRunService{codeRunDao: codeRunDao, slots: make(chan struct{}, maxConcurrentRuns)}
+}
+
+// Enabled 是否开启了代码运行
+func (s *CodeRunService) Enabled() bool {
+	return config.GetConfig(false).CodeRunEnabled
+}
+
+// RunGo 补全并运行 Go 代码片段，相同代码直接返回缓存的结果。片段存在语法错误时作为编译错误返回，
+// 编译超时等不确定的情况返回 error 且不缓存
+func (s *CodeRunService) RunGo(c context.Context, code string) (*coderun.Result, error) {
+	source, err := codecheck.CompleteGo(code)
+	if err != nil {
+		return &coderun.Result{CompileError: err.Error()}, nil
+	}
+	sum := sha256.Sum256([]byte("go\x00" + source))
+	hash := hex.EncodeToString(sum[:])
+	cached, err := s.codeRunDao.GetCodeRun(c, hash)
+	if err == nil {
+		return &coderun.Result{
+			CompileError: cached.CompileError,
+			Stdout:       cached.Stdout,
+			Stderr:       cached.Stderr,
+			ExitCode:     cached.ExitCode,
+			TimedOut:     cached.TimedOut,
+		}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-c.Done():
+		return nil, c.Err()
+	}
+	cfg := config.GetConfig(false)
+	runner := &coderun.GoRunner{
+		GoBinary:     cfg.CodeRunGoBinary,
+		CacheDir:     cfg.CodeRunCacheDir,
+		BuildTimeout: time.Duration(cfg.CodeRunBuildTimeout) * time.Second,
+		RunTimeout:   time.Duration(cfg.CodeRunTimeout) * time.Second,
+		MemoryMB:     cfg.CodeRunMemoryMB,
+	}
+	result, err := runner.Run(c, source)
+	if err != nil {
+		return nil, err
+	}
+	// 缓存只用于加速，写入失败不影响本次结果
+	err = s.codeRunDao.SaveCodeRun(c, &model.CodeRun{
+		Hash:         hash,
+		CompileError: result.CompileError,
+		Stdout:       result.Stdout,
+		Stderr:       result.Stderr,
+		ExitCode:     result.ExitCode,
+		TimedOut:     result.TimedOut,
+	})
+	if err != nil {
+		log.Printf("保存代码运行结果失败: %v", err)
+	}
+	return result, nil
+}
+
+// VerifyOutput 核对输出类题目的答案: 题目语言为 Go、题干询问输出且只有一段代码、只有一个正确选项时，
+// 运行题干代码并与各选项比较。不满足条件的题目返回 Checked 为 false
+func (s *CodeRunService) VerifyOutput(c context.Context, language string, q dto.Question) (*dto.OutputCheckRes, error) {
+	tag := markdown.LanguageTag(language)
+	if tag != "go" || !outputQuestion.MatchString(markdown.StripCodeBlocks(q.Title)) {
+		return &dto.OutputCheckRes{}, nil
+	}
+	var code []string
+	for _, block := range markdown.CodeBlocks(q.Title) {
+		if block.Language == "" || markdown.LanguageTag(block.Language) == tag {
+			code = append(code, block.Code)
+		}
+	}
+	correct := -1
+	for i, opt := range q.Options {
+		if opt.Value != 0 && q.Answer&opt.Value == opt.Value {
+			if correct >= 0 {
+				return &dto.OutputCheckRes{}, nil
+			}
+			correct = i
+		}
+	}
+	if len(code) != 1 || correct < 0 {
+		return &dto.OutputCheckRes{}, nil
+	}
+
+	result, err := s.RunGo(c, code[0])
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.OutputCheckRes{Checked: true, Actual: describeResult(result)}
+	for i, opt := range q.Options {
+		if matchesOption(result, opt.Content) {
+			if i == correct {
+				res.Matched = true
+				res.MatchedOption = optionLabel(i)
+				break
+			}
+			if res.MatchedOption == "" {
+				res.MatchedOption = optionLabel(i)
+			}
+		}
+	}
+	if !res.Matched {
+		res.Message = fmt.Sprintf("运行结果为%s，与正确选项%s不一致", res.Actual, optionLabel(correct))
+		if res.MatchedOption != "" {
+			res.Message += "，与选项" + res.MatchedOption + "一致"
+		} else {
+			res.Message += "，也没有与之一致的选项"
+		}
+	}
+	return res, nil
+}
+
+func optionLabel(i int) string {
+	return string(rune('A' + i))
+}
+
+// describeResult 描述运行结果，输出过长时截断
+func describeResult(result *coderun.Result) string {
+	switch {
+	case result.CompileError != "":
+		return "编译失败(" + firstLine(result.CompileError) + ")"
+	case result.TimedOut:
+		return "运行超时"
+	case isDeadlock(result):
+		return "死锁"
+	case isPanic(result):
+		return "panic(" + strings.TrimPrefix(firstLine(result.Stderr), "panic: ") + ")"
+	}
+	output := normalizeOutput(result.Stdout)
+	if output == "" {
+		return "无输出"
+	}
+	if utf8.RuneCountInString(output) > maxActualLength {
+		output = string([]rune(output)[:maxActualLength]) + "..."
+	}
+	return fmt.Sprintf("%q", output)
+}
+
+// matchesOption 判断选项是否描述了该运行结果。选项中的代码块或行内代码视为具体输出，
+// 否则先识别“编译错误”“panic”等说法，再与输出比较(忽略行尾空白，仍不一致时忽略所有空白差异)
+func matchesOption(result *coderun.Result, content string) bool {
+	expected, literal := optionOutput(content)
+	if !literal {
+		switch {
+		case expectCompileError.MatchString(expected):
+			return result.CompileError != ""
+		case expectDeadlock.MatchString(expected):
+			return isDeadlock(result)
+		case expectPanic.MatchString(expected):
+			return isPanic(result)
+		case expectTimeout.MatchString(expected):
+			return result.TimedOut
+		case expectNoOutput.MatchString(expected):
+			return result.CompileError == "" && !result.TimedOut && result.ExitCode == 0 && normalizeOutput(result.Stdout) == ""
+		}
+	}
+	if result.CompileError != "" || result.TimedOut || result.ExitCode != 0 {
+		return false
+	}
+	actual := normalizeOutput(result.Stdout)
+	expected = normalizeOutput(expected)
+	return actual == expected || strings.Join(strings.Fields(actual), " ") == strings.Join(strings.Fields(expected), " ")
+}
+
+// optionOutput 提取选项表示的输出，literal 表示内容来自代码块或整段行内代码
+func optionOutput(content string) (string, bool) {
+	if blocks := markdown.CodeBlocks(content); len(blocks) > 0 {
+		return blocks[0].Code, true
+	}
+	content = strings.TrimSpace(content)
+	if len(content) >= 2 && strings.HasPrefix(content, "`") && strings.HasSuffix(content, "`") {
+		if inner := strings.Trim(content, "`"); !strings.Contains(inner, "`") {
+			return strings.TrimSpace(inner), true
+		}
+	}
+	return content, false
+}
+
+// normalizeOutput 统一换行符，去掉行尾空白与末尾空行
+func normalizeOutput(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func isDeadlock(result *coderun.Result) bool {
+	return result.ExitCode != 0 && strings.Contains(result.Stderr, "deadlock!")
+}
+
+func isPanic(result *coderun.Result) bool {
+	return result.ExitCode != 0 && strings.Contains(result.Stderr, "panic: ")
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
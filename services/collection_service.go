@@ -0,0 +1,223 @@
+package services
+
+import (
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"sort"
+)
+
+type CollectionService struct {
+	collectionDao *dao.CollectionDao
+	questionDao   *dao.QuestionDao
+}
+
+func NewCollectionService(collectionDao *dao.CollectionDao, questionDao *dao.QuestionDao) *CollectionService {
+	return &CollectionService{collectionDao: collectionDao, questionDao: questionDao}
+}
+
+// CreateCollection 创建集合，上级集合须属于同一用户
+func (s *CollectionService) CreateCollection(c context.Context, userID int, req *dto.CollectionReq) (*model.Collection, error) {
+	collection := &model.Collection{
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+		UserID:      userID,
+	}
+	if err := s.checkParent(c, collection); err != nil {
+		return nil, err
+	}
+	if err := s.collectionDao.CreateCollection(c, collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+func (s *CollectionService) GetCollection(c context.Context, collectionID int) (*model.Collection, error) {
+	return s.collectionDao.GetCollection(c, collectionID)
+}
+
+// UpdateCollection 修改集合的名称、描述与上级集合，不能移动到自身或下级集合之下
+func (s *CollectionService) UpdateCollection(c context.Context, collection *model.Collection, req *dto.CollectionReq) error {
+	updated := *collection
+	updated.Name = req.Name
+	updated.Description = req.Description
+	updated.ParentID = req.ParentID
+	if updated.ParentID != 0 && updated.ParentID != collection.ParentID {
+		subtree, err := s.collectionDao.SubtreeIDs(c, collection.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range subtree {
+			if id == updated.ParentID {
+				return errors.New("不能移动到自身或下级集合之下")
+			}
+		}
+	}
+	if err := s.checkParent(c, &updated); err != nil {
+		return err
+	}
+	return s.collectionDao.UpdateCollection(c, &updated)
+}
+
+// checkParent 校验上级集合存在且与集合属于同一用户
+func (s *CollectionService) checkParent(c context.Context, collection *model.Collection) error {
+	if collection.ParentID == 0 {
+		return nil
+	}
+	parent, err := s.collectionDao.GetCollection(c, collection.ParentID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err != nil || parent.UserID != collection.UserID {
+		return errors.New("上级集合不存在")
+	}
+	return nil
+}
+
+// DeleteCollection 删除集合及其全部下级集合，集合中的题目不受影响
+func (s *CollectionService) DeleteCollection(c context.Context, collectionID int) error {
+	ids, err := s.collectionDao.SubtreeIDs(c, collectionID)
+	if err != nil {
+		return err
+	}
+	return s.collectionDao.DeleteCollections(c, ids)
+}
+
+// ListCollectionTree 以树形结构返回用户的全部集合及各集合中的题目数量
+func (s *CollectionService) ListCollectionTree(c context.Context, userID int) ([]dto.CollectionRes, error) {
+	collections, err := s.collectionDao.ListCollections(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(collections) == 0 {
+		return []dto.CollectionRes{}, nil
+	}
+	ids := make([]int, 0, len(collections))
+	for _, collection := range collections {
+		ids = append(ids, collection.ID)
+	}
+	counts, err := s.collectionDao.CountQuestions(c, ids)
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[int][]model.Collection, len(collections))
+	exists := make(map[int]struct{}, len(collections))
+	for _, collection := range collections {
+		exists[collection.ID] = struct{}{}
+	}
+	for _, collection := range collections {
+		parentID := collection.ParentID
+		// 上级集合已不存在时作为顶层集合展示
+		if _, ok := exists[parentID]; !ok {
+			parentID = 0
+		}
+		children[parentID] = append(children[parentID], collection)
+	}
+	var build func(parentID int) []dto.CollectionRes
+	build = func(parentID int) []dto.CollectionRes {
+		nodes := make([]dto.CollectionRes, 0, len(children[parentID]))
+		for _, collection := range children[parentID] {
+			res := ToCollectionRes(&collection, counts[collection.ID])
+			res.Children = build(collection.ID)
+			nodes = append(nodes, res)
+		}
+		sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+		return nodes
+	}
+	return build(0), nil
+}
+
+// GetCollectionTree 返回集合及其下级集合的树形结构
+func (s *CollectionService) GetCollectionTree(c context.Context, collection *model.Collection) (*dto.CollectionRes, error) {
+	tree, err := s.ListCollectionTree(c, collection.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if node := findCollection(tree, collection.ID); node != nil {
+		return node, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func findCollection(nodes []dto.CollectionRes, collectionID int) *dto.CollectionRes {
+	for i := range nodes {
+		if nodes[i].ID == collectionID {
+			return &nodes[i]
+		}
+		if node := findCollection(nodes[i].Children, collectionID); node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+// ToCollectionRes 将集合转换为返回结构体
+func ToCollectionRes(collection *model.Collection, questionCount int) dto.CollectionRes {
+	return dto.CollectionRes{
+		ID:            collection.ID,
+		Name:          collection.Name,
+		Description:   collection.Description,
+		ParentID:      collection.ParentID,
+		QuestionCount: questionCount,
+		CreatedAt:     collection.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     collection.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Children:      []dto.CollectionRes{},
+	}
+}
+
+// AddQuestions 将题目追加到集合末尾，只能加入集合所有者的题目或共享题目
+func (s *CollectionService) AddQuestions(c context.Context, collection *model.Collection, questionIDs []int) (int, error) {
+	existing, err := s.questionDao.GetExistingQuestionIDs(c, collection.UserID, questionIDs)
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) != len(uniqueIDs(questionIDs)) {
+		return 0, errors.New("某些题目不存在")
+	}
+	added := 0
+	err = s.collectionDao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		added, err = s.collectionDao.AddQuestions(c, tx, collection.ID, questionIDs)
+		return err
+	})
+	return added, err
+}
+
+// RemoveQuestion 将题目移出集合，返回 false 表示题目不在集合中
+func (s *CollectionService) RemoveQuestion(c context.Context, collectionID, questionID int) (bool, error) {
+	removed, err := s.collectionDao.RemoveQuestions(c, s.collectionDao.DB, collectionID, []int{questionID})
+	return removed > 0, err
+}
+
+// ReorderQuestions 调整集合中题目的顺序，列出的题目须都在集合中
+func (s *CollectionService) ReorderQuestions(c context.Context, collectionID int, questionIDs []int) error {
+	unique := uniqueIDs(questionIDs)
+	if len(unique) != len(questionIDs) {
+		return errors.New("存在重复的题目ID")
+	}
+	in, err := s.collectionDao.GetQuestionIDsIn(c, collectionID, questionIDs)
+	if err != nil {
+		return err
+	}
+	if len(in) != len(questionIDs) {
+		return errors.New("某些题目不在集合中")
+	}
+	return s.collectionDao.ReorderQuestions(c, collectionID, questionIDs)
+}
+
+// uniqueIDs 按原顺序去重
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]struct{}, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"aiquiz/config"
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils"
+	"context"
+	"errors"
+	"math"
+	"sort"
+)
+
+// maxDuplicatesPerQuestion 每道题目最多返回的可能重复题目数量
+const maxDuplicatesPerQuestion = 5
+
+type DuplicateService struct {
+	duplicateDao *dao.DuplicateDao
+	questionDao  *dao.QuestionDao
+}
+
+func NewDuplicateService(duplicateDao *dao.DuplicateDao, questionDao *dao.QuestionDao) *DuplicateService {
+	return &DuplicateService{duplicateDao: duplicateDao, questionDao: questionDao}
+}
+
+// DuplicateCandidate 待检查是否重复的题目，ID 为 0 表示尚未入库
+type DuplicateCandidate struct {
+	ID       int
+	Language string
+	Title    string
+	Options  []string
+}
+
+// NewDuplicateCandidate 由题目内容构造待检查的题目
+func NewDuplicateCandidate(id int, language, title string, options []dto.Option) DuplicateCandidate {
+	contents := make([]string, 0, len(options))
+	for _, opt := range options {
+		contents = append(contents, opt.Content)
+	}
+	return DuplicateCandidate{ID: id, Language: language, Title: title, Options: contents}
+}
+
+type duplicateMatch struct {
+	questionID int
+	distance   int
+}
+
+// similarity 汉明距离换算的相似度，保留两位小数
+func similarity(distance int) float64 {
+	return math.Round((1-float64(distance)/64)*100) / 100
+}
+
+// FindDuplicates 在用户可见的题目(自己的题目与共享题目)中查找与各题目相同语言、可能重复的题目，
+// 按相似度从高到低排列，结果与 candidates 按下标对应
+func (s *DuplicateService) FindDuplicates(c context.Context, userID int, candidates []DuplicateCandidate) ([][]dto.DuplicateRes, error) {
+	maxDistance := config.GetConfig(false).DuplicateMaxDistance
+	fingerprints := make(map[string][]model.QuestionFingerprint)
+	matches := make([][]duplicateMatch, len(candidates))
+	var ids []int
+	for i, candidate := range candidates {
+		list, ok := fingerprints[candidate.Language]
+		if !ok {
+			var err error
+			if list, err = s.duplicateDao.ListFingerprints(c, candidate.Language, userID); err != nil {
+				return nil, err
+			}
+			fingerprints[candidate.Language] = list
+		}
+		hash := utils.QuestionFingerprint(candidate.Title, candidate.Options)
+		for _, fp := range list {
+			if fp.QuestionID == candidate.ID {
+				continue
+			}
+			if distance := utils.HammingDistance(hash, uint64(fp.SimHash)); distance <= maxDistance {
+				matches[i] = append(matches[i], duplicateMatch{questionID: fp.QuestionID, distance: distance})
+			}
+		}
+		sort.SliceStable(matches[i], func(a, b int) bool { return matches[i][a].distance < matches[i][b].distance })
+		if len(matches[i]) > maxDuplicatesPerQuestion {
+			matches[i] = matches[i][:maxDuplicatesPerQuestion]
+		}
+		for _, m := range matches[i] {
+			ids = append(ids, m.questionID)
+		}
+	}
+
+	result := make([][]dto.DuplicateRes, len(candidates))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	questions, err := s.questionDao.GetQuestionsByIDs(c, ids)
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[int]string, len(questions))
+	for _, q := range questions {
+		titles[q.ID] = q.Title
+	}
+	for i := range matches {
+		for _, m := range matches[i] {
+			result[i] = append(result[i], dto.DuplicateRes{ID: m.questionID, Title: titles[m.questionID], Similarity: similarity(m.distance)})
+		}
+	}
+	return result, nil
+}
+
+// ListClusters 将全部题目中相同语言、相互相似的题目分组(相似关系可传递)，只返回两道题目以上的分组，
+// 题目多的分组在前。指纹按段分桶，只比较同一语言中至少一段相同的指纹，language 为空时处理全部语言
+func (s *DuplicateService) ListClusters(c context.Context, language string) ([]dto.DuplicateClusterRes, error) {
+	maxDistance := config.GetConfig(false).DuplicateMaxDistance
+	fingerprints, err := s.duplicateDao.ListFingerprints(c, language, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// 并查集，同时记录每组内直接相似的题目间最大的距离
+	parent := make([]int, len(fingerprints))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	worst := make(map[int]int)
+	union := func(i, j int) {
+		distance := utils.HammingDistance(uint64(fingerprints[i].SimHash), uint64(fingerprints[j].SimHash))
+		if distance > maxDistance {
+			return
+		}
+		a, b := find(i), find(j)
+		if a != b {
+			parent[b] = a
+			worst[a] = max(worst[a], worst[b])
+		}
+		worst[a] = max(worst[a], distance)
+	}
+
+	// 同一段相同的指纹放入同一个桶，两道题目有多段相同时会重复比较，结果不受影响
+	type bucketKey struct {
+		language string
+		band     int
+		value    uint64
+	}
+	buckets := make(map[bucketKey][]int)
+	for i, fp := range fingerprints {
+		for band, value := range utils.SimHashBands(uint64(fp.SimHash), maxDistance) {
+			key := bucketKey{language: fp.Language, band: band, value: value}
+			for _, j := range buckets[key] {
+				union(j, i)
+			}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+	groups := make(map[int][]int)
+	var ids []int
+	for i := range fingerprints {
+		root := find(i)
+		groups[root] = append(groups[root], fingerprints[i].QuestionID)
+	}
+	for root, members := range groups {
+		if len(members) < 2 {
+			delete(groups, root)
+			continue
+		}
+		ids = append(ids, members...)
+	}
+	if len(ids) == 0 {
+		return []dto.DuplicateClusterRes{}, nil
+	}
+
+	questions, err := s.questionDao.GetQuestionsByIDs(c, ids)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.duplicateDao.CountPaperUsage(c, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]model.Question, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+	clusters := make([]dto.DuplicateClusterRes, 0, len(groups))
+	for root, members := range groups {
+		cluster := dto.DuplicateClusterRes{MinSimilarity: similarity(worst[root])}
+		for _, id := range members {
+			q, ok := byID[id]
+			if !ok {
+				continue
+			}
+			cluster.Questions = append(cluster.Questions, dto.DuplicateQuestionRes{
+				ID:         q.ID,
+				Title:      q.Title,
+				Language:   q.Language,
+				UserID:     q.UserID,
+				Status:     q.Status,
+				PaperCount: usage[q.ID],
+				CreatedAt:  q.CreatedAt.Format("2006-01-02 15:04:05"),
+			})
+		}
+		// 建议保留被试卷使用最多的题目，其次为最早创建的题目
+		sort.SliceStable(cluster.Questions, func(i, j int) bool {
+			a, b := cluster.Questions[i], cluster.Questions[j]
+			if a.PaperCount != b.PaperCount {
+				return a.PaperCount > b.PaperCount
+			}
+			return a.ID < b.ID
+		})
+		if len(cluster.Questions) >= 2 {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Questions) != len(clusters[j].Questions) {
+			return len(clusters[i].Questions) > len(clusters[j].Questions)
+		}
+		return clusters[i].Questions[0].ID < clusters[j].Questions[0].ID
+	})
+	return clusters, nil
+}
+
+// MergeQuestions 合并重复题目，保留的题目不存在时返回 gorm.ErrRecordNotFound
+func (s *DuplicateService) MergeQuestions(c context.Context, req *dto.MergeQuestionsReq) (*dto.MergeQuestionsRes, error) {
+	if _, err := s.questionDao.GetQuestion(c, req.SurvivorID); err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool, len(req.DuplicateIDs))
+	ids := make([]int, 0, len(req.DuplicateIDs))
+	for _, id := range req.DuplicateIDs {
+		if id == req.SurvivorID {
+			return nil, errors.New("保留的题目不能同时作为重复题目")
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("请提供重复题目")
+	}
+	duplicates, err := s.questionDao.GetQuestionsByIDs(c, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(duplicates) != len(ids) {
+		return nil, errors.New("部分重复题目不存在或已删除")
+	}
+	repointed, err := s.duplicateDao.MergeQuestions(c, req.SurvivorID, ids)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MergeQuestionsRes{Merged: len(ids), RepointedPapers: repointed}, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"aiquiz/ai"
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils/enums"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+type ExperimentService struct {
+	experimentDao *dao.ExperimentDao
+}
+
+func NewExperimentService(experimentDao *dao.ExperimentDao) *ExperimentService {
+	return &ExperimentService{experimentDao: experimentDao}
+}
+
+func (s *ExperimentService) CreateExperiment(c context.Context, req *dto.CreateExperimentReq) error {
+	experiment := &model.Experiment{
+		Name:           req.Name,
+		Description:    req.Description,
+		VariantPrompt:  req.VariantPrompt,
+		VariantModel:   req.VariantModel,
+		TrafficPercent: req.TrafficPercent,
+		Active:         req.Active,
+	}
+	if err := validateExperiment(experiment); err != nil {
+		return err
+	}
+	return s.experimentDao.CreateExperiment(c, experiment)
+}
+
+func (s *ExperimentService) ListExperiments(c context.Context) ([]model.Experiment, error) {
+	return s.experimentDao.ListExperiments(c)
+}
+
+func (s *ExperimentService) UpdateExperiment(c context.Context, experimentID int, req *dto.UpdateExperimentReq) error {
+	experiment, err := s.experimentDao.GetExperiment(c, experimentID)
+	if err != nil {
+		return fmt.Errorf("实验不存在: %w", err)
+	}
+	fields := make(map[string]interface{})
+	if req.Name != nil {
+		experiment.Name = *req.Name
+		fields["name"] = *req.Name
+	}
+	if req.Description != nil {
+		fields["description"] = *req.Description
+	}
+	if req.VariantPrompt != nil {
+		experiment.VariantPrompt = *req.VariantPrompt
+		fields["variant_prompt"] = *req.VariantPrompt
+	}
+	if req.VariantModel != nil {
+		experiment.VariantModel = *req.VariantModel
+		fields["variant_model"] = *req.VariantModel
+	}
+	if req.TrafficPercent != nil {
+		experiment.TrafficPercent = *req.TrafficPercent
+		fields["traffic_percent"] = *req.TrafficPercent
+	}
+	if req.Active != nil {
+		fields["active"] = *req.Active
+	}
+	if len(fields) == 0 {
+		return errors.New("没有需要更新的字段")
+	}
+	if err := validateExperiment(experiment); err != nil {
+		return err
+	}
+	return s.experimentDao.UpdateExperiment(c, experimentID, fields)
+}
+
+func (s *ExperimentService) DeleteExperiment(c context.Context, experimentID int) error {
+	return s.experimentDao.DeleteExperiment(c, experimentID)
+}
+
+func validateExperiment(experiment *model.Experiment) error {
+	if experiment.Name == "" {
+		return errors.New("实验名称不能为空")
+	}
+	if experiment.TrafficPercent < 0 || experiment.TrafficPercent > 100 {
+		return errors.New("实验流量比例必须在0到100之间")
+	}
+	if experiment.VariantPrompt == "" && experiment.VariantModel == "" {
+		return errors.New("实验组的提示词模板与模型至少需要设置一个")
+	}
+	if experiment.VariantPrompt != "" && !ai.IsSupportedPromptTemplate(experiment.VariantPrompt) {
+		return errors.New("无效的提示词模板")
+	}
+	if experiment.VariantModel != "" && !enums.IsSupportedAiModel(enums.AiModel(experiment.VariantModel)) {
+		return errors.New("无效的AI模型")
+	}
+	return nil
+}
+
+// assignVariant 按用户确定性分组，同一用户在同一实验中始终落在同一组。
+// 只使用实验ID与用户ID计算分组，实验改名不会改变已有用户的分组
+func assignVariant(experiment *model.Experiment, userID int) enums.ExperimentVariant {
+	h := fnv.New32a()
+	_, _ = fmt.Fprintf(h, "%d:%d", experiment.ID, userID)
+	if int(h.Sum32()%100) < experiment.TrafficPercent {
+		return enums.VariantTreatment
+	}
+	return enums.VariantControl
+}
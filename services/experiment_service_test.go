@@ -0,0 +1,144 @@
+package services
+
+import (
+	"aiquiz/ai"
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils/enums"
+	"context"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"testing"
+)
+
+func newExperimentService(t *testing.T) (*ExperimentService, *dao.ExperimentDao) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 内存数据库每个连接各自独立，只使用一个连接
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&model.Experiment{}); err != nil {
+		t.Fatal(err)
+	}
+	experimentDao := dao.NewExperimentDAO(db)
+	return NewExperimentService(experimentDao), experimentDao
+}
+
+func TestAssignVariantStableAcrossRename(t *testing.T) {
+	c := context.Background()
+	service, experimentDao := newExperimentService(t)
+	err := service.CreateExperiment(c, &dto.CreateExperimentReq{
+		Name:           "结构化提示词",
+		VariantPrompt:  ai.PromptTemplateStructured,
+		TrafficPercent: 50,
+		Active:         true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	experiments, err := service.ListExperiments(c)
+	if err != nil || len(experiments) != 1 {
+		t.Fatalf("获取实验失败: %v", err)
+	}
+	experimentID := experiments[0].ID
+
+	before := make(map[int]enums.ExperimentVariant)
+	treatment := 0
+	for userID := 1; userID <= 200; userID++ {
+		before[userID] = assignVariant(&experiments[0], userID)
+		if before[userID] == enums.VariantTreatment {
+			treatment++
+		}
+	}
+	if treatment == 0 || treatment == len(before) {
+		t.Fatalf("50%%流量时两组都应有用户，实验组 %d/%d", treatment, len(before))
+	}
+
+	name := "结构化提示词(第二轮)"
+	if err := service.UpdateExperiment(c, experimentID, &dto.UpdateExperimentReq{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := experimentDao.GetExperiment(c, experimentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Name != name {
+		t.Fatalf("实验名称未更新: %s", renamed.Name)
+	}
+	for userID, variant := range before {
+		if got := assignVariant(renamed, userID); got != variant {
+			t.Errorf("用户 %d 在实验改名后从 %s 变为 %s", userID, variant, got)
+		}
+	}
+}
+
+func TestOnlyOneActiveExperiment(t *testing.T) {
+	c := context.Background()
+	service, experimentDao := newExperimentService(t)
+	for _, name := range []string{"实验A", "实验B"} {
+		err := service.CreateExperiment(c, &dto.CreateExperimentReq{
+			Name:           name,
+			VariantPrompt:  ai.PromptTemplateStructured,
+			TrafficPercent: 50,
+			Active:         true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	activeName := func() string {
+		t.Helper()
+		experiment, err := experimentDao.GetActiveExperiment(c)
+		if err != nil || experiment == nil {
+			t.Fatalf("获取生效的实验失败: %v", err)
+		}
+		experiments, err := service.ListExperiments(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range experiments {
+			if e.Active && e.ID != experiment.ID {
+				t.Fatalf("%s 与 %s 同时处于开启状态", e.Name, experiment.Name)
+			}
+		}
+		return experiment.Name
+	}
+	if got := activeName(); got != "实验B" {
+		t.Fatalf("开启新实验后应由其生效，实际为 %s", got)
+	}
+
+	// 修改已关闭实验的描述不影响生效的实验
+	experiments, err := service.ListExperiments(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var idA int
+	for _, e := range experiments {
+		if e.Name == "实验A" {
+			idA = e.ID
+		}
+	}
+	description := "补充说明"
+	if err := service.UpdateExperiment(c, idA, &dto.UpdateExperimentReq{Description: &description}); err != nil {
+		t.Fatal(err)
+	}
+	if got := activeName(); got != "实验B" {
+		t.Fatalf("修改描述后生效的实验不应变化，实际为 %s", got)
+	}
+
+	active := true
+	if err := service.UpdateExperiment(c, idA, &dto.UpdateExperimentReq{Active: &active}); err != nil {
+		t.Fatal(err)
+	}
+	if got := activeName(); got != "实验A" {
+		t.Fatalf("重新开启实验A后应由其生效，实际为 %s", got)
+	}
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils"
+	"aiquiz/utils/quizformat"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxExportQuestions 单次最多导出的题目数量
+const maxExportQuestions = 2000
+
+type ExportService struct {
+	questionService *QuestionService
+	questionDao     *dao.QuestionDao
+	paperDao        *dao.PaperDao
+}
+
+func NewExportService(questionService *QuestionService, questionDao *dao.QuestionDao, paperDao *dao.PaperDao) *ExportService {
+	return &ExportService{questionService: questionService, questionDao: questionDao, paperDao: paperDao}
+}
+
+// ExportFile 导出的文件内容
+type ExportFile struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	Inline      bool // 浏览器中直接打开而不是下载
+}
+
+// CheckExportFormat 校验导出格式，目前支持 Moodle XML、GIFT、QTI 内容包(2.1/3.0)与可打印的 HTML
+func (s *ExportService) CheckExportFormat(format, qtiVersion string) error {
+	if format != FormatMoodleXML && format != FormatGIFT && format != FormatQTI && format != FormatHTML {
+		return errors.New("不支持的导出格式，仅支持 moodle_xml、gift、qti、html")
+	}
+	if format == FormatQTI && qtiVersion != "" && !quizformat.IsSupportedQTIVersion(qtiVersion) {
+		return errors.New("不支持的QTI版本，仅支持 2.1、3.0")
+	}
+	return nil
+}
+
+// ExportQuestions 按筛选条件导出题目，userID 为 0 时导出全部用户的题目
+func (s *ExportService) ExportQuestions(c context.Context, userID int, req *dto.ExportQuestionsReq) (*ExportFile, error) {
+	filter, ok, err := s.questionService.buildFilter(c, userID, &req.ListQuestionsReq)
+	if err != nil {
+		return nil, err
+	}
+	var questions []model.Question
+	if ok {
+		ids, err := s.questionDao.ListQuestionIDs(c, filter, maxExportQuestions+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > maxExportQuestions {
+			return nil, fmt.Errorf("单次最多导出%d道题目，请缩小筛选范围", maxExportQuestions)
+		}
+		if len(ids) > 0 {
+			if questions, err = s.questionDao.GetQuestionsByIDs(c, ids); err != nil {
+				return nil, err
+			}
+			sort.Slice(questions, func(i, j int) bool { return questions[i].ID < questions[j].ID })
+		}
+	}
+	items := make([]quizformat.Question, 0, len(questions))
+	for _, q := range questions {
+		tags := make([]string, 0, len(q.Tags))
+		for _, tag := range q.Tags {
+			tags = append(tags, tag.DisplayName)
+		}
+		item, err := toQuizQuestion(q, tags)
+		if err != nil {
+			return nil, fmt.Errorf("题目 %d: %w", q.ID, err)
+		}
+		items = append(items, item)
+	}
+	// 同一分类的题目放在一起，避免重复输出分类
+	sort.SliceStable(items, func(i, j int) bool {
+		return strings.Join(items[i].Category, "/") < strings.Join(items[j].Category, "/")
+	})
+	return writeQuizFile("questions", "题目导出", req.Format, req.QTIVersion, req.Answers, items)
+}
+
+// ExportPaper 按试卷中的顺序导出题目，固定了版本的题目使用该版本的内容，QTI 格式同时保留各题分值
+func (s *ExportService) ExportPaper(c context.Context, paperID int, req *dto.ExportPaperReq) (*ExportFile, error) {
+	paper, err := s.paperDao.GetPaper(c, paperID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(paper.Questions, func(i, j int) bool {
+		return paper.Questions[i].QuestionOrder < paper.Questions[j].QuestionOrder
+	})
+	questionIDs := make([]int, 0, len(paper.Questions))
+	for _, pq := range paper.Questions {
+		if pq.Question != nil {
+			questionIDs = append(questionIDs, pq.QuestionID)
+		}
+	}
+	// 当前版本的标签需要单独加载
+	questionTags := make(map[int][]string, len(questionIDs))
+	if len(questionIDs) > 0 {
+		questions, err := s.questionDao.GetQuestionsByIDs(c, questionIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range questions {
+			for _, tag := range q.Tags {
+				questionTags[q.ID] = append(questionTags[q.ID], tag.DisplayName)
+			}
+		}
+	}
+
+	items := make([]quizformat.Question, 0, len(paper.Questions))
+	for _, pq := range paper.Questions {
+		q := pq.Question
+		if q == nil {
+			continue
+		}
+		tags := questionTags[q.ID]
+		if r := pq.Revision; r != nil {
+			if tags, err = RevisionTags(r); err != nil {
+				return nil, err
+			}
+			q = &model.Question{
+				ID:           q.ID,
+				Title:        r.Title,
+				QuestionType: r.QuestionType,
+				Options:      r.Options,
+				Answer:       r.Answer,
+				Explanation:  r.Explanation,
+				Keywords:     r.Keywords,
+			}
+		}
+		item, err := toQuizQuestion(*q, tags)
+		if err != nil {
+			return nil, fmt.Errorf("题目 %d: %w", q.ID, err)
+		}
+		item.Score = pq.Score
+		items = append(items, item)
+	}
+	return writeQuizFile(fmt.Sprintf("paper-%d", paperID), paper.Title, req.Format, req.QTIVersion, req.Answers, items)
+}
+
+// toQuizQuestion 转换为交换格式，关键词作为分类路径，与关键词重复的标签不再单独输出
+func toQuizQuestion(q model.Question, tags []string) (quizformat.Question, error) {
+	var options []dto.Option
+	if err := json.Unmarshal([]byte(q.Options), &options); err != nil {
+		return quizformat.Question{}, errors.New("选项反序列化失败")
+	}
+	answer, err := strconv.Atoi(q.Answer)
+	if err != nil {
+		return quizformat.Question{}, errors.New("答案转换失败")
+	}
+	item := quizformat.Question{
+		Title:       q.Title,
+		Single:      q.QuestionType == "single",
+		Explanation: q.Explanation,
+		Category:    utils.SplitKeywords(q.Keywords),
+	}
+	for i, opt := range options {
+		item.Options = append(item.Options, opt.Content)
+		if answer&opt.Value != 0 {
+			item.Answer |= 1 << i
+		}
+	}
+	categories := make(map[string]struct{}, len(item.Category))
+	for _, segment := range item.Category {
+		categories[utils.NormalizeTag(segment)] = struct{}{}
+	}
+	for _, tag := range tags {
+		if _, ok := categories[utils.NormalizeTag(tag)]; !ok {
+			item.Tags = append(item.Tags, tag)
+		}
+	}
+	return item, nil
+}
+
+// writeQuizFile 生成导出文件，title 为 QTI 试卷与 HTML 文档的标题，answers 控制 HTML 文档是否包含答案与解析
+func writeQuizFile(name, title, format, qtiVersion string, answers bool, questions []quizformat.Question) (*ExportFile, error) {
+	var buf bytes.Buffer
+	file := &ExportFile{}
+	switch format {
+	case FormatMoodleXML:
+		if err := quizformat.WriteMoodleXML(&buf, questions); err != nil {
+			return nil, err
+		}
+		file.Filename = name + ".xml"
+		file.ContentType = "application/xml; charset=utf-8"
+	case FormatGIFT:
+		if err := quizformat.WriteGIFT(&buf, questions); err != nil {
+			return nil, err
+		}
+		file.Filename = name + ".gift"
+		file.ContentType = "text/plain; charset=utf-8"
+	case FormatQTI:
+		if qtiVersion == "" {
+			qtiVersion = quizformat.QTIVersion21
+		}
+		if err := quizformat.WriteQTI(&buf, title, questions, qtiVersion); err != nil {
+			return nil, err
+		}
+		file.Filename = name + ".zip"
+		file.ContentType = "application/zip"
+	case FormatHTML:
+		if err := quizformat.WriteHTML(&buf, title, questions, answers); err != nil {
+			return nil, err
+		}
+		file.Filename = name + ".html"
+		file.ContentType = "text/html; charset=utf-8"
+		file.Inline = true
+	default:
+		return nil, errors.New("不支持的导出格式")
+	}
+	file.Data = buf.Bytes()
+	return file, nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils/enums"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+)
+
+type FeedbackService struct {
+	feedbackDao *dao.FeedbackDao
+}
+
+func NewFeedbackService(feedbackDao *dao.FeedbackDao) *FeedbackService {
+	return &FeedbackService{feedbackDao: feedbackDao}
+}
+
+// AddComment 发表评论，回复评论串中的回复时挂到该评论串下
+func (s *FeedbackService) AddComment(c context.Context, userID, questionID int, req *dto.AddCommentReq) (*model.QuestionComment, error) {
+	comment := &model.QuestionComment{
+		QuestionID: questionID,
+		UserID:     userID,
+		Content:    req.Content,
+		Status:     string(enums.FeedbackOpen),
+	}
+	if req.ParentID != 0 {
+		parent, err := s.feedbackDao.GetComment(c, questionID, req.ParentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("回复的评论不存在")
+			}
+			return nil, err
+		}
+		comment.ParentID = parent.ID
+		if parent.ParentID != 0 {
+			comment.ParentID = parent.ParentID
+		}
+	}
+	if err := s.feedbackDao.AddComment(c, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *FeedbackService) GetComment(c context.Context, questionID, commentID int) (*model.QuestionComment, error) {
+	return s.feedbackDao.GetComment(c, questionID, commentID)
+}
+
+// ListComments 获取题目的全部评论，按时间顺序
+func (s *FeedbackService) ListComments(c context.Context, questionID int) ([]model.QuestionComment, error) {
+	return s.feedbackDao.ListComments(c, questionID)
+}
+
+func (s *FeedbackService) DeleteComment(c context.Context, comment *model.QuestionComment) error {
+	return s.feedbackDao.DeleteComment(c, comment)
+}
+
+// UpdateCommentStatus 修改评论串的状态，回复没有单独的状态
+func (s *FeedbackService) UpdateCommentStatus(c context.Context, userID int, comment *model.QuestionComment, status enums.FeedbackStatus) error {
+	if comment.ParentID != 0 {
+		return errors.New("只能修改评论串的状态")
+	}
+	return s.feedbackDao.UpdateCommentStatus(c, comment.ID, status, userID)
+}
+
+// AddReport 报告题目错误
+func (s *FeedbackService) AddReport(c context.Context, userID, questionID int, req *dto.AddReportReq) (*model.QuestionReport, error) {
+	report := &model.QuestionReport{
+		QuestionID:  questionID,
+		ReporterID:  userID,
+		Category:    string(req.Category),
+		Description: req.Description,
+		Status:      string(enums.FeedbackOpen),
+	}
+	if err := s.feedbackDao.AddReport(c, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *FeedbackService) GetReport(c context.Context, questionID, reportID int) (*model.QuestionReport, error) {
+	return s.feedbackDao.GetReport(c, questionID, reportID)
+}
+
+func (s *FeedbackService) ListReports(c context.Context, questionID int, status enums.FeedbackStatus) ([]model.QuestionReport, error) {
+	return s.feedbackDao.ListReports(c, questionID, string(status))
+}
+
+// UpdateReportStatus 修改错误报告的状态，重新打开时清空处理说明
+func (s *FeedbackService) UpdateReportStatus(c context.Context, userID int, report *model.QuestionReport, req *dto.UpdateFeedbackStatusReq) error {
+	resolution := req.Resolution
+	if req.Status == enums.FeedbackOpen {
+		resolution = ""
+	}
+	return s.feedbackDao.UpdateReportStatus(c, report.ID, req.Status, resolution, userID)
+}
+
+// ListInboxReports 分页查询用户题目收到的错误报告，ownerID 为 0 时查询全部题目
+func (s *FeedbackService) ListInboxReports(c context.Context, ownerID int, req *dto.FeedbackListReq) ([]model.QuestionReport, int64, error) {
+	return s.feedbackDao.ListInboxReports(c, ownerID, string(req.Status), req.Page)
+}
+
+// ListInboxComments 分页查询用户题目下其他用户发起的评论串及其回复数量，ownerID 为 0 时查询全部题目
+func (s *FeedbackService) ListInboxComments(c context.Context, ownerID int, req *dto.FeedbackListReq) ([]model.QuestionComment, map[int]int, int64, error) {
+	threads, total, err := s.feedbackDao.ListInboxComments(c, ownerID, string(req.Status), req.Page)
+	if err != nil || len(threads) == 0 {
+		return threads, nil, total, err
+	}
+	ids := make([]int, 0, len(threads))
+	for _, thread := range threads {
+		ids = append(ids, thread.ID)
+	}
+	replies, err := s.feedbackDao.CountReplies(c, ids)
+	return threads, replies, total, err
+}
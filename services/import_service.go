@@ -0,0 +1,454 @@
+package services
+
+import (
+	"aiquiz/ai"
+	"aiquiz/config"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils"
+	"aiquiz/utils/enums"
+	"aiquiz/utils/quizformat"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxImportQuestions 单个文件最多导入的题目数量
+const maxImportQuestions = 1000
+
+// maxImportOptions 每道题目最多的选项数量，选项以字母 A-Z 标记，答案为按选项下标的位掩码
+const maxImportOptions = 26
+
+// 导入导出的文件格式
+const (
+	FormatJSON      = "json"
+	FormatCSV       = "csv"
+	FormatXLSX      = "xlsx"
+	FormatMoodleXML = "moodle_xml"
+	FormatGIFT      = "gift"
+	FormatQTI       = "qti"
+	FormatHTML      = "html" // 仅用于导出，可打印的 HTML 文档
+)
+
+// formatExtensions 未指定格式时按文件扩展名判断
+var formatExtensions = map[string]string{
+	".json": FormatJSON,
+	".csv":  FormatCSV,
+	".xlsx": FormatXLSX,
+	".xml":  FormatMoodleXML,
+	".gift": FormatGIFT,
+	".txt":  FormatGIFT,
+	".zip":  FormatQTI,
+}
+
+// 未指定列映射时各字段默认匹配的表头(不区分大小写)
+var defaultImportHeaders = map[string][]string{
+	"title":         {"title", "题目", "题干"},
+	"answer":        {"answer", "答案"},
+	"explanation":   {"explanation", "解析"},
+	"question_type": {"question_type", "题型"},
+	"language":      {"language", "语言"},
+	"keywords":      {"keywords", "关键词"},
+	"tags":          {"tags", "标签"},
+	"difficulty":    {"difficulty", "难度"},
+}
+
+// defaultOptionPrefixes 未指定选项列时，表头以这些前缀开头的列依次作为选项
+var defaultOptionPrefixes = []string{"option", "选项"}
+
+type ImportService struct {
+	questionService *QuestionService
+}
+
+func NewImportService(questionService *QuestionService) *ImportService {
+	return &ImportService{questionService: questionService}
+}
+
+// importItem 从文件中读取的一道题目，尚未校验
+type importItem struct {
+	Row          int
+	Title        string
+	Options      []string
+	Answer       string // 字母或位掩码
+	Explanation  string
+	QuestionType string
+	Language     string
+	Keywords     string
+	Tags         []string
+	Difficulty   string
+	Err          error // 解析阶段已发现的错误(如不支持的题型)
+}
+
+// importJSONQuestion JSON导入格式，与 dto.Question 一致并可附带题目属性，答案可以是位掩码或字母
+type importJSONQuestion struct {
+	Title        string          `json:"title"`
+	Options      []dto.Option    `json:"options"`
+	Answer       json.RawMessage `json:"answer"`
+	Explanation  string          `json:"explanation"`
+	QuestionType string          `json:"question_type"`
+	Language     string          `json:"language"`
+	Keywords     string          `json:"keywords"`
+	Tags         []string        `json:"tags"`
+	Difficulty   string          `json:"difficulty"`
+}
+
+// DetectFormat 确定导入文件的格式，未指定时按扩展名判断
+func (s *ImportService) DetectFormat(format, filename string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = formatExtensions[strings.ToLower(filepath.Ext(filename))]
+	}
+	switch format {
+	case FormatJSON, FormatCSV, FormatXLSX, FormatMoodleXML, FormatGIFT, FormatQTI:
+		return format, nil
+	}
+	return "", errors.New("不支持的文件格式，仅支持 json、csv、xlsx、moodle_xml、gift、qti")
+}
+
+// ImportQuestions 解析并校验导入文件，不是试运行且全部题目校验通过(或指定跳过错误题目)时入库。
+// 文件本身无法解析时返回 error，题目内容的错误逐行记录在结果中
+func (s *ImportService) ImportQuestions(c context.Context, userID int, req *dto.ImportQuestionsReq, format string, data []byte) (*dto.ImportQuestionsRes, error) {
+	items, err := s.parseFile(req, format, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > maxImportQuestions {
+		return nil, fmt.Errorf("单个文件最多导入%d道题目", maxImportQuestions)
+	}
+
+	res := &dto.ImportQuestionsRes{DryRun: req.DryRun, Total: len(items), Errors: make([]dto.ImportRowError, 0)}
+	questions := make([]model.Question, 0, len(items))
+	tagNames := make([][]string, 0, len(items))
+	languages := config.GetConfig(true).SupportedLanguages
+	for _, item := range items {
+		applyImportDefaults(&item, req)
+		q, err := buildImportQuestion(item, languages)
+		if err != nil {
+			res.Errors = append(res.Errors, dto.ImportRowError{Row: item.Row, Message: err.Error()})
+			continue
+		}
+		q.UserID = userID
+		questions = append(questions, *q)
+		tagNames = append(tagNames, item.Tags)
+	}
+	res.Valid = len(questions)
+	if req.DryRun || (len(res.Errors) > 0 && !req.SkipInvalid) || len(questions) == 0 {
+		return res, nil
+	}
+	if err := s.questionService.ConfirmQuestions(c, userID, &questions, tagNames, nil, nil); err != nil {
+		return nil, err
+	}
+	res.Imported = len(questions)
+	return res, nil
+}
+
+func (s *ImportService) parseFile(req *dto.ImportQuestionsReq, format string, data []byte) ([]importItem, error) {
+	switch format {
+	case FormatJSON:
+		return parseImportJSON(data)
+	case FormatMoodleXML:
+		items, err := quizformat.ParseMoodleXML(data)
+		if err != nil {
+			return nil, err
+		}
+		return convertQuizItems(items), nil
+	case FormatGIFT:
+		items, err := quizformat.ParseGIFT(data)
+		if err != nil {
+			return nil, err
+		}
+		return convertQuizItems(items), nil
+	case FormatQTI:
+		items, err := quizformat.ParseQTI(data)
+		if err != nil {
+			return nil, err
+		}
+		return convertQuizItems(items), nil
+	case FormatCSV:
+		rows, err := utils.ReadCSV(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return parseImportRows(rows, req.Mapping)
+	case FormatXLSX:
+		rows, err := utils.ReadXLSX(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		return parseImportRows(rows, req.Mapping)
+	}
+	return nil, errors.New("不支持的文件格式")
+}
+
+// parseImportJSON 解析题目数组，行号为题目在数组中的序号(从1开始)
+func parseImportJSON(data []byte) ([]importItem, error) {
+	var questions []importJSONQuestion
+	if err := json.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &questions); err != nil {
+		return nil, fmt.Errorf("JSON格式错误，应为题目数组: %w", err)
+	}
+	items := make([]importItem, 0, len(questions))
+	for i, q := range questions {
+		item := importItem{
+			Row:          i + 1,
+			Title:        q.Title,
+			Explanation:  q.Explanation,
+			QuestionType: q.QuestionType,
+			Language:     q.Language,
+			Keywords:     q.Keywords,
+			Tags:         q.Tags,
+			Difficulty:   q.Difficulty,
+		}
+		for _, opt := range q.Options {
+			item.Options = append(item.Options, opt.Content)
+		}
+		// 答案可以是数字位掩码或字母字符串
+		var answer string
+		if err := json.Unmarshal(q.Answer, &answer); err != nil {
+			answer = string(q.Answer)
+		}
+		item.Answer = answer
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// convertQuizItems 转换 Moodle XML、GIFT 中的题目，分类路径作为关键词，分类与题目标签一起作为标签
+func convertQuizItems(quizItems []quizformat.Item) []importItem {
+	items := make([]importItem, 0, len(quizItems))
+	for _, qi := range quizItems {
+		q := qi.Question
+		item := importItem{
+			Row:          qi.Index,
+			Title:        q.Title,
+			Options:      q.Options,
+			Answer:       strconv.Itoa(q.Answer),
+			Explanation:  q.Explanation,
+			QuestionType: string(enums.MultipleType),
+			Keywords:     strings.Join(q.Category, ","),
+			Tags:         append(append([]string{}, q.Category...), q.Tags...),
+			Err:          qi.Err,
+		}
+		if q.Single {
+			item.QuestionType = string(enums.SingleType)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// parseImportRows 按列映射解析表格，第一行为表头
+func parseImportRows(rows []utils.SheetRow, mappingJSON string) ([]importItem, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("文件中没有数据")
+	}
+	var mapping dto.ImportColumnMapping
+	if strings.TrimSpace(mappingJSON) != "" {
+		if err := json.Unmarshal([]byte(mappingJSON), &mapping); err != nil {
+			return nil, fmt.Errorf("列映射格式错误: %w", err)
+		}
+	}
+	header := rows[0].Cells
+
+	columns := make(map[string]int, len(defaultImportHeaders))
+	fields := map[string]string{
+		"title":         mapping.Title,
+		"answer":        mapping.Answer,
+		"explanation":   mapping.Explanation,
+		"question_type": mapping.QuestionType,
+		"language":      mapping.Language,
+		"keywords":      mapping.Keywords,
+		"tags":          mapping.Tags,
+		"difficulty":    mapping.Difficulty,
+	}
+	for field, ref := range fields {
+		if ref != "" {
+			col, err := findColumn(header, ref)
+			if err != nil {
+				return nil, err
+			}
+			columns[field] = col
+			continue
+		}
+		for _, name := range defaultImportHeaders[field] {
+			if col := headerIndex(header, name); col >= 0 {
+				columns[field] = col
+				break
+			}
+		}
+	}
+	for _, field := range []string{"title", "answer"} {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("找不到 %s 对应的列，请检查表头或列映射", field)
+		}
+	}
+
+	var optionColumns []int
+	if len(mapping.Options) > 0 {
+		for _, ref := range mapping.Options {
+			col, err := findColumn(header, ref)
+			if err != nil {
+				return nil, err
+			}
+			optionColumns = append(optionColumns, col)
+		}
+	} else {
+		for i, name := range header {
+			name = strings.ToLower(strings.TrimSpace(name))
+			for _, prefix := range defaultOptionPrefixes {
+				if strings.HasPrefix(name, prefix) {
+					optionColumns = append(optionColumns, i)
+					break
+				}
+			}
+		}
+	}
+	if len(optionColumns) == 0 {
+		return nil, errors.New("找不到选项列，请检查表头或列映射")
+	}
+
+	items := make([]importItem, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		cell := func(field string) string {
+			col, ok := columns[field]
+			if !ok || col >= len(row.Cells) {
+				return ""
+			}
+			return strings.TrimSpace(row.Cells[col])
+		}
+		item := importItem{
+			Row:          row.Line,
+			Title:        cell("title"),
+			Answer:       cell("answer"),
+			Explanation:  cell("explanation"),
+			QuestionType: cell("question_type"),
+			Language:     cell("language"),
+			Keywords:     cell("keywords"),
+			Tags:         utils.SplitKeywords(cell("tags")),
+			Difficulty:   cell("difficulty"),
+		}
+		for _, col := range optionColumns {
+			if col < len(row.Cells) {
+				item.Options = append(item.Options, strings.TrimSpace(row.Cells[col]))
+			} else {
+				item.Options = append(item.Options, "")
+			}
+		}
+		// 选项数量不固定时末尾的空列不算作选项
+		for len(item.Options) > 0 && item.Options[len(item.Options)-1] == "" {
+			item.Options = item.Options[:len(item.Options)-1]
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// findColumn 按表头名称(优先)或列字母查找列
+func findColumn(header []string, ref string) (int, error) {
+	if col := headerIndex(header, ref); col >= 0 {
+		return col, nil
+	}
+	if col := utils.ColumnIndex(ref); col >= 0 {
+		return col, nil
+	}
+	return 0, fmt.Errorf("列映射中的列 %s 不存在", ref)
+}
+
+func headerIndex(header []string, name string) int {
+	name = strings.TrimSpace(name)
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyImportDefaults 文件中未提供的属性使用请求中的默认值
+func applyImportDefaults(item *importItem, req *dto.ImportQuestionsReq) {
+	if item.QuestionType == "" {
+		item.QuestionType = string(req.QuestionType)
+	}
+	if item.Language == "" {
+		item.Language = req.Language
+	}
+	if item.Keywords == "" && len(item.Tags) == 0 {
+		item.Keywords = req.Keywords
+	}
+	if item.Difficulty == "" {
+		item.Difficulty = string(req.Difficulty)
+	}
+}
+
+// buildImportQuestion 校验题目并转换为模型，选项的值按顺序依次为2的次幂
+func buildImportQuestion(item importItem, languages map[string]interface{}) (*model.Question, error) {
+	if item.Err != nil {
+		return nil, item.Err
+	}
+	if strings.TrimSpace(item.Title) == "" {
+		return nil, errors.New("题目不能为空")
+	}
+	if len(item.Options) < 2 {
+		return nil, errors.New("至少需要两个选项")
+	}
+	if len(item.Options) > maxImportOptions {
+		return nil, fmt.Errorf("最多支持%d个选项", maxImportOptions)
+	}
+	options := make([]dto.Option, 0, len(item.Options))
+	for i, content := range item.Options {
+		if strings.TrimSpace(content) == "" {
+			return nil, fmt.Errorf("选项%c为空", 'A'+i)
+		}
+		options = append(options, dto.Option{Content: content, Value: 1 << i})
+	}
+	if err := ai.CleanQuestionContent(&item.Title, options, &item.Explanation); err != nil {
+		return nil, fmt.Errorf("内容格式错误: %v", err)
+	}
+	answer, err := utils.ParseAnswer(item.Answer, len(options))
+	if err != nil {
+		return nil, err
+	}
+	correct := bits.OnesCount(uint(answer))
+	questionType := enums.QuestionType(item.QuestionType)
+	if questionType == "" {
+		questionType = enums.SingleType
+		if correct > 1 {
+			questionType = enums.MultipleType
+		}
+	}
+	if !enums.IsSupportedQuestionType(questionType) {
+		return nil, fmt.Errorf("无效的题目类型 %s，必须是 'single' 或 'multiple'", item.QuestionType)
+	}
+	if questionType == enums.SingleType && correct != 1 {
+		return nil, errors.New("单选题只能有一个正确答案")
+	}
+	if _, ok := languages[item.Language]; !ok {
+		return nil, fmt.Errorf("无效的语言 %s", item.Language)
+	}
+	if strings.TrimSpace(item.Keywords) == "" && len(item.Tags) == 0 {
+		return nil, errors.New("缺少关键词或标签")
+	}
+	if item.Difficulty != "" && !enums.IsSupportedDifficulty(enums.Difficulty(item.Difficulty)) {
+		return nil, fmt.Errorf("无效的难度 %s，必须是 'easy'、'medium' 或 'hard'", item.Difficulty)
+	}
+	optionBytes, err := json.Marshal(options)
+	if err != nil {
+		return nil, errors.New("选项序列化失败")
+	}
+	return &model.Question{
+		Title:        item.Title,
+		QuestionType: string(questionType),
+		Options:      string(optionBytes),
+		Answer:       strconv.Itoa(answer),
+		Explanation:  item.Explanation,
+		Keywords:     item.Keywords,
+		Language:     item.Language,
+		AiModel:      string(enums.AiModelImport),
+		Difficulty:   item.Difficulty,
+	}, nil
+}
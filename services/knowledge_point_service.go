@@ -0,0 +1,421 @@
+package services
+
+import (
+	"aiquiz/ai"
+	"aiquiz/config"
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils/enums"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidKnowledgePoint 知识点不存在或不属于题目的语言
+var ErrInvalidKnowledgePoint = errors.New("知识点不存在或不属于题目的语言")
+
+// knowledgePathSeparator 知识点完整路径中各级名称的分隔符
+const knowledgePathSeparator = " / "
+
+type KnowledgePointService struct {
+	knowledgePointDao *dao.KnowledgePointDao
+}
+
+func NewKnowledgePointService(knowledgePointDao *dao.KnowledgePointDao) *KnowledgePointService {
+	return &KnowledgePointService{knowledgePointDao: knowledgePointDao}
+}
+
+// CreateKnowledgePoint 创建知识点，上级知识点须属于同一语言，同一上级知识点下名称不能重复
+func (s *KnowledgePointService) CreateKnowledgePoint(c context.Context, req *dto.KnowledgePointReq) (*model.KnowledgePoint, error) {
+	point := &model.KnowledgePoint{
+		Language:    req.Language,
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	}
+	if err := s.checkPoint(c, point); err != nil {
+		return nil, err
+	}
+	if err := s.knowledgePointDao.CreateKnowledgePoint(c, point); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
+
+func (s *KnowledgePointService) GetKnowledgePoint(c context.Context, id int) (*model.KnowledgePoint, error) {
+	return s.knowledgePointDao.GetKnowledgePoint(c, id)
+}
+
+// UpdateKnowledgePoint 修改知识点的名称、描述与上级知识点，不能移动到自身或下级知识点之下
+func (s *KnowledgePointService) UpdateKnowledgePoint(c context.Context, point *model.KnowledgePoint, req *dto.KnowledgePointReq) error {
+	updated := *point
+	updated.Name = req.Name
+	updated.Description = req.Description
+	updated.ParentID = req.ParentID
+	if updated.ParentID != 0 && updated.ParentID != point.ParentID {
+		subtree, err := s.knowledgePointDao.SubtreeIDs(c, point.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range subtree {
+			if id == updated.ParentID {
+				return errors.New("不能移动到自身或下级知识点之下")
+			}
+		}
+	}
+	if err := s.checkPoint(c, &updated); err != nil {
+		return err
+	}
+	return s.knowledgePointDao.UpdateKnowledgePoint(c, &updated)
+}
+
+// checkPoint 校验上级知识点存在且属于同一语言，同一上级知识点下没有同名知识点
+func (s *KnowledgePointService) checkPoint(c context.Context, point *model.KnowledgePoint) error {
+	if point.ParentID != 0 {
+		parent, err := s.knowledgePointDao.GetKnowledgePoint(c, point.ParentID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err != nil || parent.Language != point.Language {
+			return errors.New("上级知识点不存在")
+		}
+	}
+	exists, err := s.knowledgePointDao.ExistsSibling(c, point.Language, point.ParentID, point.Name, point.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("同一上级知识点下已有同名知识点")
+	}
+	return nil
+}
+
+// DeleteKnowledgePoint 删除知识点及其全部下级知识点，关联的题目不受影响
+func (s *KnowledgePointService) DeleteKnowledgePoint(c context.Context, id int) error {
+	ids, err := s.knowledgePointDao.SubtreeIDs(c, id)
+	if err != nil {
+		return err
+	}
+	return s.knowledgePointDao.DeleteKnowledgePoints(c, ids)
+}
+
+// SubtreeIDs 返回知识点自身及其全部下级知识点的ID
+func (s *KnowledgePointService) SubtreeIDs(c context.Context, id int) ([]int, error) {
+	return s.knowledgePointDao.SubtreeIDs(c, id)
+}
+
+// knowledgeCounter 统计知识点树中每个节点覆盖的题目，下级知识点的题目同时计入上级知识点(同一题目只计一次)
+type knowledgeCounter struct {
+	direct map[int]map[int]int // 知识点ID -> 题目ID -> 分值
+	total  map[int]map[int]int
+}
+
+func newKnowledgeCounter() *knowledgeCounter {
+	return &knowledgeCounter{direct: make(map[int]map[int]int), total: make(map[int]map[int]int)}
+}
+
+func (k *knowledgeCounter) add(points map[int]model.KnowledgePoint, pointID, questionID, score int) {
+	if _, ok := points[pointID]; !ok {
+		return
+	}
+	if k.direct[pointID] == nil {
+		k.direct[pointID] = make(map[int]int)
+	}
+	k.direct[pointID][questionID] = score
+	// 沿上级知识点向上累计，visited 防止数据异常时出现环
+	visited := make(map[int]bool)
+	for id := pointID; id != 0 && !visited[id]; id = points[id].ParentID {
+		if _, ok := points[id]; !ok {
+			break
+		}
+		visited[id] = true
+		if k.total[id] == nil {
+			k.total[id] = make(map[int]int)
+		}
+		k.total[id][questionID] = score
+	}
+}
+
+func sumScores(scores map[int]int) int {
+	total := 0
+	for _, score := range scores {
+		total += score
+	}
+	return total
+}
+
+// buildKnowledgeTree 以树形结构返回知识点及覆盖的题目数量，withScore 为 true 时同时返回分值
+func buildKnowledgeTree(points []model.KnowledgePoint, counter *knowledgeCounter, withScore bool) []dto.KnowledgePointRes {
+	exists := make(map[int]struct{}, len(points))
+	for _, point := range points {
+		exists[point.ID] = struct{}{}
+	}
+	children := make(map[int][]model.KnowledgePoint, len(points))
+	for _, point := range points {
+		parentID := point.ParentID
+		// 上级知识点已不存在时作为顶层知识点展示
+		if _, ok := exists[parentID]; !ok {
+			parentID = 0
+		}
+		children[parentID] = append(children[parentID], point)
+	}
+	var build func(parentID int) []dto.KnowledgePointRes
+	build = func(parentID int) []dto.KnowledgePointRes {
+		nodes := make([]dto.KnowledgePointRes, 0, len(children[parentID]))
+		for _, point := range children[parentID] {
+			res := ToKnowledgePointRes(&point)
+			res.QuestionCount = len(counter.direct[point.ID])
+			res.TotalCount = len(counter.total[point.ID])
+			if withScore {
+				res.Score = sumScores(counter.direct[point.ID])
+				res.TotalScore = sumScores(counter.total[point.ID])
+			}
+			res.Children = build(point.ID)
+			nodes = append(nodes, res)
+		}
+		sort.SliceStable(nodes, func(i, j int) bool {
+			if nodes[i].Language != nodes[j].Language {
+				return nodes[i].Language < nodes[j].Language
+			}
+			return nodes[i].Name < nodes[j].Name
+		})
+		return nodes
+	}
+	return build(0)
+}
+
+// ListTree 以树形结构返回知识点及各知识点覆盖的题目数量，language 为空时返回全部语言；
+// visibleTo 大于 0 时只统计该用户自己的题目与共享题目
+func (s *KnowledgePointService) ListTree(c context.Context, language string, visibleTo int) ([]dto.KnowledgePointRes, error) {
+	points, err := s.knowledgePointDao.ListKnowledgePoints(c, language)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return []dto.KnowledgePointRes{}, nil
+	}
+	links, err := s.knowledgePointDao.ListQuestionLinks(c, language, visibleTo)
+	if err != nil {
+		return nil, err
+	}
+	byID := pointsByID(points)
+	counter := newKnowledgeCounter()
+	for _, link := range links {
+		counter.add(byID, link.KnowledgePointID, link.QuestionID, 0)
+	}
+	return buildKnowledgeTree(points, counter, false), nil
+}
+
+// GetTree 返回知识点及其下级知识点的树形结构
+func (s *KnowledgePointService) GetTree(c context.Context, point *model.KnowledgePoint, visibleTo int) (*dto.KnowledgePointRes, error) {
+	tree, err := s.ListTree(c, point.Language, visibleTo)
+	if err != nil {
+		return nil, err
+	}
+	if node := findKnowledgePoint(tree, point.ID); node != nil {
+		return node, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func findKnowledgePoint(nodes []dto.KnowledgePointRes, id int) *dto.KnowledgePointRes {
+	for i := range nodes {
+		if nodes[i].ID == id {
+			return &nodes[i]
+		}
+		if node := findKnowledgePoint(nodes[i].Children, id); node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+// PaperCoverage 统计试卷中的题目覆盖的知识点及分值，只返回试卷题目所属语言的知识点树
+func (s *KnowledgePointService) PaperCoverage(c context.Context, paperID int) (*dto.PaperKnowledgeCoverageRes, error) {
+	links, err := s.knowledgePointDao.ListPaperLinks(c, paperID)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.PaperKnowledgeCoverageRes{PaperID: paperID, KnowledgePoints: []dto.KnowledgePointRes{}}
+	scores := make(map[int]int)
+	classified := make(map[int]int)
+	languages := make(map[string]bool)
+	for _, link := range links {
+		scores[link.QuestionID] = link.Score
+		if link.KnowledgePointID != 0 {
+			classified[link.QuestionID] = link.Score
+		}
+		languages[link.Language] = true
+	}
+	res.QuestionCount, res.TotalScore = len(scores), sumScores(scores)
+	res.ClassifiedCount, res.ClassifiedScore = len(classified), sumScores(classified)
+
+	var points []model.KnowledgePoint
+	for language := range languages {
+		list, err := s.knowledgePointDao.ListKnowledgePoints(c, language)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, list...)
+	}
+	if len(points) == 0 {
+		return res, nil
+	}
+	byID := pointsByID(points)
+	counter := newKnowledgeCounter()
+	for _, link := range links {
+		if link.KnowledgePointID != 0 {
+			counter.add(byID, link.KnowledgePointID, link.QuestionID, link.Score)
+		}
+	}
+	res.KnowledgePoints = buildKnowledgeTree(points, counter, true)
+	return res, nil
+}
+
+func pointsByID(points []model.KnowledgePoint) map[int]model.KnowledgePoint {
+	byID := make(map[int]model.KnowledgePoint, len(points))
+	for _, point := range points {
+		byID[point.ID] = point
+	}
+	return byID
+}
+
+// knowledgePath 知识点从顶层知识点开始的完整路径
+func knowledgePath(byID map[int]model.KnowledgePoint, id int) string {
+	var names []string
+	visited := make(map[int]bool)
+	for id != 0 && !visited[id] {
+		point, ok := byID[id]
+		if !ok {
+			break
+		}
+		visited[id] = true
+		names = append([]string{point.Name}, names...)
+		id = point.ParentID
+	}
+	return strings.Join(names, knowledgePathSeparator)
+}
+
+// ListQuestionKnowledgePoints 获取题目关联的知识点
+func (s *KnowledgePointService) ListQuestionKnowledgePoints(c context.Context, q *model.Question) ([]dto.KnowledgePointRefRes, error) {
+	rows, err := s.knowledgePointDao.ListQuestionKnowledgePoints(c, []int{q.ID})
+	if err != nil {
+		return nil, err
+	}
+	points, err := s.knowledgePointDao.ListKnowledgePoints(c, q.Language)
+	if err != nil {
+		return nil, err
+	}
+	byID := pointsByID(points)
+	res := make([]dto.KnowledgePointRefRes, 0, len(rows[q.ID]))
+	for _, row := range rows[q.ID] {
+		// 知识点的语言与题目不同(题目修改过语言)时路径只含知识点自身
+		path := knowledgePath(byID, row.ID)
+		if path == "" {
+			path = row.Name
+		}
+		res = append(res, dto.KnowledgePointRefRes{ID: row.ID, Name: row.Name, Path: path, Source: row.Source})
+	}
+	return res, nil
+}
+
+// ValidateIDs 去重并校验知识点都存在且属于指定语言，否则返回 ErrInvalidKnowledgePoint
+func (s *KnowledgePointService) ValidateIDs(c context.Context, language string, ids []int) ([]int, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	points, err := s.knowledgePointDao.GetKnowledgePointsByIDs(c, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) != len(ids) {
+		return nil, ErrInvalidKnowledgePoint
+	}
+	for _, point := range points {
+		if point.Language != language {
+			return nil, ErrInvalidKnowledgePoint
+		}
+	}
+	return ids, nil
+}
+
+// SetQuestionKnowledgePoints 整体替换题目关联的知识点(手动关联)
+func (s *KnowledgePointService) SetQuestionKnowledgePoints(c context.Context, q *model.Question, ids []int) error {
+	ids, err := s.ValidateIDs(c, q.Language, ids)
+	if err != nil {
+		return err
+	}
+	return s.knowledgePointDao.SetQuestionKnowledgePoints(c, q.ID, ids, string(enums.KnowledgeSourceManual))
+}
+
+// LinkQuestions 在事务中为新入库的题目添加知识点关联，ids 与 questions 按下标对应，知识点须已通过 ValidateIDs 校验
+func (s *KnowledgePointService) LinkQuestions(c context.Context, tx *gorm.DB, questions []model.Question, ids [][]int, sources []enums.KnowledgeSource) error {
+	var links []model.QuestionKnowledgePoint
+	for i, q := range questions {
+		if i >= len(ids) {
+			break
+		}
+		for _, id := range ids[i] {
+			links = append(links, model.QuestionKnowledgePoint{QuestionID: q.ID, KnowledgePointID: id, Source: string(sources[i])})
+		}
+	}
+	return s.knowledgePointDao.AddQuestionKnowledgePoints(c, tx, links)
+}
+
+// CopyQuestionKnowledgePoints 在事务中将题目关联的知识点复制给另一道题目(复制题目时使用)
+func (s *KnowledgePointService) CopyQuestionKnowledgePoints(c context.Context, tx *gorm.DB, fromQuestionID, toQuestionID int) error {
+	return s.knowledgePointDao.CopyQuestionKnowledgePoints(c, tx, fromQuestionID, toQuestionID)
+}
+
+// SuggestKnowledgePoints 调用模型为生成的题目推荐考查的知识点，结果与 questions 按下标对应。
+// 未开启自动分类或该语言没有知识点时返回 nil
+func (s *KnowledgePointService) SuggestKnowledgePoints(c context.Context, aiModel, language string, questions []dto.Question) ([][]dto.KnowledgePointRefRes, error) {
+	if !config.GetConfig(false).KnowledgeAutoClassify {
+		return nil, nil
+	}
+	points, err := s.knowledgePointDao.ListKnowledgePoints(c, language)
+	if err != nil || len(points) == 0 {
+		return nil, err
+	}
+	byID := pointsByID(points)
+	options := make([]ai.KnowledgePointOption, 0, len(points))
+	for _, point := range points {
+		options = append(options, ai.KnowledgePointOption{ID: point.ID, Path: knowledgePath(byID, point.ID)})
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].Path < options[j].Path })
+	classified, err := ai.ClassifyKnowledgePoints(aiModel, language, options, questions)
+	if err != nil {
+		return nil, fmt.Errorf("知识点分类失败: %w", err)
+	}
+	result := make([][]dto.KnowledgePointRefRes, len(questions))
+	for i, ids := range classified {
+		for _, id := range ids {
+			result[i] = append(result[i], dto.KnowledgePointRefRes{
+				ID:     id,
+				Name:   byID[id].Name,
+				Path:   knowledgePath(byID, id),
+				Source: string(enums.KnowledgeSourceAI),
+			})
+		}
+	}
+	return result, nil
+}
+
+// ToKnowledgePointRes 将知识点转换为返回结构体
+func ToKnowledgePointRes(point *model.KnowledgePoint) dto.KnowledgePointRes {
+	return dto.KnowledgePointRes{
+		ID:          point.ID,
+		Language:    point.Language,
+		Name:        point.Name,
+		Description: point.Description,
+		ParentID:    point.ParentID,
+		CreatedAt:   point.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   point.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Children:    []dto.KnowledgePointRes{},
+	}
+}
@@ -1,22 +1,29 @@
 package services
 
 import (
+	"aiquiz/config"
 	"aiquiz/dao"
 	"aiquiz/dao/model"
 	"aiquiz/models/dto"
 	"context"
 	"errors"
+	"fmt"
+	"gorm.io/gorm"
 )
 
 type PaperService struct {
-	paperDao    *dao.PaperDao
-	questionDao *dao.QuestionDao
+	paperDao      *dao.PaperDao
+	questionDao   *dao.QuestionDao
+	revisionDao   *dao.QuestionRevisionDao
+	collectionDao *dao.CollectionDao
 }
 
-func NewPaperService(paperDao *dao.PaperDao, questionDao *dao.QuestionDao) *PaperService {
+func NewPaperService(paperDao *dao.PaperDao, questionDao *dao.QuestionDao, revisionDao *dao.QuestionRevisionDao, collectionDao *dao.CollectionDao) *PaperService {
 	return &PaperService{
-		paperDao:    paperDao,
-		questionDao: questionDao,
+		paperDao:      paperDao,
+		questionDao:   questionDao,
+		revisionDao:   revisionDao,
+		collectionDao: collectionDao,
 	}
 }
 func (s *PaperService) GeneratePaper(c context.Context, userID int, req *dto.GeneratePaperReq) error {
@@ -48,13 +55,43 @@ func (s *PaperService) AddPaperQuestions(c context.Context, userID, paperID int,
 	if len(existQuestionIds) != len(questionIDList) {
 		return errors.New("某些题目不存在")
 	}
+	// 开启审核规则时只允许加入审核通过的题目
+	if config.GetConfig(false).ReviewRequireApproved {
+		unapproved, err := s.questionDao.GetUnapprovedQuestionIDs(c, questionIDList)
+		if err != nil {
+			return err
+		}
+		if len(unapproved) > 0 {
+			return fmt.Errorf("题目 %v 未通过审核，不能加入试卷", unapproved)
+		}
+	}
+	// 开启后有未解决错误报告的题目不能加入试卷
+	if config.GetConfig(false).PaperExcludeReported {
+		reported, err := s.questionDao.GetReportedQuestionIDs(c, questionIDList)
+		if err != nil {
+			return err
+		}
+		if len(reported) > 0 {
+			return fmt.Errorf("题目 %v 有未解决的错误报告，不能加入试卷", reported)
+		}
+	}
 	// 将req转换为model
 	var paperQuestions = make([]model.PaperQuestion, 0, len(req))
 	for _, question := range req {
+		// 指定了版本号时固定使用该版本
+		revisionID := 0
+		if question.Revision > 0 {
+			revision, err := s.revisionDao.GetRevision(c, question.QuestionID, question.Revision)
+			if err != nil {
+				return fmt.Errorf("题目 %d 的版本 %d 不存在", question.QuestionID, question.Revision)
+			}
+			revisionID = revision.ID
+		}
 		paperQuestions = append(paperQuestions, model.PaperQuestion{
 			PaperID:    paperID,
 			QuestionID: question.QuestionID,
 			Score:      question.Score,
+			RevisionID: revisionID,
 		})
 	}
 
@@ -66,10 +103,59 @@ func (s *PaperService) AddPaperQuestions(c context.Context, userID, paperID int,
 	return nil
 }
 
+// AddCollectionQuestions 将集合中的题目按集合中的顺序加入试卷，已在试卷中的题目跳过，返回加入的数量
+func (s *PaperService) AddCollectionQuestions(c context.Context, userID int, isAdmin bool, paperID int, req *dto.AddCollectionToPaperReq) (int, error) {
+	collection, err := s.collectionDao.GetCollection(c, req.CollectionID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	if err != nil || (!isAdmin && collection.UserID != userID) {
+		return 0, errors.New("集合不存在")
+	}
+	questionIDs, err := s.collectionDao.ListQuestionIDs(c, collection.ID)
+	if err != nil {
+		return 0, err
+	}
+	paperQuestions, err := s.paperDao.GetPaperQuestions(c, paperID)
+	if err != nil {
+		return 0, err
+	}
+	inPaper := make(map[int]struct{}, len(paperQuestions))
+	for _, pq := range paperQuestions {
+		inPaper[pq.QuestionID] = struct{}{}
+	}
+	questions := make([]dto.AddPaperQuestionsReq, 0, len(questionIDs))
+	for _, id := range questionIDs {
+		if _, ok := inPaper[id]; !ok {
+			questions = append(questions, dto.AddPaperQuestionsReq{QuestionID: id, Score: req.Score})
+		}
+	}
+	if len(questions) == 0 {
+		return 0, nil
+	}
+	if err := s.AddPaperQuestions(c, userID, paperID, questions); err != nil {
+		return 0, err
+	}
+	return len(questions), nil
+}
+
 func (s *PaperService) UpdatePaper(c context.Context, paperID int, req dto.UpdatePaperReq) error {
 	return s.paperDao.UpdatePaper(c, paperID, req.Title, req.Description, req.TotalScore)
 }
 
+// PinQuestionRevision 固定试卷中题目使用的版本，revision 为 0 时取消固定(始终使用最新内容)
+func (s *PaperService) PinQuestionRevision(c context.Context, paperID, questionID, revision int) (bool, error) {
+	revisionID := 0
+	if revision > 0 {
+		r, err := s.revisionDao.GetRevision(c, questionID, revision)
+		if err != nil {
+			return false, fmt.Errorf("版本 %d 不存在", revision)
+		}
+		revisionID = r.ID
+	}
+	return s.paperDao.UpdatePaperQuestionRevision(c, paperID, questionID, revisionID)
+}
+
 func (s *PaperService) DeletePaperQuestion(c context.Context, paperID, questionID int) (bool, error) {
 	return s.paperDao.DeletePaperQuestion(c, paperID, questionID)
 }
@@ -83,18 +169,20 @@ func (s *PaperService) DeletePaper(c context.Context, paperID int) error {
 
 func (s *PaperService) UpdatePaperQuestionOrder(c context.Context, paperID int, req []dto.QuestionOrderReq) error {
 	// 查询试卷全部题目
-	existQuestionIDs, err := s.paperDao.GetPaperQuestionIDs(paperID)
+	existQuestions, err := s.paperDao.GetPaperQuestions(c, paperID)
 	if err != nil {
 		return err
 	}
 	// 判断传入的题目ID是否存在
-	if len(req) != len(existQuestionIDs) {
+	if len(req) != len(existQuestions) {
 		return errors.New("试卷题目数量与参数不一致")
 	}
-	// 将已存在的题目ID的List转换为map
+	// 将已存在的题目转换为map(重建关联时需保留固定的版本)
 	existIDMap := make(map[int]bool)
-	for _, id := range existQuestionIDs {
-		existIDMap[id] = true
+	revisionMap := make(map[int]int, len(existQuestions))
+	for _, q := range existQuestions {
+		existIDMap[q.QuestionID] = true
+		revisionMap[q.QuestionID] = q.RevisionID
 	}
 
 	// 检查req中的每个题目ID是否都存在于existQuestionIDs中
@@ -105,6 +193,7 @@ func (s *PaperService) UpdatePaperQuestionOrder(c context.Context, paperID int,
 			QuestionID:    question.QuestionID,
 			QuestionOrder: question.QuestionOrder,
 			Score:         question.Score,
+			RevisionID:    revisionMap[question.QuestionID],
 		})
 		if !existIDMap[question.QuestionID] {
 			return errors.New("某些题目不存在")
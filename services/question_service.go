@@ -1,51 +1,609 @@
 package services
 
 import (
+	"aiquiz/ai"
+	"aiquiz/config"
 	"aiquiz/dao"
 	"aiquiz/dao/model"
 	"aiquiz/models/dto"
+	"aiquiz/utils"
+	"aiquiz/utils/enums"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"reflect"
 	"strconv"
+	"strings"
 )
 
 type QuestionService struct {
-	questionDao *dao.QuestionDao
+	questionDao   *dao.QuestionDao
+	experimentDao *dao.ExperimentDao
+	tagDao        *dao.TagDao
+	revisionDao   *dao.QuestionRevisionDao
+	collectionDao *dao.CollectionDao
+	attachmentDao *dao.AttachmentDao
+	feedbackDao   *dao.FeedbackDao
+	// codeRunService 运行代码核对输出类题目的答案
+	codeRunService *CodeRunService
+	// duplicateService 检测与题库中已有题目可能重复的题目
+	duplicateService *DuplicateService
+	// knowledgePointService 题目与知识点的关联及模型推荐知识点
+	knowledgePointService *KnowledgePointService
 }
 
-func NewQuestionService(questionDAO *dao.QuestionDao) *QuestionService {
+func NewQuestionService(
+	questionDAO *dao.QuestionDao,
+	experimentDao *dao.ExperimentDao,
+	tagDao *dao.TagDao,
+	revisionDao *dao.QuestionRevisionDao,
+	collectionDao *dao.CollectionDao,
+	attachmentDao *dao.AttachmentDao,
+	feedbackDao *dao.FeedbackDao,
+	codeRunService *CodeRunService,
+	duplicateService *DuplicateService,
+	knowledgePointService *KnowledgePointService,
+) *QuestionService {
 	return &QuestionService{
-		questionDao: questionDAO,
+		questionDao:           questionDAO,
+		experimentDao:         experimentDao,
+		tagDao:                tagDao,
+		revisionDao:           revisionDao,
+		collectionDao:         collectionDao,
+		attachmentDao:         attachmentDao,
+		feedbackDao:           feedbackDao,
+		codeRunService:        codeRunService,
+		duplicateService:      duplicateService,
+		knowledgePointService: knowledgePointService,
 	}
 }
 
-func (s *QuestionService) ConfirmQuestions(c context.Context, questions *[]model.Question) error {
-	return s.questionDao.AddQuestions(c, questions)
+// GenerateQuestions 调用ai模型生成题目，若有生效的实验则按用户分组替换提示词模板或模型，并记录每道题目以便统计采纳率
+func (s *QuestionService) GenerateQuestions(c context.Context, userID int, req *dto.GenerateQuestionReq) ([]dto.GenerateQuestionRes, error) {
+	aiModel := string(req.AiModel)
+	promptTemplate := ai.PromptTemplateDefault
+	variant := enums.VariantControl
+	experimentID := 0
+
+	experiment, err := s.experimentDao.GetActiveExperiment(c)
+	if err != nil {
+		return nil, fmt.Errorf("获取实验配置失败: %w", err)
+	}
+	if experiment != nil {
+		experimentID = experiment.ID
+		variant = assignVariant(experiment, userID)
+		if variant == enums.VariantTreatment {
+			if experiment.VariantPrompt != "" {
+				promptTemplate = experiment.VariantPrompt
+			}
+			if experiment.VariantModel != "" {
+				aiModel = experiment.VariantModel
+			}
+		}
+	}
+
+	generated, err := ai.GenerateQuestionsWithPrompt(aiModel, promptTemplate, req.Language, string(req.QuestionType), req.Keywords, req.Count)
+	if err != nil {
+		return nil, err
+	}
+	if generated == nil || len(generated.Questions) == 0 {
+		return nil, errors.New("模型未返回题目")
+	}
+
+	// 内容审核：reject 模式下直接剔除未通过的题目，flag 模式下保留并附带原因
+	rejectMode := config.GetConfig(false).ModerationAction == ai.ModerationReject
+	questions := make([]dto.Question, 0, len(generated.Questions))
+	flagReasons := make([][]string, 0, len(generated.Questions))
+	var rejectedReasons []string
+	for i, question := range generated.Questions {
+		reasons := ai.ModerateQuestion(question, true)
+		if len(reasons) > 0 && rejectMode {
+			rejectedReasons = append(rejectedReasons, reasons...)
+			continue
+		}
+		// 代码无法编译的题目只做标记，由教师判断是否为有意考查编译错误
+		if i < len(generated.CodeIssues) {
+			reasons = append(reasons, generated.CodeIssues[i]...)
+		}
+		// 输出类题目运行代码核对答案，答案与实际运行结果不一致时标记
+		if s.codeRunService.Enabled() {
+			check, err := s.codeRunService.VerifyOutput(c, req.Language, question)
+			if err != nil {
+				log.Printf("运行题目代码失败: %v", err)
+			} else if check.Checked && !check.Matched {
+				reasons = append(reasons, check.Message)
+			}
+		}
+		questions = append(questions, question)
+		flagReasons = append(flagReasons, reasons)
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("生成的题目均未通过内容审核: %s", strings.Join(rejectedReasons, "; "))
+	}
+
+	// 与题库中已有题目可能重复的题目只做标记
+	candidates := make([]DuplicateCandidate, 0, len(questions))
+	for _, question := range questions {
+		candidates = append(candidates, NewDuplicateCandidate(0, req.Language, question.Title, question.Options))
+	}
+	duplicates, err := s.duplicateService.FindDuplicates(c, userID, candidates)
+	if err != nil {
+		log.Printf("检测重复题目失败: %v", err)
+		duplicates = make([][]dto.DuplicateRes, len(questions))
+	}
+	for i := range questions {
+		for _, duplicate := range duplicates[i] {
+			flagReasons[i] = append(flagReasons[i], fmt.Sprintf("可能与已有题目#%d重复(相似度%.0f%%)", duplicate.ID, duplicate.Similarity*100))
+		}
+	}
+
+	// 推荐考查的知识点，失败时不影响本次出题
+	knowledgePoints, err := s.knowledgePointService.SuggestKnowledgePoints(c, aiModel, req.Language, questions)
+	if err != nil {
+		log.Printf("推荐知识点失败: %v", err)
+	}
+	if knowledgePoints == nil {
+		knowledgePoints = make([][]dto.KnowledgePointRefRes, len(questions))
+	}
+
+	records := make([]model.GenerationRecord, 0, len(questions))
+	for _, question := range questions {
+		options, err := json.Marshal(question.Options)
+		if err != nil {
+			return nil, errors.New("选项序列化失败")
+		}
+		records = append(records, model.GenerationRecord{
+			UserID:         userID,
+			ExperimentID:   experimentID,
+			Variant:        string(variant),
+			PromptTemplate: promptTemplate,
+			AiModel:        aiModel,
+			ContentHash:    contentHash(question.Title, string(options), strconv.Itoa(question.Answer), question.Explanation),
+			Outcome:        string(enums.OutcomePending),
+		})
+	}
+	// 生成记录只用于统计，写入失败不影响本次出题
+	if err := s.experimentDao.AddGenerationRecords(c, records); err != nil {
+		log.Printf("保存生成记录失败: %v", err)
+	}
+
+	questionResponseList := make([]dto.GenerateQuestionRes, 0, len(questions))
+	for i, question := range questions {
+		questionResponseList = append(questionResponseList, dto.GenerateQuestionRes{
+			Question:        question,
+			GenerationID:    records[i].ID,
+			Flagged:         len(flagReasons[i]) > 0,
+			FlagReasons:     flagReasons[i],
+			Duplicates:      duplicates[i],
+			KnowledgePoints: knowledgePoints[i],
+			QuestionType:    string(req.QuestionType),
+			Language:        req.Language,
+			AiModel:         aiModel,
+			Keywords:        req.Keywords,
+		})
+	}
+	return questionResponseList, nil
 }
 
-func (s *QuestionService) ListQuestions(c context.Context, userID int, req *dto.ListQuestionsReq) ([]model.Question, int64, error) {
-	return s.questionDao.ListQuestions(c, userID, req.Title, string(req.QuestionType), req.Keywords, req.Language, string(req.AiModel), req.Page)
+// ConfirmQuestions 题目入库，tagNames、generationIDs、knowledgePointIDs 与 questions 按下标对应，
+// generationIDs 中 0 表示非生成题目，用于记录生成题目是否被修改，生成题目的知识点记为模型推荐。
+// 知识点不属于题目的语言时返回 ErrInvalidKnowledgePoint。代码无法编译的题目自动添加错误报告
+func (s *QuestionService) ConfirmQuestions(c context.Context, userID int, questions *[]model.Question, tagNames [][]string, generationIDs []int, knowledgePointIDs [][]int) error {
+	if err := s.confirmQuestions(c, questions, tagNames, generationIDs, knowledgePointIDs, nil); err != nil {
+		return err
+	}
+	s.recordGenerationOutcomes(c, userID, *questions, generationIDs)
+	s.reportCodeIssues(c, *questions)
+	return nil
 }
 
-func (s *QuestionService) UpdateQuestion(c context.Context, useID, questionID int, req dto.UpdateQuestionReq) error {
-	// 构建Question
-	options, err := json.Marshal(req.Options)
+// confirmQuestions 在同一事务中创建标签、保存题目并关联知识点，afterAdd 不为 nil 时在题目保存后于同一事务中执行
+func (s *QuestionService) confirmQuestions(c context.Context, questions *[]model.Question, tagNames [][]string, generationIDs []int, knowledgePointIDs [][]int, afterAdd func(tx *gorm.DB) error) error {
+	submitOnConfirm := config.GetConfig(false).ReviewSubmitOnConfirm
+	pointIDs := make([][]int, len(*questions))
+	sources := make([]enums.KnowledgeSource, len(*questions))
+	for i := range *questions {
+		q := &(*questions)[i]
+		if i < len(knowledgePointIDs) {
+			ids, err := s.knowledgePointService.ValidateIDs(c, q.Language, knowledgePointIDs[i])
+			if err != nil {
+				return fmt.Errorf("第%d题%w", i+1, err)
+			}
+			pointIDs[i] = ids
+		}
+		sources[i] = enums.KnowledgeSourceManual
+		if i < len(generationIDs) && generationIDs[i] != 0 {
+			sources[i] = enums.KnowledgeSourceAI
+		}
+		if q.Visibility == "" {
+			q.Visibility = string(enums.VisibilityPrivate)
+		}
+		if q.Status == "" {
+			q.Status = string(enums.ReviewDraft)
+			if submitOnConfirm {
+				q.Status = string(enums.ReviewInReview)
+			}
+		}
+	}
+	return s.questionDao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		for i := range *questions {
+			var names []string
+			if i < len(tagNames) {
+				names = tagNames[i]
+			}
+			if err := s.attachTags(c, tx, &(*questions)[i], names); err != nil {
+				return err
+			}
+		}
+		if err := s.questionDao.AddQuestions(c, tx, questions); err != nil {
+			return err
+		}
+		if err := s.knowledgePointService.LinkQuestions(c, tx, *questions, pointIDs, sources); err != nil {
+			return err
+		}
+		if afterAdd != nil {
+			return afterAdd(tx)
+		}
+		return nil
+	})
+}
+
+// reportCodeIssues 检查题目中的代码，无法编译的题目以系统身份(ReporterID 为 0)添加未解决的错误报告，
+// 教师确认是有意考查编译错误后可将报告标记为已解决
+func (s *QuestionService) reportCodeIssues(c context.Context, questions []model.Question) {
+	var reports []model.QuestionReport
+	for _, q := range questions {
+		var options []dto.Option
+		if err := json.Unmarshal([]byte(q.Options), &options); err != nil {
+			continue
+		}
+		issues := ai.CheckQuestionCode(q.Language, q.Title, options)
+		if len(issues) == 0 {
+			continue
+		}
+		reports = append(reports, model.QuestionReport{
+			QuestionID:  q.ID,
+			Category:    string(enums.ReportCompileError),
+			Description: strings.Join(issues, "\n"),
+			Status:      string(enums.FeedbackOpen),
+		})
+	}
+	// 报告只用于提示，写入失败不影响题目入库
+	if err := s.feedbackDao.AddReports(c, reports); err != nil {
+		log.Printf("保存代码检查报告失败: %v", err)
+	}
+}
+
+// recordGenerationOutcomes 对比确认内容与生成内容的哈希，标记为原样或修改后确认
+func (s *QuestionService) recordGenerationOutcomes(c context.Context, userID int, questions []model.Question, generationIDs []int) {
+	ids := make([]int, 0, len(generationIDs))
+	for _, id := range generationIDs {
+		if id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	records, err := s.experimentDao.GetPendingGenerationRecords(c, userID, ids)
 	if err != nil {
-		return errors.New("选项序列化失败")
+		log.Printf("查询生成记录失败: %v", err)
+		return
+	}
+	recordMap := make(map[int]model.GenerationRecord, len(records))
+	for _, record := range records {
+		recordMap[record.ID] = record
 	}
+	for i, id := range generationIDs {
+		record, ok := recordMap[id]
+		if !ok || i >= len(questions) {
+			continue
+		}
+		q := questions[i]
+		outcome := enums.OutcomeEdited
+		if contentHash(q.Title, q.Options, q.Answer, q.Explanation) == record.ContentHash {
+			outcome = enums.OutcomeUnedited
+		}
+		if err := s.experimentDao.UpdateGenerationOutcome(c, id, outcome, q.ID); err != nil {
+			log.Printf("更新生成记录失败: %v", err)
+		}
+		// 同一条生成记录只计一次
+		delete(recordMap, id)
+	}
+}
+
+// contentHash 题目内容哈希，options 为序列化后的JSON，answer 为字符串形式的位掩码
+func contentHash(title, options, answer, explanation string) string {
+	h := sha256.New()
+	for _, part := range []string{title, options, answer, explanation} {
+		h.Write([]byte(strings.TrimSpace(part)))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// attachTags 关联题目标签：未指定标签时由关键词拆分得到，关键词为空时由标签拼接，保证两者一致。
+// 新标签在 tx 中创建，tx 为 nil 时使用默认连接
+func (s *QuestionService) attachTags(c context.Context, tx *gorm.DB, q *model.Question, names []string) error {
+	if len(names) == 0 {
+		names = utils.SplitKeywords(q.Keywords)
+	}
+	tags, err := s.tagDao.ResolveTags(c, tx, names)
+	if err != nil {
+		return fmt.Errorf("解析标签失败: %w", err)
+	}
+	q.Tags = tags
+	if strings.TrimSpace(q.Keywords) == "" {
+		displayNames := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			displayNames = append(displayNames, tag.DisplayName)
+		}
+		q.Keywords = strings.Join(displayNames, ",")
+	}
+	return nil
+}
+
+// ListQuestions 分页查询用户的题目(userID 为 0 时查询全部)，按集合查询时集合须属于该用户，且包含集合中的共享题目
+func (s *QuestionService) ListQuestions(c context.Context, userID int, req *dto.ListQuestionsReq) ([]model.Question, int64, error) {
+	if req.CollectionID != 0 && userID != 0 {
+		collection, err := s.collectionDao.GetCollection(c, req.CollectionID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if collection.UserID != userID {
+			return nil, 0, gorm.ErrRecordNotFound
+		}
+	}
+	filter, ok, err := s.buildFilter(c, userID, req)
+	if err != nil || !ok {
+		return []model.Question{}, 0, err
+	}
+	if req.CollectionID != 0 && userID != 0 {
+		filter.UserID = 0
+		filter.VisibleTo = userID
+	}
+	return s.questionDao.ListQuestions(c, filter, req.Page)
+}
+
+// buildFilter 将查询请求转换为查询条件，返回 false 表示不可能有匹配的题目
+func (s *QuestionService) buildFilter(c context.Context, userID int, req *dto.ListQuestionsReq) (dao.QuestionFilter, bool, error) {
+	filter := dao.QuestionFilter{
+		UserID:          userID,
+		Title:           req.Title,
+		QuestionType:    string(req.QuestionType),
+		Keywords:        req.Keywords,
+		Language:        req.Language,
+		AiModel:         string(req.AiModel),
+		Difficulty:      string(req.Difficulty),
+		Search:          req.Q,
+		TagMatchAll:     req.TagMode == "and",
+		ExcludeReported: req.ExcludeReported,
+	}
+	if req.Visibility != "" {
+		filter.Visibilities = []string{string(req.Visibility)}
+	}
+	if req.Status != "" {
+		filter.Status = string(req.Status)
+	}
+	if req.CollectionID != 0 {
+		filter.CollectionIDs = []int{req.CollectionID}
+		filter.CollectionOrder = req.CollectionID
+		if req.Subcollections {
+			ids, err := s.collectionDao.SubtreeIDs(c, req.CollectionID)
+			if err != nil {
+				return filter, false, err
+			}
+			filter.CollectionIDs = ids
+			filter.CollectionOrder = 0
+		}
+	}
+	if req.KnowledgePointID != 0 {
+		ids, err := s.knowledgePointService.SubtreeIDs(c, req.KnowledgePointID)
+		if err != nil {
+			return filter, false, err
+		}
+		filter.KnowledgePointIDs = ids
+	}
+	if names := utils.SplitKeywords(req.Tags); len(names) > 0 {
+		ids, err := s.tagDao.FindTagIDs(c, names)
+		if err != nil {
+			return filter, false, err
+		}
+		for _, id := range ids {
+			if id != 0 {
+				filter.TagIDs = append(filter.TagIDs, id)
+			}
+		}
+		// 标签不存在时不可能有匹配的题目(and 模式下任一标签不存在即无结果)
+		if len(filter.TagIDs) == 0 || (filter.TagMatchAll && len(filter.TagIDs) < len(ids)) {
+			return filter, false, nil
+		}
+	}
+	return filter, true, nil
+}
+
+// ListSharedQuestions 分页查询共享题库(全部用户设为 organization 或 public 的题目)，publicOnly 为 true 时只查询 public 的题目
+func (s *QuestionService) ListSharedQuestions(c context.Context, req *dto.ListQuestionsReq, publicOnly bool) ([]model.Question, int64, error) {
+	visibilities := enums.SharedVisibilities
+	if publicOnly {
+		visibilities = []string{string(enums.VisibilityPublic)}
+	}
+	if req.Visibility != "" {
+		allowed := false
+		for _, v := range visibilities {
+			allowed = allowed || v == string(req.Visibility)
+		}
+		if !allowed {
+			return []model.Question{}, 0, nil
+		}
+		visibilities = []string{string(req.Visibility)}
+	}
+	filter, ok, err := s.buildFilter(c, 0, req)
+	if err != nil || !ok {
+		return []model.Question{}, 0, err
+	}
+	filter.Visibilities = visibilities
+	return s.questionDao.ListQuestions(c, filter, req.Page)
+}
+
+func (s *QuestionService) UpdateQuestion(c context.Context, useID, questionID int, req dto.UpdateQuestionReq) error {
+	// 构建Question(未传入的选项与答案保持不变，以便只修改可见范围等字段)
 	question := model.Question{
 		ID:           questionID,
 		Title:        req.Title,
 		Keywords:     req.Keywords,
 		Language:     req.Language,
 		QuestionType: string(req.QuestionType),
-		Answer:       strconv.Itoa(req.Answer),
 		Explanation:  req.Explanation,
-		Options:      string(options),
+		Difficulty:   string(req.Difficulty),
+		Visibility:   string(req.Visibility),
 		UserID:       useID,
 	}
-	return s.questionDao.UpdateQuestion(c, &question)
+	if req.Options != nil {
+		options, err := json.Marshal(req.Options)
+		if err != nil {
+			return errors.New("选项序列化失败")
+		}
+		question.Options = string(options)
+	}
+	if req.Answer != 0 {
+		question.Answer = strconv.Itoa(req.Answer)
+	}
+	// 传入了标签或关键词时同步更新标签
+	if req.Tags != nil || req.Keywords != "" {
+		if err := s.attachTags(c, nil, &question, req.Tags); err != nil {
+			return err
+		}
+		if question.Tags == nil {
+			question.Tags = []model.Tag{}
+		}
+	}
+	return s.questionDao.UpdateQuestion(c, &question, useID)
+}
+
+func (s *QuestionService) ListRevisions(c context.Context, questionID int) ([]model.QuestionRevision, error) {
+	return s.revisionDao.ListRevisions(c, questionID)
+}
+
+// DiffRevisions 逐字段对比题目的两个版本
+func (s *QuestionService) DiffRevisions(c context.Context, questionID int, req *dto.RevisionDiffReq) (*dto.RevisionDiffRes, error) {
+	to, err := s.revisionDao.GetRevision(c, questionID, req.To)
+	if err != nil {
+		return nil, fmt.Errorf("版本 %d 不存在", req.To)
+	}
+	fromRevision := req.From
+	if fromRevision == 0 {
+		fromRevision = to.Revision - 1
+	}
+	if fromRevision < 1 {
+		return nil, errors.New("该版本没有更早的版本可以对比")
+	}
+	from, err := s.revisionDao.GetRevision(c, questionID, fromRevision)
+	if err != nil {
+		return nil, fmt.Errorf("版本 %d 不存在", fromRevision)
+	}
+
+	fromFields, err := revisionFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := revisionFields(to)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.RevisionDiffRes{
+		QuestionID: questionID,
+		From:       from.Revision,
+		To:         to.Revision,
+		Changes:    make([]dto.RevisionFieldDiff, 0),
+	}
+	for i := range fromFields {
+		if !reflect.DeepEqual(fromFields[i].value, toFields[i].value) {
+			res.Changes = append(res.Changes, dto.RevisionFieldDiff{
+				Field: fromFields[i].name,
+				From:  fromFields[i].value,
+				To:    toFields[i].value,
+			})
+		}
+	}
+	return res, nil
+}
+
+type revisionField struct {
+	name  string
+	value interface{}
+}
+
+// revisionFields 按固定顺序展开版本的各字段，选项、答案与标签还原为结构化的值
+func revisionFields(r *model.QuestionRevision) ([]revisionField, error) {
+	var options []dto.Option
+	if err := json.Unmarshal([]byte(r.Options), &options); err != nil {
+		return nil, errors.New("选项反序列化失败")
+	}
+	answer, err := strconv.Atoi(r.Answer)
+	if err != nil {
+		return nil, errors.New("答案转换失败")
+	}
+	tags, err := RevisionTags(r)
+	if err != nil {
+		return nil, err
+	}
+	return []revisionField{
+		{name: "title", value: r.Title},
+		{name: "question_type", value: r.QuestionType},
+		{name: "language", value: r.Language},
+		{name: "options", value: options},
+		{name: "answer", value: answer},
+		{name: "explanation", value: r.Explanation},
+		{name: "keywords", value: r.Keywords},
+		{name: "tags", value: tags},
+	}, nil
+}
+
+// RevisionTags 解析版本中保存的标签名称
+func RevisionTags(r *model.QuestionRevision) ([]string, error) {
+	tags := make([]string, 0)
+	if r.Tags == "" {
+		return tags, nil
+	}
+	if err := json.Unmarshal([]byte(r.Tags), &tags); err != nil {
+		return nil, errors.New("标签反序列化失败")
+	}
+	return tags, nil
+}
+
+// RestoreRevision 将题目恢复为指定版本的内容，恢复本身也会产生一个新版本
+func (s *QuestionService) RestoreRevision(c context.Context, editorID, questionID, revision int) error {
+	r, err := s.revisionDao.GetRevision(c, questionID, revision)
+	if err != nil {
+		return fmt.Errorf("版本 %d 不存在", revision)
+	}
+	tags, err := RevisionTags(r)
+	if err != nil {
+		return err
+	}
+	question := model.Question{
+		ID:           questionID,
+		Title:        r.Title,
+		QuestionType: r.QuestionType,
+		Options:      r.Options,
+		Answer:       r.Answer,
+		Explanation:  r.Explanation,
+		Keywords:     r.Keywords,
+		Language:     r.Language,
+	}
+	if err := s.attachTags(c, nil, &question, tags); err != nil {
+		return err
+	}
+	if question.Tags == nil {
+		question.Tags = []model.Tag{}
+	}
+	return s.questionDao.RestoreQuestion(c, &question, editorID, r.Revision)
 }
 
 func (s *QuestionService) CheckQuestionPermission(c context.Context, userID, questionID int) bool {
@@ -53,6 +611,276 @@ func (s *QuestionService) CheckQuestionPermission(c context.Context, userID, que
 	return err == nil && q != nil
 }
 
+// GetQuestion 获取题目及其标签
+func (s *QuestionService) GetQuestion(c context.Context, questionID int) (*model.Question, error) {
+	return s.questionDao.GetQuestion(c, questionID)
+}
+
+// ForkQuestion 将题目复制到用户自己的题库(私有)，记录来源题目及其创建者，返回新题目
+func (s *QuestionService) ForkQuestion(c context.Context, userID int, source *model.Question) (*model.Question, error) {
+	tagNames := make([]string, 0, len(source.Tags))
+	for _, tag := range source.Tags {
+		tagNames = append(tagNames, tag.DisplayName)
+	}
+	questions := []model.Question{{
+		Title:            source.Title,
+		QuestionType:     source.QuestionType,
+		Options:          source.Options,
+		Answer:           source.Answer,
+		Explanation:      source.Explanation,
+		Keywords:         source.Keywords,
+		Language:         source.Language,
+		AiModel:          source.AiModel,
+		Difficulty:       source.Difficulty,
+		Visibility:       string(enums.VisibilityPrivate),
+		ForkedFromID:     source.ID,
+		ForkedFromUserID: source.UserID,
+		UserID:           userID,
+	}}
+	// 复制的题目引用与原题目相同的附件，并关联相同的知识点，与保存题目在同一事务中完成
+	err := s.confirmQuestions(c, &questions, [][]string{tagNames}, nil, nil, func(tx *gorm.DB) error {
+		if err := s.attachmentDao.CopyQuestionAttachments(c, tx, source.ID, questions[0].ID); err != nil {
+			return err
+		}
+		return s.knowledgePointService.CopyQuestionKnowledgePoints(c, tx, source.ID, questions[0].ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.reportCodeIssues(c, questions)
+	return &questions[0], nil
+}
+
 func (s *QuestionService) DeleteQuestion(c context.Context, questionID int) error {
 	return s.questionDao.DeleteQuestion(c, questionID)
 }
+
+// maxBulkQuestions 单次批量操作最多处理的题目数量
+const maxBulkQuestions = 500
+
+// BulkOperate 对指定ID或筛选结果的题目执行批量操作：逐题校验权限(题目创建者或管理员)，
+// 通过校验的题目在同一事务中处理，返回每道题目的处理结果
+func (s *QuestionService) BulkOperate(c context.Context, userID int, isAdmin bool, req *dto.BulkQuestionReq) (*dto.BulkQuestionRes, error) {
+	if req.Operation == enums.BulkMoveToCollection {
+		if err := s.checkBulkCollections(c, userID, isAdmin, req); err != nil {
+			return nil, err
+		}
+	}
+	ids, err := s.bulkTargetIDs(c, userID, isAdmin, req)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.BulkQuestionRes{Total: len(ids), Results: make([]dto.BulkItemResult, 0, len(ids))}
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	questions, err := s.questionDao.GetQuestionsByIDs(c, ids)
+	if err != nil {
+		return nil, err
+	}
+	questionMap := make(map[int]*model.Question, len(questions))
+	for i := range questions {
+		questionMap[questions[i].ID] = &questions[i]
+	}
+	var allowed []*model.Question
+	for _, id := range ids {
+		q, ok := questionMap[id]
+		switch {
+		case !ok:
+			res.Results = append(res.Results, dto.BulkItemResult{ID: id, Error: "题目不存在"})
+		case !isAdmin && q.UserID != userID:
+			res.Results = append(res.Results, dto.BulkItemResult{ID: id, Error: "无权操作该题目"})
+		default:
+			allowed = append(allowed, q)
+			res.Results = append(res.Results, dto.BulkItemResult{ID: id, Success: true})
+		}
+	}
+	if len(allowed) > 0 {
+		if err := s.applyBulkOperation(c, userID, req, allowed); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range res.Results {
+		if r.Success {
+			res.Succeeded++
+		} else {
+			res.Failed++
+		}
+	}
+	return res, nil
+}
+
+// checkBulkCollections 校验批量加入(移出)的集合存在且属于该用户(管理员不限)
+func (s *QuestionService) checkBulkCollections(c context.Context, userID int, isAdmin bool, req *dto.BulkQuestionReq) error {
+	for _, id := range []int{req.CollectionID, req.FromCollectionID} {
+		if id == 0 {
+			continue
+		}
+		collection, err := s.collectionDao.GetCollection(c, id)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err != nil || (!isAdmin && collection.UserID != userID) {
+			return fmt.Errorf("集合 %d 不存在", id)
+		}
+	}
+	return nil
+}
+
+// bulkTargetIDs 获取批量操作的题目ID(去重)，使用筛选条件时普通用户只匹配自己的题目
+func (s *QuestionService) bulkTargetIDs(c context.Context, userID int, isAdmin bool, req *dto.BulkQuestionReq) ([]int, error) {
+	var ids []int
+	if req.Filter != nil {
+		filterUserID := userID
+		if isAdmin {
+			filterUserID = 0
+		}
+		filter, ok, err := s.buildFilter(c, filterUserID, req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, nil
+		}
+		if ids, err = s.questionDao.ListQuestionIDs(c, filter, maxBulkQuestions+1); err != nil {
+			return nil, err
+		}
+	} else {
+		seen := make(map[int]struct{}, len(req.IDs))
+		for _, id := range req.IDs {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > maxBulkQuestions {
+		return nil, fmt.Errorf("单次最多操作%d道题目，请缩小范围", maxBulkQuestions)
+	}
+	return ids, nil
+}
+
+// applyBulkOperation 在同一事务中对题目执行批量操作，修改标签时同步更新关键词
+func (s *QuestionService) applyBulkOperation(c context.Context, editorID int, req *dto.BulkQuestionReq, questions []*model.Question) error {
+	ids := make([]int, 0, len(questions))
+	for _, q := range questions {
+		ids = append(ids, q.ID)
+	}
+	// 关键词中的每一项解析为标签ID(含别名)，用于判断关键词与标签是否对应
+	keywordTagIDs := make(map[int][]int, len(questions))
+	if req.Operation == enums.BulkAddTags || req.Operation == enums.BulkRemoveTags {
+		for _, q := range questions {
+			tagIDs, err := s.tagDao.FindTagIDs(c, utils.SplitKeywords(q.Keywords))
+			if err != nil {
+				return err
+			}
+			keywordTagIDs[q.ID] = tagIDs
+		}
+	}
+
+	return s.questionDao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		switch req.Operation {
+		case enums.BulkDelete:
+			return s.questionDao.DeleteQuestions(c, tx, ids)
+		case enums.BulkMoveToCollection:
+			// 集合不属于题目内容，无需保存新版本
+			if _, err := s.collectionDao.AddQuestions(c, tx, req.CollectionID, ids); err != nil {
+				return err
+			}
+			if req.FromCollectionID != 0 && req.FromCollectionID != req.CollectionID {
+				_, err := s.collectionDao.RemoveQuestions(c, tx, req.FromCollectionID, ids)
+				return err
+			}
+			return nil
+		case enums.BulkSetDifficulty:
+			return s.questionDao.UpdateQuestionsColumn(c, tx, ids, "difficulty", string(req.Difficulty))
+		case enums.BulkSetVisibility:
+			return s.questionDao.UpdateQuestionsColumn(c, tx, ids, "visibility", string(req.Visibility))
+		case enums.BulkSetLanguage:
+			if err := s.questionDao.UpdateQuestionsColumn(c, tx, ids, "language", req.Language); err != nil {
+				return err
+			}
+		case enums.BulkAddTags, enums.BulkRemoveTags:
+			tags, err := s.bulkTags(c, tx, req)
+			if err != nil {
+				return err
+			}
+			tagIDs := make([]int, 0, len(tags))
+			for _, tag := range tags {
+				tagIDs = append(tagIDs, tag.ID)
+			}
+			if req.Operation == enums.BulkAddTags {
+				err = s.questionDao.AddQuestionTags(c, tx, ids, tagIDs)
+			} else {
+				err = s.questionDao.RemoveQuestionTags(c, tx, ids, tagIDs)
+			}
+			if err != nil {
+				return err
+			}
+			for _, q := range questions {
+				keywords := bulkKeywords(q.Keywords, keywordTagIDs[q.ID], tags, req.Operation == enums.BulkAddTags)
+				if keywords == q.Keywords {
+					continue
+				}
+				if err := s.questionDao.UpdateQuestionsColumn(c, tx, []int{q.ID}, "keywords", keywords); err != nil {
+					return err
+				}
+			}
+		}
+		// 修改了版本记录中的内容，保存新版本并更新全文索引
+		return s.questionDao.SnapshotQuestions(c, tx, questions, editorID)
+	})
+}
+
+// bulkTags 解析批量操作的标签：添加时不存在的标签会被创建，移除时忽略不存在的标签
+func (s *QuestionService) bulkTags(c context.Context, tx *gorm.DB, req *dto.BulkQuestionReq) ([]model.Tag, error) {
+	if req.Operation == enums.BulkAddTags {
+		tags, err := s.tagDao.ResolveTags(c, tx, req.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("解析标签失败: %w", err)
+		}
+		return tags, nil
+	}
+	ids, err := s.tagDao.FindTagIDs(c, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]model.Tag, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 {
+			tags = append(tags, model.Tag{ID: id})
+		}
+	}
+	return tags, nil
+}
+
+// bulkKeywords 添加标签时把关键词中缺少的标签追加到末尾，移除标签时删除对应(含别名)的关键词
+func bulkKeywords(keywords string, keywordTagIDs []int, tags []model.Tag, add bool) string {
+	entries := utils.SplitKeywords(keywords)
+	tagSet := make(map[int]struct{}, len(tags))
+	for _, tag := range tags {
+		tagSet[tag.ID] = struct{}{}
+	}
+	if add {
+		present := make(map[int]struct{}, len(keywordTagIDs))
+		for _, id := range keywordTagIDs {
+			present[id] = struct{}{}
+		}
+		for _, tag := range tags {
+			if _, ok := present[tag.ID]; !ok {
+				entries = append(entries, tag.DisplayName)
+			}
+		}
+	} else {
+		kept := make([]string, 0, len(entries))
+		for i, entry := range entries {
+			if _, ok := tagSet[keywordTagIDs[i]]; !ok {
+				kept = append(kept, entry)
+			}
+		}
+		entries = kept
+	}
+	return strings.Join(entries, ",")
+}
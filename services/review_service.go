@@ -0,0 +1,86 @@
+package services
+
+import (
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils/enums"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+type ReviewService struct {
+	questionService *QuestionService
+	questionDao     *dao.QuestionDao
+	reviewDao       *dao.QuestionReviewDao
+}
+
+func NewReviewService(questionService *QuestionService, questionDao *dao.QuestionDao, reviewDao *dao.QuestionReviewDao) *ReviewService {
+	return &ReviewService{questionService: questionService, questionDao: questionDao, reviewDao: reviewDao}
+}
+
+// reviewTransitions 各审核操作允许的起始状态与目标状态
+var reviewTransitions = map[enums.ReviewAction]struct {
+	from []enums.ReviewStatus
+	to   enums.ReviewStatus
+}{
+	enums.ReviewSubmit:  {from: []enums.ReviewStatus{enums.ReviewDraft, enums.ReviewRejected}, to: enums.ReviewInReview},
+	enums.ReviewApprove: {from: []enums.ReviewStatus{enums.ReviewInReview}, to: enums.ReviewApproved},
+	enums.ReviewReject:  {from: []enums.ReviewStatus{enums.ReviewInReview}, to: enums.ReviewRejected},
+}
+
+var reviewStatusNames = map[string]string{
+	string(enums.ReviewDraft):    "草稿",
+	string(enums.ReviewInReview): "待审核",
+	string(enums.ReviewApproved): "审核通过",
+	string(enums.ReviewRejected): "审核驳回",
+}
+
+// ChangeStatus 执行审核操作(提交、通过、驳回)并记录，当前状态不允许该操作时返回错误
+func (s *ReviewService) ChangeStatus(c context.Context, operatorID int, q *model.Question, action enums.ReviewAction, comment string) error {
+	transition, ok := reviewTransitions[action]
+	if !ok {
+		return fmt.Errorf("不支持的审核操作 %s", action)
+	}
+	allowed := false
+	for _, from := range transition.from {
+		allowed = allowed || q.Status == string(from)
+	}
+	if !allowed {
+		return fmt.Errorf("题目当前状态为%s，不能执行该操作", reviewStatusNames[q.Status])
+	}
+	return s.questionDao.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		updated, err := s.reviewDao.UpdateStatus(c, tx, q.ID, enums.ReviewStatus(q.Status), transition.to)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return errors.New("题目状态已变化，请刷新后重试")
+		}
+		return s.reviewDao.AddReview(c, tx, &model.QuestionReview{
+			QuestionID: q.ID,
+			ReviewerID: operatorID,
+			Action:     string(action),
+			FromStatus: q.Status,
+			ToStatus:   string(transition.to),
+			Comment:    comment,
+		})
+	})
+}
+
+// ListQueue 分页查询待审核的题目，按筛选条件过滤
+func (s *ReviewService) ListQueue(c context.Context, req *dto.ListQuestionsReq) ([]model.Question, int64, error) {
+	filter, ok, err := s.questionService.buildFilter(c, 0, req)
+	if err != nil || !ok {
+		return []model.Question{}, 0, err
+	}
+	filter.Status = string(enums.ReviewInReview)
+	return s.questionDao.ListQuestions(c, filter, req.Page)
+}
+
+// ListReviews 获取题目的审核记录
+func (s *ReviewService) ListReviews(c context.Context, questionID int) ([]model.QuestionReview, error) {
+	return s.reviewDao.ListReviews(c, questionID)
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"aiquiz/ai"
+	"aiquiz/config"
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// embeddingBackfillInterval 补算题目向量的执行间隔
+	embeddingBackfillInterval = time.Minute
+	// maxEmbeddingRunes 计算向量时文本的最大长度(字符数)，超出部分截断
+	maxEmbeddingRunes = 2000
+	// defaultSemanticLimit 语义检索默认返回的题目数量
+	defaultSemanticLimit = 10
+	// maxSemanticLimit 语义检索最多返回的题目数量
+	maxSemanticLimit = 50
+)
+
+// ErrEmbeddingPending 题目的向量尚未计算
+var ErrEmbeddingPending = errors.New("题目向量尚未计算")
+
+// SemanticService 基于题目向量的语义检索。向量由后台任务补算，检索时在内存中逐一计算余弦相似度
+type SemanticService struct {
+	embeddingDao *dao.EmbeddingDao
+	questionDao  *dao.QuestionDao
+	embedder     ai.Embedder
+
+	mu sync.Mutex
+	// failed 单独计算也失败的题目(如内容超出模型限制)，本次运行期间不再重试，避免阻塞其余题目
+	failed map[int]bool
+}
+
+func NewSemanticService(embeddingDao *dao.EmbeddingDao, questionDao *dao.QuestionDao, embedder ai.Embedder) *SemanticService {
+	return &SemanticService{embeddingDao: embeddingDao, questionDao: questionDao, embedder: embedder, failed: make(map[int]bool)}
+}
+
+// Enabled 是否开启了语义检索
+func (s *SemanticService) Enabled() bool {
+	return config.GetConfig(false).EmbeddingEnabled
+}
+
+type semanticMatch struct {
+	questionID int
+	score      float64
+}
+
+// Search 在用户可见的题目中查找与 query 语义相近的题目，按相似度从高到低排列，visibleTo 为 0 时查找全部题目
+func (s *SemanticService) Search(c context.Context, visibleTo int, query, language string, limit int) ([]dto.SemanticQuestionRes, error) {
+	vectors, err := s.embedder.Embed(c, []string{truncateRunes(query, maxEmbeddingRunes)})
+	if err != nil {
+		return nil, err
+	}
+	return s.nearest(c, utils.NormalizeVector(vectors[0]), language, visibleTo, 0, limit)
+}
+
+// Similar 在用户可见的题目中查找与指定题目语言相同、语义相近的题目，题目向量尚未计算时返回 ErrEmbeddingPending
+func (s *SemanticService) Similar(c context.Context, visibleTo int, q *model.Question, limit int) ([]dto.SemanticQuestionRes, error) {
+	embedding, err := s.embeddingDao.GetEmbedding(c, q.ID, s.embedder.Model())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmbeddingPending
+		}
+		return nil, err
+	}
+	return s.nearest(c, utils.DecodeVector(embedding.Vector), q.Language, visibleTo, q.ID, limit)
+}
+
+// nearest 按余弦相似度取最相近的 limit 道题目，跳过 excludeID
+func (s *SemanticService) nearest(c context.Context, vector []float32, language string, visibleTo, excludeID, limit int) ([]dto.SemanticQuestionRes, error) {
+	if limit <= 0 {
+		limit = defaultSemanticLimit
+	}
+	limit = min(limit, maxSemanticLimit)
+	embeddings, err := s.embeddingDao.ListEmbeddings(c, s.embedder.Model(), language, visibleTo)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]semanticMatch, 0, len(embeddings))
+	for _, e := range embeddings {
+		if e.QuestionID == excludeID {
+			continue
+		}
+		matches = append(matches, semanticMatch{questionID: e.QuestionID, score: utils.CosineSimilarity(vector, utils.DecodeVector(e.Vector))})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make([]dto.SemanticQuestionRes, 0, len(matches))
+	if len(matches) == 0 {
+		return result, nil
+	}
+
+	ids := make([]int, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.questionID)
+	}
+	questions, err := s.questionDao.GetQuestionsByIDs(c, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]model.Question, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+	for _, m := range matches {
+		q, ok := byID[m.questionID]
+		if !ok {
+			continue
+		}
+		result = append(result, dto.SemanticQuestionRes{
+			ID:           q.ID,
+			Title:        q.Title,
+			QuestionType: q.QuestionType,
+			Language:     q.Language,
+			Keywords:     q.Keywords,
+			Difficulty:   q.Difficulty,
+			UserID:       q.UserID,
+			Score:        math.Round(m.score*1000) / 1000,
+		})
+	}
+	return result, nil
+}
+
+// Backfill 为还没有向量(或向量由其他模型计算)的题目计算向量，直到全部完成。
+// 一批计算失败时逐题重试，单独计算失败的题目记录后跳过；全部失败时视为服务不可用并返回错误
+func (s *SemanticService) Backfill(c context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batchSize := max(config.GetConfig(false).EmbeddingBatchSize, 1)
+	embeddingModel := s.embedder.Model()
+	for {
+		excluded := make([]int, 0, len(s.failed))
+		for id := range s.failed {
+			excluded = append(excluded, id)
+		}
+		pending, err := s.embeddingDao.ListPending(c, embeddingModel, excluded, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		embeddings, err := s.embed(c, embeddingModel, pending)
+		if err != nil {
+			if len(pending) == 1 {
+				return err
+			}
+			embeddings = embeddings[:0]
+			var lastErr error
+			for _, p := range pending {
+				single, err := s.embed(c, embeddingModel, []dao.PendingEmbedding{p})
+				if err != nil {
+					lastErr = err
+					continue
+				}
+				embeddings = append(embeddings, single...)
+			}
+			if len(embeddings) == 0 {
+				return lastErr
+			}
+			saved := make(map[int]bool, len(embeddings))
+			for _, e := range embeddings {
+				saved[e.QuestionID] = true
+			}
+			for _, p := range pending {
+				if !saved[p.QuestionID] {
+					log.Printf("计算题目 %d 的向量失败，本次运行不再重试: %v", p.QuestionID, lastErr)
+					s.failed[p.QuestionID] = true
+				}
+			}
+		}
+		if err := s.embeddingDao.SaveEmbeddings(c, embeddings); err != nil {
+			return err
+		}
+	}
+}
+
+// embed 计算一批题目的单位向量
+func (s *SemanticService) embed(c context.Context, embeddingModel string, pending []dao.PendingEmbedding) ([]model.QuestionEmbedding, error) {
+	texts := make([]string, 0, len(pending))
+	for _, p := range pending {
+		texts = append(texts, truncateRunes(p.Text, maxEmbeddingRunes))
+	}
+	vectors, err := s.embedder.Embed(c, texts)
+	if err != nil {
+		return nil, err
+	}
+	embeddings := make([]model.QuestionEmbedding, 0, len(pending))
+	for i, p := range pending {
+		vector := utils.NormalizeVector(vectors[i])
+		embeddings = append(embeddings, model.QuestionEmbedding{
+			QuestionID:  p.QuestionID,
+			Model:       embeddingModel,
+			ContentHash: p.ContentHash,
+			Dimensions:  len(vector),
+			Vector:      utils.EncodeVector(vector),
+		})
+	}
+	return embeddings, nil
+}
+
+// RunBackfillJob 开启语义检索时定期补算题目向量，直到 ctx 结束
+func (s *SemanticService) RunBackfillJob(ctx context.Context) {
+	ticker := time.NewTicker(embeddingBackfillInterval)
+	defer ticker.Stop()
+	for {
+		if s.Enabled() {
+			if err := s.Backfill(ctx); err != nil {
+				log.Printf("补算题目向量失败: %v", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func truncateRunes(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
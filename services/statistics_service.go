@@ -1,6 +1,7 @@
 package services
 
 import (
+	"aiquiz/config"
 	"aiquiz/dao"
 	"aiquiz/models/dto"
 	"context"
@@ -8,6 +9,9 @@ import (
 	"time"
 )
 
+// tagDistributionLimit 标签分布只返回题目数量最多的标签
+const tagDistributionLimit = 20
+
 type StatisticsService struct {
 	userDao             *dao.UserDao
 	userStatisticsDao   *dao.UserStatisticsDao
@@ -56,6 +60,18 @@ func (s *StatisticsService) GetUserStatistics(c context.Context, userID int) (*d
 		return nil, fmt.Errorf("统计语言分布失败: %v", err)
 	}
 
+	// 统计标签分布
+	tagDistribution, err := s.userStatisticsDao.GetTagDistribution(c, userID, tagDistributionLimit)
+	if err != nil {
+		return nil, fmt.Errorf("统计标签分布失败: %v", err)
+	}
+
+	// 统计审核状态分布
+	statusDistribution, err := s.userStatisticsDao.GetStatusDistribution(c, userID)
+	if err != nil {
+		return nil, fmt.Errorf("统计审核状态分布失败: %v", err)
+	}
+
 	// 分析活跃时间 (获取最近一年的数据)
 	oneYearAgo := time.Now().AddDate(-1, 0, 0)
 	timeData, err := s.userStatisticsDao.GetActiveTimeData(c, userID, oneYearAgo)
@@ -73,6 +89,8 @@ func (s *StatisticsService) GetUserStatistics(c context.Context, userID int) (*d
 		PaperCount:               paperCount,
 		QuestionTypeDistribution: typeDistribution,
 		LanguageDistribution:     languageDistribution,
+		TagDistribution:          tagDistribution,
+		StatusDistribution:       statusDistribution,
 		ActiveTimeAnalysis:       activeTimeAnalysis,
 	}, nil
 }
@@ -131,12 +149,30 @@ func (s *StatisticsService) GetSystemStatistics(c context.Context) (*dto.SystemS
 		return nil, fmt.Errorf("获取语言分布失败: %v", err)
 	}
 
+	// 标签分布
+	tagDist, err := s.systemStatisticsDao.GetTagDistribution(c, tagDistributionLimit)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签分布失败: %v", err)
+	}
+
+	// 审核状态分布
+	statusDist, err := s.systemStatisticsDao.GetStatusDistribution(c)
+	if err != nil {
+		return nil, fmt.Errorf("获取审核状态分布失败: %v", err)
+	}
+
 	// AI模型使用情况
 	aiUsage, err := s.systemStatisticsDao.GetAIModelUsage(c)
 	if err != nil {
 		return nil, fmt.Errorf("获取AI模型使用情况失败: %v", err)
 	}
 
+	// 提示词/模型实验结果
+	experimentResults, err := s.GetExperimentResults(c)
+	if err != nil {
+		return nil, err
+	}
+
 	// 试卷题目数量分布
 	paperQuestionDist, err := s.systemStatisticsDao.GetPaperQuestionDistribution(c)
 	if err != nil {
@@ -157,12 +193,34 @@ func (s *StatisticsService) GetSystemStatistics(c context.Context) (*dto.SystemS
 		TotalQuestionCount:        totalQuestion,
 		TotalPaperCount:           totalPaper,
 		LanguageDistribution:      languageDist,
+		TagDistribution:           tagDist,
+		StatusDistribution:        statusDist,
 		AIModelUsage:              aiUsage,
+		ExperimentResults:         experimentResults,
 		PaperQuestionDistribution: paperQuestionDist,
 		ActivityAnalysis:          activityAnalysis,
 	}, nil
 }
 
+// GetExperimentResults 获取实验结果并计算采纳率
+func (s *StatisticsService) GetExperimentResults(c context.Context) ([]dto.ExperimentResult, error) {
+	discardHours := config.GetConfig(false).ExperimentDiscardHours
+	discardBefore := time.Now().Add(-time.Duration(discardHours) * time.Hour)
+	results, err := s.systemStatisticsDao.GetExperimentResults(c, discardBefore)
+	if err != nil {
+		return nil, fmt.Errorf("获取实验结果失败: %v", err)
+	}
+	for i := range results {
+		r := &results[i]
+		// 仍在等待确认的题目不计入分母
+		if decided := r.Generated - r.Pending; decided > 0 {
+			r.AcceptanceRate = float64(r.Unedited+r.Edited) / float64(decided)
+			r.UneditedRate = float64(r.Unedited) / float64(decided)
+		}
+	}
+	return results, nil
+}
+
 // analyzeSystemActivity 分析系统活跃度
 func (s *StatisticsService) analyzeSystemActivity(times []time.Time) dto.SystemActivityAnalysis {
 	analysis := dto.SystemActivityAnalysis{
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"aiquiz/utils"
+	"aiquiz/utils/enums"
+	"context"
+	"math"
+	"strings"
+)
+
+const (
+	// coverageTolerance 主题实际分值与目标分值的允许偏差比例，偏差内视为已达标
+	coverageTolerance = 0.1
+	// suggestionLimit 每个主题最多推荐的题目数量
+	suggestionLimit = 5
+)
+
+type SyllabusService struct {
+	syllabusDao *dao.SyllabusDao
+	tagDao      *dao.TagDao
+}
+
+func NewSyllabusService(syllabusDao *dao.SyllabusDao, tagDao *dao.TagDao) *SyllabusService {
+	return &SyllabusService{syllabusDao: syllabusDao, tagDao: tagDao}
+}
+
+// CreateSyllabus 创建课程大纲及其主题
+func (s *SyllabusService) CreateSyllabus(c context.Context, userID int, req *dto.SyllabusReq) (*model.Syllabus, error) {
+	syllabus := &model.Syllabus{
+		Name:        req.Name,
+		Description: req.Description,
+		Language:    req.Language,
+		UserID:      userID,
+		Topics:      toSyllabusTopics(req.Topics),
+	}
+	if err := s.syllabusDao.CreateSyllabus(c, syllabus); err != nil {
+		return nil, err
+	}
+	return syllabus, nil
+}
+
+func (s *SyllabusService) GetSyllabus(c context.Context, syllabusID int) (*model.Syllabus, error) {
+	return s.syllabusDao.GetSyllabus(c, syllabusID)
+}
+
+// ListSyllabi 获取用户的全部课程大纲
+func (s *SyllabusService) ListSyllabi(c context.Context, userID int) ([]dto.SyllabusRes, error) {
+	syllabi, err := s.syllabusDao.ListSyllabi(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]dto.SyllabusRes, 0, len(syllabi))
+	for _, syllabus := range syllabi {
+		res = append(res, ToSyllabusRes(&syllabus))
+	}
+	return res, nil
+}
+
+// UpdateSyllabus 修改课程大纲，主题以请求中的列表整体替换
+func (s *SyllabusService) UpdateSyllabus(c context.Context, syllabus *model.Syllabus, req *dto.SyllabusReq) error {
+	updated := *syllabus
+	updated.Name = req.Name
+	updated.Description = req.Description
+	updated.Language = req.Language
+	updated.Topics = toSyllabusTopics(req.Topics)
+	return s.syllabusDao.UpdateSyllabus(c, &updated)
+}
+
+func (s *SyllabusService) DeleteSyllabus(c context.Context, syllabusID int) error {
+	return s.syllabusDao.DeleteSyllabus(c, syllabusID)
+}
+
+// toSyllabusTopics 将请求中的主题转换为模型，标签与关键词去重后以逗号分隔保存
+func toSyllabusTopics(topics []dto.SyllabusTopicReq) []model.SyllabusTopic {
+	result := make([]model.SyllabusTopic, 0, len(topics))
+	for i, topic := range topics {
+		result = append(result, model.SyllabusTopic{
+			Name:     topic.Name,
+			Tags:     strings.Join(utils.SplitKeywords(strings.Join(topic.Tags, ",")), ","),
+			Keywords: strings.Join(utils.SplitKeywords(strings.Join(topic.Keywords, ",")), ","),
+			Weight:   topic.Weight,
+			Position: i + 1,
+		})
+	}
+	return result
+}
+
+// topicMatcher 判断题目是否属于大纲主题
+type topicMatcher struct {
+	tagIDs   map[int]struct{}
+	keywords []string // 归一化后的关键词
+}
+
+// newTopicMatcher 解析主题的标签(含别名)与关键词，不存在的标签忽略
+func (s *SyllabusService) newTopicMatcher(c context.Context, topic *model.SyllabusTopic) (*topicMatcher, error) {
+	ids, err := s.tagDao.FindTagIDs(c, utils.SplitKeywords(topic.Tags))
+	if err != nil {
+		return nil, err
+	}
+	matcher := &topicMatcher{tagIDs: make(map[int]struct{}, len(ids))}
+	for _, id := range ids {
+		if id != 0 {
+			matcher.tagIDs[id] = struct{}{}
+		}
+	}
+	for _, keyword := range utils.SplitKeywords(topic.Keywords) {
+		matcher.keywords = append(matcher.keywords, utils.NormalizeTag(keyword))
+	}
+	return matcher, nil
+}
+
+// match 题目带有主题的任一标签，或题目的某个关键词包含主题的任一关键词
+func (m *topicMatcher) match(question *model.Question) bool {
+	for _, tag := range question.Tags {
+		if _, ok := m.tagIDs[tag.ID]; ok {
+			return true
+		}
+	}
+	if len(m.keywords) == 0 {
+		return false
+	}
+	for _, keyword := range utils.SplitKeywords(question.Keywords) {
+		normalized := utils.NormalizeTag(keyword)
+		for _, topicKeyword := range m.keywords {
+			if strings.Contains(normalized, topicKeyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *topicMatcher) tagIDList() []int {
+	ids := make([]int, 0, len(m.tagIDs))
+	for id := range m.tagIDs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// PaperCoverage 对比试卷中各主题的题目分值与按权重分配的目标分值，
+// 为未覆盖或分值不足的主题推荐 visibleTo 可见(0 表示不限)且不在试卷中的题库题目
+func (s *SyllabusService) PaperCoverage(c context.Context, paperID int, syllabus *model.Syllabus, visibleTo int) (*dto.SyllabusCoverageRes, error) {
+	paper, err := s.syllabusDao.GetPaper(c, paperID)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.SyllabusCoverageRes{
+		PaperID:            paper.ID,
+		SyllabusID:         syllabus.ID,
+		SyllabusName:       syllabus.Name,
+		TotalScore:         paper.TotalScore,
+		QuestionCount:      len(paper.Questions),
+		UnmatchedQuestions: []int{},
+		Topics:             []dto.TopicCoverageRes{},
+	}
+	paperQuestionIDs := make([]int, 0, len(paper.Questions))
+	for _, pq := range paper.Questions {
+		res.QuestionScore += pq.Score
+		paperQuestionIDs = append(paperQuestionIDs, pq.QuestionID)
+	}
+	// 未设置试卷总分时以题目分值之和计算目标分值
+	if res.TotalScore <= 0 {
+		res.TotalScore = res.QuestionScore
+	}
+	totalWeight := 0
+	for _, topic := range syllabus.Topics {
+		totalWeight += topic.Weight
+	}
+
+	matched := make(map[int]bool, len(paper.Questions))
+	for i := range syllabus.Topics {
+		topic := &syllabus.Topics[i]
+		matcher, err := s.newTopicMatcher(c, topic)
+		if err != nil {
+			return nil, err
+		}
+		coverage := dto.TopicCoverageRes{
+			TopicID:     topic.ID,
+			Name:        topic.Name,
+			Weight:      topic.Weight,
+			QuestionIDs: []int{},
+		}
+		for _, pq := range paper.Questions {
+			if pq.Question == nil || !matcher.match(pq.Question) {
+				continue
+			}
+			coverage.ActualScore += pq.Score
+			coverage.QuestionIDs = append(coverage.QuestionIDs, pq.QuestionID)
+			matched[pq.QuestionID] = true
+		}
+		if totalWeight > 0 {
+			coverage.TargetPercent = roundTo(float64(topic.Weight)*100/float64(totalWeight), 1)
+			coverage.TargetScore = roundTo(float64(res.TotalScore)*float64(topic.Weight)/float64(totalWeight), 2)
+		}
+		if res.TotalScore > 0 {
+			coverage.ActualPercent = roundTo(float64(coverage.ActualScore)*100/float64(res.TotalScore), 1)
+		}
+		coverage.Gap = roundTo(coverage.TargetScore-float64(coverage.ActualScore), 2)
+		coverage.Status = string(coverageStatus(float64(coverage.ActualScore), coverage.TargetScore))
+
+		if coverage.Status == string(enums.CoverageMissing) || coverage.Status == string(enums.CoverageUnder) {
+			coverage.Suggestions, err = s.suggestQuestions(c, matcher, syllabus.Language, visibleTo, paperQuestionIDs)
+			if err != nil {
+				return nil, err
+			}
+		}
+		res.Topics = append(res.Topics, coverage)
+	}
+	for _, pq := range paper.Questions {
+		if !matched[pq.QuestionID] {
+			res.UnmatchedScore += pq.Score
+			res.UnmatchedQuestions = append(res.UnmatchedQuestions, pq.QuestionID)
+		}
+	}
+	return res, nil
+}
+
+// coverageStatus 按允许偏差判断主题的覆盖状态
+func coverageStatus(actual, target float64) enums.CoverageStatus {
+	switch {
+	case actual == 0:
+		return enums.CoverageMissing
+	case actual < target*(1-coverageTolerance):
+		return enums.CoverageUnder
+	case actual > target*(1+coverageTolerance):
+		return enums.CoverageOver
+	default:
+		return enums.CoverageOK
+	}
+}
+
+// suggestQuestions 查找属于主题的题库题目，数据库按关键词模糊匹配后再按题目的关键词逐个复核
+func (s *SyllabusService) suggestQuestions(c context.Context, matcher *topicMatcher, language string, visibleTo int, excludeIDs []int) ([]dto.SyllabusSuggestionRes, error) {
+	candidates, err := s.syllabusDao.ListTopicCandidates(c, dao.TopicCandidateFilter{
+		VisibleTo:  visibleTo,
+		Language:   language,
+		TagIDs:     matcher.tagIDList(),
+		Keywords:   matcher.keywords,
+		ExcludeIDs: excludeIDs,
+		Limit:      suggestionLimit * 4,
+	})
+	if err != nil {
+		return nil, err
+	}
+	suggestions := make([]dto.SyllabusSuggestionRes, 0, suggestionLimit)
+	for _, question := range candidates {
+		if len(suggestions) == suggestionLimit {
+			break
+		}
+		if !matcher.match(&question) {
+			continue
+		}
+		tags := make([]string, 0, len(question.Tags))
+		for _, tag := range question.Tags {
+			tags = append(tags, tag.DisplayName)
+		}
+		suggestions = append(suggestions, dto.SyllabusSuggestionRes{
+			ID:           question.ID,
+			Title:        question.Title,
+			QuestionType: question.QuestionType,
+			Language:     question.Language,
+			Keywords:     question.Keywords,
+			Difficulty:   question.Difficulty,
+			Status:       question.Status,
+			UserID:       question.UserID,
+			Tags:         tags,
+		})
+	}
+	return suggestions, nil
+}
+
+func roundTo(value float64, digits int) float64 {
+	scale := math.Pow(10, float64(digits))
+	return math.Round(value*scale) / scale
+}
+
+func ToSyllabusRes(syllabus *model.Syllabus) dto.SyllabusRes {
+	topics := make([]dto.SyllabusTopicRes, 0, len(syllabus.Topics))
+	for _, topic := range syllabus.Topics {
+		topics = append(topics, dto.SyllabusTopicRes{
+			ID:       topic.ID,
+			Name:     topic.Name,
+			Tags:     utils.SplitKeywords(topic.Tags),
+			Keywords: utils.SplitKeywords(topic.Keywords),
+			Weight:   topic.Weight,
+		})
+	}
+	return dto.SyllabusRes{
+		ID:          syllabus.ID,
+		Name:        syllabus.Name,
+		Description: syllabus.Description,
+		Language:    syllabus.Language,
+		UserID:      syllabus.UserID,
+		CreatedAt:   syllabus.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   syllabus.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Topics:      topics,
+	}
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"context"
+)
+
+type TagService struct {
+	tagDao *dao.TagDao
+}
+
+func NewTagService(tagDao *dao.TagDao) *TagService {
+	return &TagService{tagDao: tagDao}
+}
+
+func (s *TagService) ListTags(c context.Context, req *dto.ListTagsReq) ([]dto.TagRes, int64, error) {
+	return s.tagDao.ListTags(c, req.Name, req.Page)
+}
+
+func (s *TagService) CreateTag(c context.Context, req *dto.TagReq) (*model.Tag, error) {
+	return s.tagDao.CreateTag(c, req.Name)
+}
+
+func (s *TagService) RenameTag(c context.Context, tagID int, req *dto.TagReq) error {
+	if _, err := s.tagDao.GetTag(c, tagID); err != nil {
+		return err
+	}
+	return s.tagDao.RenameTag(c, tagID, req.Name)
+}
+
+func (s *TagService) DeleteTag(c context.Context, tagID int) error {
+	return s.tagDao.DeleteTag(c, tagID)
+}
+
+// AddAlias 添加别名，别名已是其他标签时会把该标签合并到当前标签
+func (s *TagService) AddAlias(c context.Context, tagID int, req *dto.TagAliasReq) error {
+	return s.tagDao.AddAlias(c, tagID, req.Alias)
+}
+
+func (s *TagService) DeleteAlias(c context.Context, tagID, aliasID int) (bool, error) {
+	return s.tagDao.DeleteAlias(c, tagID, aliasID)
+}
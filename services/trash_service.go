@@ -0,0 +1,128 @@
+package services
+
+import (
+	"aiquiz/config"
+	"aiquiz/dao"
+	"aiquiz/dao/model"
+	"aiquiz/models/dto"
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// trashPurgeInterval 回收站自动清理的执行间隔
+const trashPurgeInterval = time.Hour
+
+type TrashService struct {
+	trashDao *dao.TrashDao
+	userDao  *dao.UserDao
+}
+
+func NewTrashService(trashDao *dao.TrashDao, userDao *dao.UserDao) *TrashService {
+	return &TrashService{trashDao: trashDao, userDao: userDao}
+}
+
+// ExpireAt 计算回收站条目被自动彻底删除的时间，未开启自动清理时返回空字符串
+func (s *TrashService) ExpireAt(deletedAt time.Time) string {
+	days := config.GetConfig(false).TrashRetentionDays
+	if days <= 0 {
+		return ""
+	}
+	return deletedAt.AddDate(0, 0, days).Format("2006-01-02 15:04:05")
+}
+
+func (s *TrashService) ListQuestions(c context.Context, userID int, req *dto.TrashListReq) ([]model.Question, int64, error) {
+	return s.trashDao.ListDeletedQuestions(c, userID, req.Page)
+}
+
+func (s *TrashService) ListPapers(c context.Context, userID int, req *dto.TrashListReq) ([]model.Paper, int64, error) {
+	return s.trashDao.ListDeletedPapers(c, userID, req.Page)
+}
+
+func (s *TrashService) ListPaperQuestions(c context.Context, userID int, req *dto.TrashListReq) ([]dao.DeletedPaperQuestion, int64, error) {
+	return s.trashDao.ListDeletedPaperQuestions(c, userID, req.PaperID, req.Page)
+}
+
+func (s *TrashService) GetQuestion(c context.Context, questionID int) (*model.Question, error) {
+	return s.trashDao.GetDeletedQuestion(c, questionID)
+}
+
+func (s *TrashService) GetPaper(c context.Context, paperID int) (*model.Paper, error) {
+	return s.trashDao.GetDeletedPaper(c, paperID)
+}
+
+// GetPaperQuestion 获取回收站中的试卷题目关联及其所属试卷
+func (s *TrashService) GetPaperQuestion(c context.Context, linkID int) (*model.PaperQuestion, *model.Paper, error) {
+	return s.trashDao.GetDeletedPaperQuestion(c, linkID)
+}
+
+// checkOwner 所属用户已被删除时不允许恢复，避免产生无主的数据
+func (s *TrashService) checkOwner(c context.Context, userID int) error {
+	if _, err := s.userDao.GetUserByID(c, userID); err != nil {
+		return errors.New("所属用户已被删除，无法恢复")
+	}
+	return nil
+}
+
+func (s *TrashService) RestoreQuestion(c context.Context, question *model.Question) error {
+	if err := s.checkOwner(c, question.UserID); err != nil {
+		return err
+	}
+	return s.trashDao.RestoreQuestion(c, question.ID)
+}
+
+func (s *TrashService) RestorePaper(c context.Context, paper *model.Paper) error {
+	if err := s.checkOwner(c, paper.CreatorID); err != nil {
+		return err
+	}
+	return s.trashDao.RestorePaper(c, paper)
+}
+
+func (s *TrashService) RestorePaperQuestion(c context.Context, link *model.PaperQuestion) error {
+	return s.trashDao.RestorePaperQuestion(c, link)
+}
+
+func (s *TrashService) PurgeQuestion(c context.Context, questionID int) error {
+	return s.trashDao.PurgeQuestions(c, []int{questionID})
+}
+
+func (s *TrashService) PurgePaper(c context.Context, paperID int) error {
+	return s.trashDao.PurgePapers(c, []int{paperID})
+}
+
+func (s *TrashService) PurgePaperQuestion(c context.Context, linkID int) error {
+	return s.trashDao.PurgePaperQuestions(c, []int{linkID})
+}
+
+// PurgeExpired 彻底删除超过保留天数的回收站条目
+func (s *TrashService) PurgeExpired(c context.Context) error {
+	days := config.GetConfig(false).TrashRetentionDays
+	if days <= 0 {
+		return nil
+	}
+	papers, links, questions, err := s.trashDao.PurgeExpired(c, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return err
+	}
+	if papers+links+questions > 0 {
+		log.Printf("回收站清理完成: 试卷%d份，试卷题目关联%d条，题目%d道", papers, links, questions)
+	}
+	return nil
+}
+
+// RunRetentionJob 定期清理回收站，直到 ctx 结束
+func (s *TrashService) RunRetentionJob(ctx context.Context) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.PurgeExpired(ctx); err != nil {
+			log.Printf("回收站清理失败: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
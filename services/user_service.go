@@ -4,22 +4,27 @@ import (
 	"aiquiz/dao"
 	"aiquiz/dao/model"
 	"aiquiz/models/dto"
+	"aiquiz/utils/enums"
 	"context"
 	"fmt"
 	"gorm.io/gorm"
 )
 
 type UserService struct {
-	userDao     *dao.UserDao
-	questionDao *dao.QuestionDao
-	paperDao    *dao.PaperDao
+	userDao       *dao.UserDao
+	questionDao   *dao.QuestionDao
+	paperDao      *dao.PaperDao
+	collectionDao *dao.CollectionDao
+	syllabusDao   *dao.SyllabusDao
 }
 
-func NewUserService(userDAO *dao.UserDao, questionDao *dao.QuestionDao, paperDao *dao.PaperDao) *UserService {
+func NewUserService(userDAO *dao.UserDao, questionDao *dao.QuestionDao, paperDao *dao.PaperDao, collectionDao *dao.CollectionDao, syllabusDao *dao.SyllabusDao) *UserService {
 	return &UserService{
-		userDao:     userDAO,
-		questionDao: questionDao,
-		paperDao:    paperDao,
+		userDao:       userDAO,
+		questionDao:   questionDao,
+		paperDao:      paperDao,
+		collectionDao: collectionDao,
+		syllabusDao:   syllabusDao,
 	}
 }
 func (s *UserService) Create(c context.Context, user *model.User) error {
@@ -38,6 +43,11 @@ func (s *UserService) UpdateUser(ctx context.Context, id int, username string, p
 	return s.userDao.UpdateUser(ctx, id, username, password)
 }
 
+// UpdateUserRole 修改用户角色，用户重新登录后生效
+func (s *UserService) UpdateUserRole(c context.Context, id int, role enums.Role) error {
+	return s.userDao.UpdateUserRole(c, id, string(role))
+}
+
 func (s *UserService) DeleteUser(c context.Context, deletedUserID int) error {
 	return s.userDao.DB.Transaction(func(tx *gorm.DB) error {
 		// 删除试卷题目关联表以及试卷表数据
@@ -45,6 +55,16 @@ func (s *UserService) DeleteUser(c context.Context, deletedUserID int) error {
 		if err != nil {
 			return fmt.Errorf("删除试卷失败: %w", err)
 		}
+		// 删除题目集合(集合中的题目关联一并删除)
+		err = s.collectionDao.DeleteCollectionsByUserID(c, tx, deletedUserID)
+		if err != nil {
+			return fmt.Errorf("删除集合失败: %w", err)
+		}
+		// 删除课程大纲及其主题
+		err = s.syllabusDao.DeleteSyllabiByUserID(c, tx, deletedUserID)
+		if err != nil {
+			return fmt.Errorf("删除课程大纲失败: %w", err)
+		}
 		// 删除问题表数据
 		err = s.questionDao.DeleteQuestionByUserID(c, tx, deletedUserID)
 		if err != nil {
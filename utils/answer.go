@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseAnswer 将答案转换为位掩码，支持字母形式(如 "A,C"、"ac"、"A、C")与数字形式的位掩码，
+// optionCount 为选项数量，答案不能为空且只能包含已有的选项
+func ParseAnswer(answer string, optionCount int) (int, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return 0, errors.New("答案不能为空")
+	}
+	all := 1<<optionCount - 1
+	if mask, err := strconv.Atoi(answer); err == nil {
+		if mask <= 0 || mask&^all != 0 {
+			return 0, fmt.Errorf("答案 %s 包含不存在的选项", answer)
+		}
+		return mask, nil
+	}
+	mask := 0
+	for _, r := range answer {
+		switch {
+		case unicode.IsSpace(r) || strings.ContainsRune(",，、;；/|", r):
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+			index := int(unicode.ToUpper(r) - 'A')
+			if index >= optionCount {
+				return 0, fmt.Errorf("答案 %s 包含不存在的选项 %c", answer, unicode.ToUpper(r))
+			}
+			mask |= 1 << index
+		default:
+			return 0, fmt.Errorf("无法识别的答案: %s", answer)
+		}
+	}
+	if mask == 0 {
+		return 0, fmt.Errorf("无法识别的答案: %s", answer)
+	}
+	return mask, nil
+}
@@ -0,0 +1,72 @@
+package codecheck
+
+import (
+	"aiquiz/utils/markdown"
+	"fmt"
+	"sync"
+)
+
+// Checker 某种编程语言的代码检查器
+type Checker interface {
+	// Check 检查一段代码(完整文件或代码片段)，返回发现的问题，为空表示通过
+	Check(code string) []Problem
+}
+
+// Problem 代码中的一个问题，Line 为代码块中的行号(从1开始)，0 表示无法定位到行
+type Problem struct {
+	Line    int
+	Message string
+}
+
+var (
+	mu       sync.RWMutex
+	checkers = map[string]Checker{
+		"go": &GoChecker{},
+	}
+)
+
+// Register 注册代码检查器，language 为代码块的语言标记(见 markdown.LanguageTag)，已存在时覆盖
+func Register(language string, checker Checker) {
+	mu.Lock()
+	defer mu.Unlock()
+	checkers[markdown.LanguageTag(language)] = checker
+}
+
+func lookup(tag string) Checker {
+	mu.RLock()
+	defer mu.RUnlock()
+	return checkers[tag]
+}
+
+// CheckQuestion 检查题干与选项中的代码块，返回发现的问题。
+// 未标注语言的代码块按题目语言检查，标注为其他语言(如 text、json)的代码块只在有对应检查器时检查
+func CheckQuestion(language, title string, options []string) []string {
+	var problems []string
+	problems = append(problems, checkContent(language, "题干", title)...)
+	for i, content := range options {
+		problems = append(problems, checkContent(language, fmt.Sprintf("选项%c", 'A'+i), content)...)
+	}
+	return problems
+}
+
+func checkContent(language, place, content string) []string {
+	var problems []string
+	for _, block := range markdown.CodeBlocks(content) {
+		tag := markdown.LanguageTag(language)
+		if block.Language != "" {
+			tag = markdown.LanguageTag(block.Language)
+		}
+		checker := lookup(tag)
+		if checker == nil {
+			continue
+		}
+		for _, p := range checker.Check(block.Code) {
+			if p.Line > 0 {
+				problems = append(problems, fmt.Sprintf("%s代码第%d行: %s", place, p.Line, p.Message))
+			} else {
+				problems = append(problems, fmt.Sprintf("%s代码: %s", place, p.Message))
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,249 @@
+package codecheck
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxGoProblems 单个代码块最多报告的类型错误数量
+const maxGoProblems = 5
+
+// stdPackages 代码片段中常直接使用而省略 import 的标准库包
+var stdPackages = map[string]string{
+	"bufio":    "bufio",
+	"bytes":    "bytes",
+	"context":  "context",
+	"errors":   "errors",
+	"fmt":      "fmt",
+	"io":       "io",
+	"json":     "encoding/json",
+	"math":     "math",
+	"os":       "os",
+	"rand":     "math/rand",
+	"reflect":  "reflect",
+	"regexp":   "regexp",
+	"runtime":  "runtime",
+	"sort":     "sort",
+	"strconv":  "strconv",
+	"strings":  "strings",
+	"sync":     "sync",
+	"atomic":   "sync/atomic",
+	"time":     "time",
+	"unicode":  "unicode",
+	"utf8":     "unicode/utf8",
+	"slices":   "slices",
+	"maps":     "maps",
+	"http":     "net/http",
+	"filepath": "path/filepath",
+}
+
+// topLevelDecl 以声明开头的行，说明片段包含函数、类型等顶层声明而不只是语句
+var topLevelDecl = regexp.MustCompile(`(?m)^(func|type|import|var|const)\b`)
+
+// GoChecker 使用 go/parser 做语法检查、go/types 做类型检查。
+// 不含 package 子句的片段会被补全: 包含顶层声明时补上 package main，只有语句时再放入 main 函数中，
+// 片段中直接使用而未导入的常用标准库包会自动导入。片段中未使用的变量与导入不视为错误
+type GoChecker struct {
+	mu       sync.Mutex
+	importer types.Importer
+}
+
+// goSource 补全后的源码，header 为补在片段前的行数，lines 为片段本身的行数
+type goSource struct {
+	src      string
+	header   int
+	lines    int
+	fragment bool
+}
+
+func (g *GoChecker) Check(code string) []Problem {
+	if strings.TrimSpace(code) == "" {
+		return nil
+	}
+	fset := token.NewFileSet()
+	source, file, err := parseGo(fset, code, "")
+	if err != nil {
+		return syntaxProblems(err, source)
+	}
+	// 补上片段中省略的导入后重新解析，补在 package 子句同一行，不影响行号
+	if source.fragment {
+		if imports := missingImports(file); imports != "" {
+			if source, file, err = parseGo(fset, code, imports); err != nil {
+				return syntaxProblems(err, source)
+			}
+		}
+	}
+	return g.typeCheck(fset, file, source)
+}
+
+// CompleteGo 将代码片段补全为可以编译的完整文件(补上 package 子句、main 函数与省略的导入)，
+// 已是完整文件时原样返回，存在语法错误时返回第一个错误
+func CompleteGo(code string) (string, error) {
+	fset := token.NewFileSet()
+	source, file, err := parseGo(fset, code, "")
+	if err == nil && source.fragment {
+		if imports := missingImports(file); imports != "" {
+			source, _, err = parseGo(fset, code, imports)
+		}
+	}
+	if err != nil {
+		problem := syntaxProblems(err, source)[0]
+		if problem.Line > 0 {
+			return "", fmt.Errorf("第%d行%s", problem.Line, problem.Message)
+		}
+		return "", errors.New(problem.Message)
+	}
+	return source.src, nil
+}
+
+// parseGo 按完整文件、带顶层声明的片段、语句片段的顺序尝试解析
+func parseGo(fset *token.FileSet, code, imports string) (goSource, *ast.File, error) {
+	lines := strings.Count(code, "\n") + 1
+	var candidates []goSource
+	if hasPackageClause(code) {
+		candidates = append(candidates, goSource{src: code, lines: lines})
+	} else {
+		header := "package main; " + imports + "\n"
+		fileForm := goSource{src: header + code, header: 1, lines: lines, fragment: true}
+		stmtForm := goSource{src: header + "func main() {\n" + code + "\n}\n", header: 2, lines: lines, fragment: true}
+		if topLevelDecl.MatchString(code) {
+			candidates = append(candidates, fileForm, stmtForm)
+		} else {
+			candidates = append(candidates, stmtForm, fileForm)
+		}
+	}
+	var firstErr error
+	for _, candidate := range candidates {
+		file, err := parser.ParseFile(fset, "snippet.go", candidate.src, parser.AllErrors)
+		if err == nil {
+			return candidate, file, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return candidates[0], nil, firstErr
+}
+
+// hasPackageClause 跳过开头的空行与注释后是否以 package 子句开始
+func hasPackageClause(code string) bool {
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	s.Init(fset.AddFile("", -1, len(code)), []byte(code), nil, 0)
+	_, tok, _ := s.Scan()
+	return tok == token.PACKAGE
+}
+
+// missingImports 片段中以 pkg.Name 形式使用、未声明也未导入的常用标准库包
+func missingImports(file *ast.File) string {
+	declared := make(map[string]bool)
+	for _, spec := range file.Imports {
+		path := strings.Trim(spec.Path.Value, `"`)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		declared[name] = true
+	}
+	unresolved := make(map[string]bool, len(file.Unresolved))
+	for _, ident := range file.Unresolved {
+		unresolved[ident.Name] = true
+	}
+	needed := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok && unresolved[ident.Name] && !declared[ident.Name] {
+			if _, ok := stdPackages[ident.Name]; ok {
+				needed[ident.Name] = true
+			}
+		}
+		return true
+	})
+	if len(needed) == 0 {
+		return ""
+	}
+	paths := make([]string, 0, len(needed))
+	for name := range needed {
+		paths = append(paths, fmt.Sprintf("%q", stdPackages[name]))
+	}
+	sort.Strings(paths)
+	return "import (" + strings.Join(paths, "; ") + ");"
+}
+
+func (g *GoChecker) typeCheck(fset *token.FileSet, file *ast.File, source goSource) []Problem {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.importer == nil {
+		// 从源码导入标准库，不依赖预编译的包文件
+		g.importer = importer.ForCompiler(token.NewFileSet(), "source", nil)
+	}
+	var importFailed bool
+	var typeErrors []types.Error
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			pkg, err := g.importer.Import(path)
+			if err != nil {
+				importFailed = true
+			}
+			return pkg, err
+		}),
+		Error: func(err error) {
+			var typeErr types.Error
+			if errors.As(err, &typeErr) {
+				typeErrors = append(typeErrors, typeErr)
+			}
+		},
+	}
+	_, _ = conf.Check("main", fset, []*ast.File{file}, nil)
+	// 无法导入依赖(如非标准库的包或运行环境缺少标准库源码)时类型信息不完整，只做语法检查
+	if importFailed {
+		return nil
+	}
+	var problems []Problem
+	for _, typeErr := range typeErrors {
+		if source.fragment && typeErr.Soft {
+			continue
+		}
+		problems = append(problems, Problem{Line: source.codeLine(fset.Position(typeErr.Pos).Line), Message: "类型错误: " + typeErr.Msg})
+		if len(problems) == maxGoProblems {
+			break
+		}
+	}
+	return problems
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+// syntaxProblems 只报告第一个语法错误，之后的错误通常是由它引起的
+func syntaxProblems(err error, source goSource) []Problem {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return []Problem{{Message: err.Error()}}
+	}
+	return []Problem{{Line: source.codeLine(list[0].Pos.Line), Message: "语法错误: " + list[0].Msg}}
+}
+
+// codeLine 换算为片段中的行号，落在补全部分的错误(如片段末尾缺少右括号)返回 0
+func (s goSource) codeLine(line int) int {
+	if line <= s.header || line > s.header+s.lines {
+		return 0
+	}
+	return line - s.header
+}
@@ -0,0 +1,357 @@
+package coderun
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxStdout 保留的标准输出长度，超出部分丢弃
+	maxStdout = 64 << 10
+	// maxStderr 保留的标准错误与编译错误长度
+	maxStderr = 8 << 10
+)
+
+// ErrBuildTimeout 编译超时。编译耗时取决于机器负载与缓存状态，不作为代码本身的结果，以免被缓存
+var ErrBuildTimeout = errors.New("编译超时")
+
+// Result 代码的编译与运行结果，编译失败时只有 CompileError
+type Result struct {
+	CompileError string
+	Stdout       string
+	Stderr       string
+	ExitCode     int
+	TimedOut     bool
+}
+
+// GoRunner 使用本机 Go 工具链在沙箱子进程中编译并运行完整的 Go 源文件:
+// 子进程处于新的用户、挂载、网络与进程命名空间中，根目录是只包含只读 GOROOT、
+// 本次运行的临时目录与编译缓存的 tmpfs，无法访问网络与宿主机的其他文件；
+// 运行时限制内存并在超时后结束整个进程组
+type GoRunner struct {
+	GoBinary     string
+	CacheDir     string // 共享编译缓存目录，只由预热编译写入，每次运行在其上使用独立的可写层
+	BuildTimeout time.Duration
+	RunTimeout   time.Duration
+	MemoryMB     int
+}
+
+const (
+	// sandboxWorkDir 沙箱中本次运行的工作目录，sandboxCacheDir 为沙箱中的 GOCACHE
+	sandboxWorkDir  = "/work"
+	sandboxCacheDir = "/gocache"
+	// maxFileSizeMB 运行程序时单个文件的大小上限
+	maxFileSizeMB = 16
+	// warmTimeout 预热共享编译缓存的超时时间
+	warmTimeout = 5 * time.Minute
+)
+
+// 编译缓存的挂载方式
+const (
+	cacheNone    = ""
+	cacheShared  = "shared"  // 直接读写共享缓存，只用于预热
+	cacheOverlay = "overlay" // 共享缓存只读，写入本次运行的独立目录
+)
+
+// sandboxSpec 传给沙箱初始化进程的配置，路径均为宿主机路径
+type sandboxSpec struct {
+	Root       string   // 沙箱根目录的挂载点
+	WorkDir    string   // 挂载到 sandboxWorkDir
+	GoRoot     string   // 只读挂载到相同路径
+	CacheMode  string   // 编译缓存的挂载方式
+	CacheDir   string   // 共享编译缓存
+	CacheUpper string   // 本次运行的缓存写入目录
+	MemoryMB   int      // 数据段上限，0 表示不限制
+	FileSizeMB int      // 单个文件大小上限，0 表示不限制
+	Args       []string // 在沙箱中执行的程序
+	Env        []string
+}
+
+// warmSource 预热共享编译缓存时编译的程序，覆盖题目代码中常用的标准库
+const warmSource = `package main
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	_ = bufio.NewReader
+	_ = bytes.NewBuffer
+	_ = heap.Init
+	_ = list.New
+	_ = context.Background
+	_ = json.Marshal
+	_ = errors.New
+	_ = io.EOF
+	_ = maps.Keys[map[int]int]
+	_ = math.Sqrt
+	_ = os.Exit
+	_ = reflect.TypeOf
+	_ = slices.Sort[[]int]
+	_ = sort.Ints
+	_ = strconv.Itoa
+	_ = strings.Split
+	_ sync.Mutex
+	_ atomic.Int64
+	_ = time.Now
+	_ = unicode.IsLetter
+	_ = utf8.RuneLen
+)
+
+func main() { fmt.Println() }
+`
+
+var (
+	goRoots   sync.Map // GoBinary -> GOROOT
+	warmMu    sync.Mutex
+	warmCache = make(map[string]bool) // 已预热的共享缓存目录
+)
+
+// Run 编译并运行源文件。编译失败、运行出错与运行超时记录在结果中，
+// 编译超时返回 ErrBuildTimeout，工具链或沙箱不可用等无法得出结果的情况返回其他 error
+func (r *GoRunner) Run(ctx context.Context, source string) (*Result, error) {
+	if !sandboxSupported {
+		return nil, errors.New("当前系统不支持沙箱运行代码")
+	}
+	goRoot, err := r.goRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cacheDir, err := filepath.Abs(r.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return nil, err
+	}
+	r.warm(goRoot, cacheDir)
+
+	dir, err := os.MkdirTemp("", "aiquiz-run-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	work := filepath.Join(dir, "work")
+	for _, d := range []string{work, filepath.Join(dir, "root"), filepath.Join(dir, "cache")} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	if err := prepareWorkDir(work, source); err != nil {
+		return nil, err
+	}
+	spec := func(mode string, args ...string) *sandboxSpec {
+		return &sandboxSpec{
+			Root:       filepath.Join(dir, "root"),
+			WorkDir:    work,
+			GoRoot:     goRoot,
+			CacheMode:  mode,
+			CacheDir:   cacheDir,
+			CacheUpper: filepath.Join(dir, "cache"),
+			Args:       args,
+		}
+	}
+
+	build := spec(cacheOverlay, filepath.Join(goRoot, "bin", "go"), "build", "-o", "prog", "main.go")
+	build.Env = buildEnv(goRoot)
+	var buildOutput limitedBuffer
+	timedOut, err := r.run(ctx, r.BuildTimeout, build, &buildOutput, &buildOutput)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if timedOut {
+		return nil, ErrBuildTimeout
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("无法调用 Go 工具链: %w", err)
+		}
+		return &Result{CompileError: compileError(buildOutput.String(), sandboxWorkDir)}, nil
+	}
+
+	run := spec(cacheNone, sandboxWorkDir+"/prog")
+	run.Env = []string{"HOME=" + sandboxWorkDir, "TMPDIR=/tmp"}
+	run.MemoryMB, run.FileSizeMB = r.MemoryMB, maxFileSizeMB
+	var stdout, stderr limitedBuffer
+	stdout.limit, stderr.limit = maxStdout, maxStderr
+	timedOut, err = r.run(ctx, r.RunTimeout, run, &stdout, &stderr)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	result := &Result{
+		Stdout:   stdout.String(),
+		Stderr:   strings.ReplaceAll(stderr.String(), sandboxWorkDir+"/", ""),
+		TimedOut: timedOut,
+	}
+	if err != nil && !result.TimedOut {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("运行程序失败: %w", err)
+		}
+		result.ExitCode = exitErr.ExitCode()
+	}
+	return result, nil
+}
+
+// buildEnv 沙箱中调用 Go 工具链的环境变量，不读取宿主机的 go env 配置
+func buildEnv(goRoot string) []string {
+	return []string{
+		"PATH=" + filepath.Join(goRoot, "bin"),
+		"GOROOT=" + goRoot,
+		"HOME=" + sandboxWorkDir,
+		"TMPDIR=/tmp",
+		"GOCACHE=" + sandboxCacheDir,
+		"GOPATH=" + sandboxWorkDir + "/gopath",
+		"GOENV=off",
+		"GOPROXY=off",
+		"GOTOOLCHAIN=local",
+		"GO111MODULE=off",
+		"CGO_ENABLED=0",
+	}
+}
+
+// prepareWorkDir 写入源文件，并关闭工作目录(沙箱中的 HOME)下 Go 工具链的遥测，
+// 沙箱中没有 /proc，遥测进程无法启动并会在编译输出中报错
+func prepareWorkDir(work, source string) error {
+	telemetry := filepath.Join(work, ".config", "go", "telemetry")
+	if err := os.MkdirAll(telemetry, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(telemetry, "mode"), []byte("off"), 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(work, "main.go"), []byte(source), 0o644)
+}
+
+// goRoot 查询并缓存 Go 工具链的 GOROOT，沙箱中只挂载该目录
+func (r *GoRunner) goRoot(ctx context.Context) (string, error) {
+	if goRoot, ok := goRoots.Load(r.GoBinary); ok {
+		return goRoot.(string), nil
+	}
+	cmd := exec.CommandContext(ctx, r.GoBinary, "env", "GOROOT")
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "HOME=" + os.TempDir(), "GOTOOLCHAIN=local"}
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("无法调用 Go 工具链: %w", err)
+	}
+	goRoot, err := filepath.EvalSymlinks(strings.TrimSpace(string(output)))
+	if err != nil || !filepath.IsAbs(goRoot) {
+		return "", fmt.Errorf("无法确定 GOROOT: %q", output)
+	}
+	goRoots.Store(r.GoBinary, goRoot)
+	return goRoot, nil
+}
+
+// warm 用固定的程序编译一次常用标准库，写入共享编译缓存。用户代码的编译只能读取共享缓存，
+// 因此不会相互影响，预热后编译也不必每次从头编译标准库。预热失败不影响运行，下次再试
+func (r *GoRunner) warm(goRoot, cacheDir string) {
+	warmMu.Lock()
+	defer warmMu.Unlock()
+	if warmCache[cacheDir] {
+		return
+	}
+	dir, err := os.MkdirTemp("", "aiquiz-warm-*")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+	work := filepath.Join(dir, "work")
+	for _, d := range []string{work, filepath.Join(dir, "root")} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return
+		}
+	}
+	if err := prepareWorkDir(work, warmSource); err != nil {
+		return
+	}
+	spec := &sandboxSpec{
+		Root:      filepath.Join(dir, "root"),
+		WorkDir:   work,
+		GoRoot:    goRoot,
+		CacheMode: cacheShared,
+		CacheDir:  cacheDir,
+		Args:      []string{filepath.Join(goRoot, "bin", "go"), "build", "-o", "prog", "main.go"},
+		Env:       buildEnv(goRoot),
+	}
+	var output limitedBuffer
+	if _, err := r.run(context.Background(), warmTimeout, spec, &output, &output); err == nil {
+		warmCache[cacheDir] = true
+	}
+}
+
+// run 在沙箱中执行程序，返回是否超时
+func (r *GoRunner) run(ctx context.Context, timeout time.Duration, spec *sandboxSpec, stdout, stderr io.Writer) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := runSandbox(ctx, spec, stdout, stderr)
+	return errors.Is(ctx.Err(), context.DeadlineExceeded), err
+}
+
+// compileError 去掉 go build 输出中的包名行与工作目录路径
+func compileError(output, dir string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			continue
+		}
+		lines = append(lines, strings.TrimPrefix(strings.ReplaceAll(line, dir+"/", ""), "./"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// limitedBuffer 只保留前 limit 个字节的输出，limit 为 0 时使用 maxStderr
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	limit := b.limit
+	if limit == 0 {
+		limit = maxStderr
+	}
+	if remain := limit - b.buf.Len(); remain > 0 {
+		if len(p) > remain {
+			b.buf.Write(p[:remain])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	// 丢弃超出的部分但不报错，避免子进程因写入失败而提前退出
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,281 @@
+//go:build linux
+
+package coderun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const sandboxSupported = true
+
+const (
+	// sandboxInitArg 重新执行当前程序进入沙箱时使用的 argv[0]
+	sandboxInitArg = "aiquiz-coderun-sandbox"
+	// sandboxRootSize 沙箱根目录(tmpfs)的大小上限，/tmp 位于其中
+	sandboxRootSize = "64m"
+	// 沙箱进程继承的文件描述符: 程序的标准输出、标准错误与初始化状态(执行程序后自动关闭)
+	sandboxStdoutFd = 3
+	sandboxStderrFd = 4
+	sandboxStatusFd = 5
+
+	prSetNoNewPrivs = 38
+	capVersion3     = 0x20080522
+	capLastCap      = 63
+)
+
+// init 以 sandboxInitArg 启动时作为沙箱的初始化进程运行: 完成挂载与降权后执行目标程序，不会返回。
+// 在此之前其他包的 init 可能已经向标准输出写入内容，因此程序的输出通过额外的文件描述符传入
+func init() {
+	if len(os.Args) < 2 || os.Args[0] != sandboxInitArg {
+		return
+	}
+	// 降权与 exec 必须在同一个线程中完成
+	runtime.LockOSThread()
+	err := sandboxInit(os.Args[1])
+	status := os.NewFile(sandboxStatusFd, "status")
+	_, _ = fmt.Fprint(status, err)
+	os.Exit(1)
+}
+
+// runSandbox 在新的用户、挂载、网络、进程等命名空间中执行 spec 描述的程序，直到程序结束。
+// 程序以非零状态退出时返回 *exec.ExitError，沙箱无法建立时返回其他错误
+func runSandbox(ctx context.Context, spec *sandboxSpec, stdout, stderr io.Writer) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	var readers, writers []*os.File
+	defer func() {
+		for _, f := range append(readers, writers...) {
+			f.Close()
+		}
+	}()
+	for i := 0; i < 3; i++ {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return err
+		}
+		readers, writers = append(readers, r), append(writers, w)
+	}
+
+	cmd := exec.CommandContext(ctx, "/proc/self/exe")
+	cmd.Args = []string{sandboxInitArg, string(data)}
+	cmd.Env = []string{}
+	cmd.Dir = "/"
+	cmd.ExtraFiles = writers
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET |
+			syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+		Setpgid:     true,
+		Pdeathsig:   syscall.SIGKILL,
+	}
+	cmd.Cancel = func() error { return killGroup(cmd) }
+	cmd.WaitDelay = time.Second
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("无法启动沙箱: %w", err)
+	}
+	for _, w := range writers {
+		w.Close()
+	}
+	writers = nil
+
+	var wg sync.WaitGroup
+	var status []byte
+	wg.Add(3)
+	go func() { defer wg.Done(); _, _ = io.Copy(stdout, readers[0]) }()
+	go func() { defer wg.Done(); _, _ = io.Copy(stderr, readers[1]) }()
+	go func() { defer wg.Done(); status, _ = io.ReadAll(readers[2]) }()
+	err = cmd.Wait()
+	wg.Wait()
+	if len(status) > 0 {
+		return fmt.Errorf("沙箱初始化失败: %s", status)
+	}
+	return err
+}
+
+func killGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// sandboxInit 在沙箱的初始化进程中执行: 以 tmpfs 为新的根目录，只挂载只读的 GOROOT、
+// 本次运行的工作目录与编译缓存，切换根目录并卸载宿主机的文件系统，降权后执行目标程序
+func sandboxInit(data string) error {
+	var spec sandboxSpec
+	if err := json.Unmarshal([]byte(data), &spec); err != nil {
+		return fmt.Errorf("解析沙箱配置失败: %v", err)
+	}
+	if len(spec.Args) == 0 {
+		return fmt.Errorf("没有要执行的程序")
+	}
+	// 挂载只在沙箱内可见
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("设置挂载传播失败: %v", err)
+	}
+	root := spec.Root
+	if err := syscall.Mount("tmpfs", root, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "size="+sandboxRootSize+",mode=0755"); err != nil {
+		return fmt.Errorf("挂载根目录失败: %v", err)
+	}
+	for _, dir := range []string{sandboxWorkDir, sandboxCacheDir, "/dev", "/.oldroot", spec.GoRoot} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "tmp"), 0o1777); err != nil {
+		return err
+	}
+	if err := bindMount(spec.WorkDir, filepath.Join(root, sandboxWorkDir), false); err != nil {
+		return err
+	}
+	if err := bindMount(spec.GoRoot, filepath.Join(root, spec.GoRoot), true); err != nil {
+		return err
+	}
+	devNull := filepath.Join(root, "dev", "null")
+	if err := os.WriteFile(devNull, nil, 0o666); err != nil {
+		return err
+	}
+	if err := bindMount("/dev/null", devNull, false); err != nil {
+		return err
+	}
+	if err := mountCache(&spec, filepath.Join(root, sandboxCacheDir)); err != nil {
+		return err
+	}
+
+	// 切换根目录后卸载宿主机的文件系统
+	if err := syscall.Chdir(root); err != nil {
+		return err
+	}
+	if err := syscall.PivotRoot(".", ".oldroot"); err != nil {
+		return fmt.Errorf("切换根目录失败: %v", err)
+	}
+	if err := syscall.Chdir("/"); err != nil {
+		return err
+	}
+	if err := syscall.Unmount("/.oldroot", syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("卸载宿主机文件系统失败: %v", err)
+	}
+	if err := os.Remove("/.oldroot"); err != nil {
+		return err
+	}
+	if err := syscall.Chdir(sandboxWorkDir); err != nil {
+		return err
+	}
+
+	if spec.MemoryMB > 0 {
+		// Go 运行时启动时会保留大量虚拟地址空间，限制虚拟内存会导致程序无法启动，因此只限制数据段
+		if err := setRlimit(syscall.RLIMIT_DATA, uint64(spec.MemoryMB)<<20); err != nil {
+			return err
+		}
+	}
+	if spec.FileSizeMB > 0 {
+		if err := setRlimit(syscall.RLIMIT_FSIZE, uint64(spec.FileSizeMB)<<20); err != nil {
+			return err
+		}
+	}
+	if err := dropPrivileges(); err != nil {
+		return err
+	}
+	// 换上程序的标准输出与标准错误，其余描述符在执行程序时关闭
+	if err := syscall.Dup3(sandboxStdoutFd, 1, 0); err != nil {
+		return err
+	}
+	if err := syscall.Dup3(sandboxStderrFd, 2, 0); err != nil {
+		return err
+	}
+	for _, fd := range []int{sandboxStdoutFd, sandboxStderrFd, sandboxStatusFd} {
+		syscall.CloseOnExec(fd)
+	}
+	err := syscall.Exec(spec.Args[0], spec.Args, spec.Env)
+	return fmt.Errorf("执行 %s 失败: %v", spec.Args[0], err)
+}
+
+// bindMount 将宿主机路径绑定挂载到沙箱中，readOnly 时重新挂载为只读(保留原挂载不可清除的标志)
+func bindMount(source, target string, readOnly bool) error {
+	if err := syscall.Mount(source, target, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("挂载 %s 失败: %v", source, err)
+	}
+	if !readOnly {
+		return nil
+	}
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(target, &st); err != nil {
+		return err
+	}
+	flags := uintptr(syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY)
+	for st_, ms := range map[int64]uintptr{
+		0x2:    syscall.MS_NOSUID,
+		0x4:    syscall.MS_NODEV,
+		0x8:    syscall.MS_NOEXEC,
+		0x400:  syscall.MS_NOATIME,
+		0x800:  syscall.MS_NODIRATIME,
+		0x1000: syscall.MS_RELATIME,
+	} {
+		if st.Flags&st_ != 0 {
+			flags |= ms
+		}
+	}
+	if err := syscall.Mount("", target, "", flags, ""); err != nil {
+		return fmt.Errorf("重新挂载 %s 为只读失败: %v", source, err)
+	}
+	return nil
+}
+
+// mountCache 挂载编译缓存: 预热时直接读写共享缓存；编译用户代码时以共享缓存为只读的下层、
+// 本次运行的目录为可写的上层，写入不会影响共享缓存。不支持 overlay 时使用空的独立缓存
+func mountCache(spec *sandboxSpec, target string) error {
+	switch spec.CacheMode {
+	case cacheShared:
+		return bindMount(spec.CacheDir, target, false)
+	case cacheOverlay:
+		upper, work := filepath.Join(spec.CacheUpper, "upper"), filepath.Join(spec.CacheUpper, "work")
+		for _, dir := range []string{upper, work} {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+		}
+		options := fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", spec.CacheDir, upper, work)
+		if err := syscall.Mount("overlay", target, "overlay", 0, options); err == nil {
+			return nil
+		}
+		return bindMount(upper, target, false)
+	}
+	return nil
+}
+
+func setRlimit(resource int, limit uint64) error {
+	return syscall.Setrlimit(resource, &syscall.Rlimit{Cur: limit, Max: limit})
+}
+
+// dropPrivileges 清空当前线程的全部能力(含边界集，执行程序后也无法重新获得)并禁止提升权限，
+// 沙箱中的程序因此无法重新挂载或卸载文件系统
+func dropPrivileges() error {
+	for c := 0; c <= capLastCap; c++ {
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, syscall.PR_CAPBSET_DROP, uintptr(c), 0); errno != 0 && errno != syscall.EINVAL {
+			return fmt.Errorf("清除能力边界集失败: %v", errno)
+		}
+	}
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0); errno != 0 {
+		return fmt.Errorf("设置 no_new_privs 失败: %v", errno)
+	}
+	header := struct {
+		version uint32
+		pid     int32
+	}{version: capVersion3}
+	var data [2]struct{ effective, permitted, inheritable uint32 }
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_CAPSET, uintptr(unsafe.Pointer(&header)), uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return fmt.Errorf("清除能力失败: %v", errno)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package coderun
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// 其他系统无法隔离文件系统与网络，不运行代码
+const sandboxSupported = false
+
+func runSandbox(ctx context.Context, spec *sandboxSpec, stdout, stderr io.Writer) error {
+	return errors.New("当前系统不支持沙箱运行代码")
+}
@@ -0,0 +1,31 @@
+package enums
+
+// BulkOperation 题目批量操作类型
+type BulkOperation string
+
+const (
+	BulkDelete           BulkOperation = "delete"             // 删除(移入回收站)
+	BulkSetLanguage      BulkOperation = "set_language"       // 修改编程语言
+	BulkAddTags          BulkOperation = "add_tags"           // 添加标签(关键词同步更新)
+	BulkRemoveTags       BulkOperation = "remove_tags"        // 移除标签(关键词同步更新)
+	BulkSetDifficulty    BulkOperation = "set_difficulty"     // 修改难度
+	BulkSetVisibility    BulkOperation = "set_visibility"     // 修改可见范围
+	BulkMoveToCollection BulkOperation = "move_to_collection" // 加入集合(可同时移出原集合)
+)
+
+// SupportedBulkOperations 所有支持的批量操作
+var SupportedBulkOperations = map[BulkOperation]struct{}{
+	BulkDelete:           {},
+	BulkSetLanguage:      {},
+	BulkAddTags:          {},
+	BulkRemoveTags:       {},
+	BulkSetDifficulty:    {},
+	BulkSetVisibility:    {},
+	BulkMoveToCollection: {},
+}
+
+// IsSupportedBulkOperation 检查批量操作是否支持
+func IsSupportedBulkOperation(op BulkOperation) bool {
+	_, exists := SupportedBulkOperations[op]
+	return exists
+}
@@ -0,0 +1,23 @@
+package enums
+
+// Difficulty 题目难度
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyMedium Difficulty = "medium"
+	DifficultyHard   Difficulty = "hard"
+)
+
+// SupportedDifficulties 所有支持的难度
+var SupportedDifficulties = map[Difficulty]struct{}{
+	DifficultyEasy:   {},
+	DifficultyMedium: {},
+	DifficultyHard:   {},
+}
+
+// IsSupportedDifficulty 检查难度是否支持
+func IsSupportedDifficulty(d Difficulty) bool {
+	_, exists := SupportedDifficulties[d]
+	return exists
+}
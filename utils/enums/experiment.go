@@ -0,0 +1,19 @@
+package enums
+
+// ExperimentVariant 实验分组
+type ExperimentVariant string
+
+const (
+	VariantControl   ExperimentVariant = "control"   // 对照组：默认提示词与请求的模型
+	VariantTreatment ExperimentVariant = "treatment" // 实验组：实验配置的提示词或模型
+)
+
+// GenerationOutcome 生成题目的最终去向
+type GenerationOutcome string
+
+const (
+	OutcomePending   GenerationOutcome = "pending"   // 尚未确认
+	OutcomeUnedited  GenerationOutcome = "unedited"  // 原样确认入库
+	OutcomeEdited    GenerationOutcome = "edited"    // 修改后确认入库
+	OutcomeDiscarded GenerationOutcome = "discarded" // 超时未确认，视为丢弃
+)
@@ -0,0 +1,40 @@
+package enums
+
+// ReportCategory 题目错误报告的类别
+type ReportCategory string
+
+const (
+	ReportWrongAnswer  ReportCategory = "wrong_answer"  // 答案错误
+	ReportAmbiguous    ReportCategory = "ambiguous"     // 题意不清
+	ReportOutdated     ReportCategory = "outdated"      // 内容过时
+	ReportTypo         ReportCategory = "typo"          // 错别字
+	ReportCompileError ReportCategory = "compile_error" // 代码无法编译(题目入库时由代码检查自动报告)
+)
+
+// SupportedReportCategories 所有支持的错误报告类别
+var SupportedReportCategories = map[ReportCategory]struct{}{
+	ReportWrongAnswer:  {},
+	ReportAmbiguous:    {},
+	ReportOutdated:     {},
+	ReportTypo:         {},
+	ReportCompileError: {},
+}
+
+// IsSupportedReportCategory 检查错误报告类别是否支持
+func IsSupportedReportCategory(category ReportCategory) bool {
+	_, exists := SupportedReportCategories[category]
+	return exists
+}
+
+// FeedbackStatus 评论串与错误报告的处理状态
+type FeedbackStatus string
+
+const (
+	FeedbackOpen     FeedbackStatus = "open"     // 未解决
+	FeedbackResolved FeedbackStatus = "resolved" // 已解决
+)
+
+// IsSupportedFeedbackStatus 检查处理状态是否支持
+func IsSupportedFeedbackStatus(status FeedbackStatus) bool {
+	return status == FeedbackOpen || status == FeedbackResolved
+}
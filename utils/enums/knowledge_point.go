@@ -0,0 +1,9 @@
+package enums
+
+// KnowledgeSource 题目与知识点关联的来源
+type KnowledgeSource string
+
+const (
+	KnowledgeSourceManual KnowledgeSource = "manual" // 教师手动关联
+	KnowledgeSourceAI     KnowledgeSource = "ai"     // 生成题目时由模型自动分类
+)
@@ -0,0 +1,35 @@
+package enums
+
+// ReviewStatus 题目审核状态
+type ReviewStatus string
+
+const (
+	ReviewDraft    ReviewStatus = "draft"     // 草稿，入库及修改内容后的状态
+	ReviewInReview ReviewStatus = "in_review" // 已提交，等待审核
+	ReviewApproved ReviewStatus = "approved"  // 审核通过
+	ReviewRejected ReviewStatus = "rejected"  // 审核驳回
+)
+
+// SupportedReviewStatuses 所有支持的审核状态
+var SupportedReviewStatuses = map[ReviewStatus]struct{}{
+	ReviewDraft:    {},
+	ReviewInReview: {},
+	ReviewApproved: {},
+	ReviewRejected: {},
+}
+
+// IsSupportedReviewStatus 检查审核状态是否支持
+func IsSupportedReviewStatus(s ReviewStatus) bool {
+	_, exists := SupportedReviewStatuses[s]
+	return exists
+}
+
+// ReviewAction 审核记录中的操作
+type ReviewAction string
+
+const (
+	ReviewSubmit  ReviewAction = "submit"  // 提交审核
+	ReviewApprove ReviewAction = "approve" // 审核通过
+	ReviewReject  ReviewAction = "reject"  // 审核驳回
+	ReviewReset   ReviewAction = "reset"   // 修改内容后退回草稿
+)
@@ -0,0 +1,10 @@
+package enums
+
+// RevisionAction 产生题目版本的操作
+type RevisionAction string
+
+const (
+	RevisionCreate  RevisionAction = "create"  // 题目入库
+	RevisionUpdate  RevisionAction = "update"  // 修改题目
+	RevisionRestore RevisionAction = "restore" // 恢复到历史版本
+)
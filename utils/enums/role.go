@@ -0,0 +1,23 @@
+package enums
+
+// Role 用户角色
+type Role string
+
+const (
+	RoleUser     Role = "user"     // 普通用户(教师)
+	RoleReviewer Role = "reviewer" // 审核员，可审核其他用户的题目
+	RoleAdmin    Role = "admin"    // 管理员
+)
+
+// SupportedRoles 所有支持的角色
+var SupportedRoles = map[Role]struct{}{
+	RoleUser:     {},
+	RoleReviewer: {},
+	RoleAdmin:    {},
+}
+
+// IsSupportedRole 检查角色是否支持
+func IsSupportedRole(r Role) bool {
+	_, exists := SupportedRoles[r]
+	return exists
+}
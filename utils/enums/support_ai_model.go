@@ -20,3 +20,9 @@ func IsSupportedAiModel(model AiModel) bool {
 	_, exists := SupportedAiModels[model]
 	return exists
 }
+
+// 非AI生成的题目来源，记录在题目的 ai_model 字段中，不能用于生成题目
+const (
+	AiModelManual AiModel = "manual" // 手动录入
+	AiModelImport AiModel = "import" // 从文件导入
+)
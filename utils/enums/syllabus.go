@@ -0,0 +1,11 @@
+package enums
+
+// CoverageStatus 试卷中大纲主题的覆盖状态
+type CoverageStatus string
+
+const (
+	CoverageMissing CoverageStatus = "missing" // 没有属于该主题的题目
+	CoverageUnder   CoverageStatus = "under"   // 分值低于目标
+	CoverageOK      CoverageStatus = "ok"      // 分值在目标允许的偏差范围内
+	CoverageOver    CoverageStatus = "over"    // 分值高于目标
+)
@@ -0,0 +1,26 @@
+package enums
+
+// Visibility 题目可见范围
+type Visibility string
+
+const (
+	VisibilityPrivate      Visibility = "private"      // 仅创建者可见
+	VisibilityOrganization Visibility = "organization" // 本系统的全部登录用户可见
+	VisibilityPublic       Visibility = "public"       // 所有人可见(无需登录)
+)
+
+// SupportedVisibilities 所有支持的可见范围
+var SupportedVisibilities = map[Visibility]struct{}{
+	VisibilityPrivate:      {},
+	VisibilityOrganization: {},
+	VisibilityPublic:       {},
+}
+
+// IsSupportedVisibility 检查可见范围是否支持
+func IsSupportedVisibility(v Visibility) bool {
+	_, exists := SupportedVisibilities[v]
+	return exists
+}
+
+// SharedVisibilities 共享题库中可见的范围
+var SharedVisibilities = []string{string(VisibilityOrganization), string(VisibilityPublic)}
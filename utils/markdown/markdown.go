@@ -0,0 +1,328 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// 题目内容(题干、选项、解析)使用的 Markdown 子集:
+//   - 段落(空行分隔)，段落内的换行保留为换行
+//   - **加粗**、*斜体*、`行内代码`，反斜杠转义标点
+//   - 无序列表(- / * / + 开头)与有序列表(1. 或 1) 开头)，不支持嵌套
+//   - 围栏代码块，以 ``` 开始与结束，开始行可标注语言，如 ```go
+//
+// 标题、链接、图片、表格与 HTML 标签均不支持，按普通文本原样显示(HTML 会被转义)。
+
+// langPattern 代码块语言标记允许的字符
+var langPattern = regexp.MustCompile(`^[A-Za-z0-9_+#.-]{1,20}$`)
+
+var (
+	unorderedItem = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	orderedItem   = regexp.MustCompile(`^\s{0,3}\d{1,9}[.)]\s+(.*)$`)
+	strongPattern = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	emPattern     = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+)
+
+// languageTags 常见编程语言名称对应的代码块语言标记，其余语言使用小写名称
+var languageTags = map[string]string{
+	"c++":         "cpp",
+	"c#":          "csharp",
+	"objective-c": "objectivec",
+}
+
+// LanguageTag 返回编程语言对应的代码块语言标记，如 Go -> go、C++ -> cpp
+func LanguageTag(language string) string {
+	name := strings.ToLower(strings.TrimSpace(language))
+	if tag, ok := languageTags[name]; ok {
+		return tag
+	}
+	return strings.ReplaceAll(name, " ", "")
+}
+
+// Sanitize 规范化内容: 统一换行符，去掉控制字符、双向文本控制字符与行尾空白，去掉首尾空行
+func Sanitize(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	src = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case unicode.IsControl(r):
+			return -1
+		// 双向文本控制字符会让代码的显示顺序与实际顺序不一致
+		case r >= '\u202a' && r <= '\u202e', r >= '\u2066' && r <= '\u2069', r == '\u200e', r == '\u200f':
+			return -1
+		}
+		return r
+	}, src)
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// Validate 校验内容是否符合支持的 Markdown 子集: 代码块必须闭合，语言标记只能包含字母、数字与 _+#.-
+func Validate(src string) error {
+	inFence := false
+	for i, line := range strings.Split(src, "\n") {
+		info, ok := fenceInfo(line)
+		if !ok {
+			continue
+		}
+		if inFence {
+			if info == "" {
+				inFence = false
+			}
+			continue
+		}
+		if lang := fenceLanguage(info); lang != "" && !langPattern.MatchString(lang) {
+			return fmt.Errorf("第%d行代码块的语言标记 %q 无效", i+1, lang)
+		}
+		inFence = true
+	}
+	if inFence {
+		return fmt.Errorf("代码块未闭合，请以单独一行的 ``` 结束")
+	}
+	return nil
+}
+
+// Clean 规范化并校验内容
+func Clean(src string) (string, error) {
+	src = Sanitize(src)
+	if err := Validate(src); err != nil {
+		return "", err
+	}
+	return src, nil
+}
+
+// CleanAll 依次规范化并校验多段内容，结果写回原处，遇到第一个错误即返回
+func CleanAll(fields ...*string) error {
+	for _, field := range fields {
+		cleaned, err := Clean(*field)
+		if err != nil {
+			return err
+		}
+		*field = cleaned
+	}
+	return nil
+}
+
+// CodeBlock 内容中的一个代码块，Line 为代码第一行在内容中的行号(从1开始)
+type CodeBlock struct {
+	Language string
+	Code     string
+	Line     int
+}
+
+// CodeBlocks 提取内容中的代码块，未闭合的代码块视为延续到末尾
+func CodeBlocks(src string) []CodeBlock {
+	var blocks []CodeBlock
+	lines := strings.Split(src, "\n")
+	for i := 0; i < len(lines); i++ {
+		info, ok := fenceInfo(lines[i])
+		if !ok {
+			continue
+		}
+		block := CodeBlock{Language: fenceLanguage(info), Line: i + 2}
+		var code []string
+		for i++; i < len(lines); i++ {
+			if closing, ok := fenceInfo(lines[i]); ok && closing == "" {
+				break
+			}
+			code = append(code, lines[i])
+		}
+		block.Code = strings.Join(code, "\n")
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// StripCodeBlocks 去掉内容中的代码块，只保留代码块之外的文本
+func StripCodeBlocks(src string) string {
+	var text []string
+	inFence := false
+	for _, line := range strings.Split(src, "\n") {
+		if info, ok := fenceInfo(line); ok && (!inFence || info == "") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			text = append(text, line)
+		}
+	}
+	return strings.Join(text, "\n")
+}
+
+// fenceInfo 判断是否为代码块的围栏行(至多缩进3个空格)，返回 ``` 之后的内容
+func fenceInfo(line string) (string, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || !strings.HasPrefix(trimmed, "```") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimLeft(trimmed, "`")), true
+}
+
+func fenceLanguage(info string) string {
+	if fields := strings.Fields(info); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// Render 将内容渲染为安全的 HTML 片段，所有文本均经过转义，只会输出
+// p、br、strong、em、code、pre、ul、ol、li 标签。未闭合的代码块视为延续到末尾
+func Render(src string) string {
+	lines := strings.Split(Sanitize(src), "\n")
+	var b strings.Builder
+	var paragraph []string
+	var listTag string
+	var items []string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>")
+			b.WriteString(renderLines(paragraph))
+			b.WriteString("</p>\n")
+			paragraph = nil
+		}
+	}
+	flushList := func() {
+		if listTag != "" {
+			b.WriteString("<" + listTag + ">\n")
+			for _, item := range items {
+				b.WriteString("<li>" + item + "</li>\n")
+			}
+			b.WriteString("</" + listTag + ">\n")
+			listTag, items = "", nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if info, ok := fenceInfo(line); ok {
+			flushParagraph()
+			flushList()
+			var code []string
+			for i++; i < len(lines); i++ {
+				if closing, ok := fenceInfo(lines[i]); ok && closing == "" {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			b.WriteString("<pre><code")
+			if lang := fenceLanguage(info); langPattern.MatchString(lang) {
+				b.WriteString(` class="language-` + html.EscapeString(strings.ToLower(lang)) + `"`)
+			}
+			b.WriteString(">")
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flushParagraph()
+			flushList()
+			continue
+		}
+		tag, content := "", ""
+		if m := unorderedItem.FindStringSubmatch(line); m != nil {
+			tag, content = "ul", m[1]
+		} else if m := orderedItem.FindStringSubmatch(line); m != nil {
+			tag, content = "ol", m[1]
+		}
+		switch {
+		case tag != "":
+			flushParagraph()
+			if tag != listTag {
+				flushList()
+				listTag = tag
+			}
+			items = append(items, renderInline(content))
+		case listTag != "":
+			// 列表项的续行
+			items[len(items)-1] += "<br>\n" + renderInline(strings.TrimSpace(line))
+		default:
+			paragraph = append(paragraph, strings.TrimSpace(line))
+		}
+	}
+	flushParagraph()
+	flushList()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func renderLines(lines []string) string {
+	rendered := make([]string, 0, len(lines))
+	for _, line := range lines {
+		rendered = append(rendered, renderInline(line))
+	}
+	return strings.Join(rendered, "<br>\n")
+}
+
+// renderInline 渲染行内格式: 先切分出行内代码，其余文本转义后再处理加粗与斜体
+func renderInline(s string) string {
+	var b strings.Builder
+	var text strings.Builder
+	flush := func() {
+		escaped := strongPattern.ReplaceAllString(text.String(), "<strong>$1</strong>")
+		b.WriteString(emPattern.ReplaceAllString(escaped, "<em>$1</em>"))
+		text.Reset()
+	}
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && isPunct(runes[i+1]):
+			// 转义的标点以字符实体输出，不参与加粗与斜体的匹配
+			fmt.Fprintf(&text, "&#%d;", runes[i+1])
+			i++
+		case r == '`':
+			n := 1
+			for i+n < len(runes) && runes[i+n] == '`' {
+				n++
+			}
+			end := findBackticks(runes, i+n, n)
+			if end < 0 {
+				text.WriteString(strings.Repeat("`", n))
+				i += n - 1
+				continue
+			}
+			flush()
+			code := string(runes[i+n : end])
+			if len(code) > 2 && strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") {
+				code = code[1 : len(code)-1]
+			}
+			b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+			i = end + n - 1
+		default:
+			text.WriteString(html.EscapeString(string(r)))
+		}
+	}
+	flush()
+	return b.String()
+}
+
+// findBackticks 从 start 开始查找恰好 n 个连续反引号的位置，找不到时返回 -1
+func findBackticks(runes []rune, start, n int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] != '`' {
+			continue
+		}
+		j := i
+		for j < len(runes) && runes[j] == '`' {
+			j++
+		}
+		if j-i == n {
+			return i
+		}
+		i = j - 1
+	}
+	return -1
+}
+
+// isPunct 可以用反斜杠转义的 ASCII 标点
+func isPunct(r rune) bool {
+	return strings.ContainsRune("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", r)
+}
@@ -0,0 +1,274 @@
+package quizformat
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GIFT 格式: 题目之间以空行分隔，"//" 开头的行为注释(其中的 [tag:xxx] 为题目标签)，
+// "$CATEGORY:" 设置其后题目的分类。选择题的答案写在 {} 中，"=" 为正确选项、"~" 为错误选项，
+// "~%50%" 形式带得分比例的为多选题，"#" 后为选项反馈，"####" 后为题目的总体反馈。
+// 特殊字符 ~ = # { } : 与 \ 需用 \ 转义，\n 表示换行。
+
+var giftTagPattern = regexp.MustCompile(`\[tag:([^\]]+)\]`)
+
+// ParseGIFT 解析 GIFT 文本
+func ParseGIFT(data []byte) ([]Item, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	var items []Item
+	var category, tags []string
+	var block []string
+	flush := func() {
+		if len(block) == 0 {
+			return
+		}
+		text := strings.TrimSpace(strings.Join(block, "\n"))
+		block = block[:0]
+		if strings.HasPrefix(text, "$CATEGORY:") {
+			category = splitCategory(strings.TrimPrefix(text, "$CATEGORY:"))
+			tags = nil
+			return
+		}
+		item := Item{Index: len(items) + 1}
+		item.Question, item.Err = parseGIFTQuestion(text)
+		item.Question.Category = category
+		item.Question.Tags = append(item.Question.Tags, tags...)
+		items = append(items, item)
+		tags = nil
+	}
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "//"):
+			for _, m := range giftTagPattern.FindAllStringSubmatch(trimmed, -1) {
+				tags = append(tags, strings.TrimSpace(m[1]))
+			}
+		default:
+			block = append(block, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取GIFT文件失败: %w", err)
+	}
+	flush()
+	return items, nil
+}
+
+// indexUnescaped 查找未转义的子串
+func indexUnescaped(s, sub string, from int) int {
+	for i := from; i+len(sub) <= len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitUnescaped 在未转义的 = 与 ~ 处切分答案，返回的每一段以 = 或 ~ 开头
+func splitUnescaped(s string) []string {
+	var parts []string
+	start := -1
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == '=' || s[i] == '~' {
+			if start >= 0 {
+				parts = append(parts, s[start:i])
+			}
+			start = i
+		}
+	}
+	if start >= 0 {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
+
+func unescapeGIFT(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func escapeGIFT(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', '~', '=', '#', '{', '}', ':':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// giftPlain 按 [html] 等格式标记转换为纯文本
+func giftPlain(s, format string) string {
+	s = unescapeGIFT(s)
+	if format == "html" {
+		return htmlToText(s)
+	}
+	return s
+}
+
+func parseGIFTQuestion(text string) (Question, error) {
+	var q Question
+	var name string
+	if strings.HasPrefix(text, "::") {
+		end := indexUnescaped(text, "::", 2)
+		if end < 0 {
+			return q, errors.New("题目名称缺少结束的 ::")
+		}
+		name = text[2:end]
+		text = strings.TrimSpace(text[end+2:])
+	}
+	format := ""
+	if strings.HasPrefix(text, "[") {
+		if end := strings.Index(text, "]"); end > 0 {
+			format = strings.ToLower(text[1:end])
+			text = text[end+1:]
+		}
+	}
+	open := indexUnescaped(text, "{", 0)
+	if open < 0 {
+		q.Title = giftPlain(text, format)
+		return q, errors.New("不支持的题型(说明文字)，仅支持选择题")
+	}
+	closing := indexUnescaped(text, "}", open+1)
+	if closing < 0 {
+		return q, errors.New("答案缺少结束的 }")
+	}
+	q.Title = giftPlain(text[:open], format)
+	if after := giftPlain(text[closing+1:], format); after != "" {
+		// 填空形式的题目，答案位置以下划线表示
+		q.Title += " _____ " + after
+	}
+	if q.Title == "" {
+		q.Title = unescapeGIFT(name)
+	}
+
+	answers := text[open+1 : closing]
+	if i := indexUnescaped(answers, "####", 0); i >= 0 {
+		q.Explanation = giftPlain(answers[i+4:], format)
+		answers = answers[:i]
+	}
+	trimmed := strings.TrimSpace(answers)
+	switch strings.ToUpper(trimmed) {
+	case "T", "F", "TRUE", "FALSE":
+		return q, errors.New("不支持的题型(判断题)，仅支持选择题")
+	}
+	if strings.HasPrefix(trimmed, "#") {
+		return q, errors.New("不支持的题型(数值题)，仅支持选择题")
+	}
+	parts := splitUnescaped(answers)
+	hasWrong, weighted, equals := false, false, 0
+	for i, part := range parts {
+		correct := part[0] == '='
+		body := part[1:]
+		if strings.HasPrefix(body, "%") {
+			end := strings.Index(body[1:], "%")
+			if end < 0 {
+				return q, fmt.Errorf("选项%c的得分比例格式错误", 'A'+i)
+			}
+			fraction, err := strconv.ParseFloat(body[1:end+1], 64)
+			if err != nil {
+				return q, fmt.Errorf("选项%c的得分比例 %s 无效", 'A'+i, body[1:end+1])
+			}
+			correct = fraction > 0
+			weighted = weighted || part[0] == '~'
+			body = body[end+2:]
+		}
+		if part[0] == '~' {
+			hasWrong = true
+		} else {
+			equals++
+		}
+		if indexUnescaped(body, "->", 0) >= 0 {
+			return q, errors.New("不支持的题型(匹配题)，仅支持选择题")
+		}
+		if j := indexUnescaped(body, "#", 0); j >= 0 {
+			body = body[:j]
+		}
+		q.Options = append(q.Options, giftPlain(body, format))
+		if correct {
+			q.Answer |= 1 << i
+		}
+	}
+	if !hasWrong {
+		return q, errors.New("不支持的题型(简答题)，仅支持选择题")
+	}
+	if q.Answer == 0 {
+		return q, errors.New("没有正确选项")
+	}
+	q.Single = !weighted && equals == 1
+	return q, nil
+}
+
+// WriteGIFT 生成 GIFT 文本，分类变化时输出 $CATEGORY
+func WriteGIFT(w io.Writer, questions []Question) error {
+	bw := bufio.NewWriter(w)
+	var category []string
+	for i, q := range questions {
+		if i == 0 || !sameCategory(category, q.Category) {
+			category = q.Category
+			fmt.Fprintf(bw, "$CATEGORY: %s\n\n", joinCategory(category))
+		}
+		if len(q.Tags) > 0 {
+			bw.WriteString("//")
+			for _, tag := range q.Tags {
+				fmt.Fprintf(bw, " [tag:%s]", strings.ReplaceAll(tag, "]", ""))
+			}
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "::%s:: %s {\n", escapeGIFT(questionName(q.Title)), escapeGIFT(q.Title))
+		for j, fraction := range answerFractions(q) {
+			switch {
+			case q.Single && fraction > 0:
+				fmt.Fprintf(bw, "\t=%s\n", escapeGIFT(q.Options[j]))
+			case q.Single:
+				fmt.Fprintf(bw, "\t~%s\n", escapeGIFT(q.Options[j]))
+			default:
+				fmt.Fprintf(bw, "\t~%%%s%%%s\n", formatFraction(fraction), escapeGIFT(q.Options[j]))
+			}
+		}
+		if q.Explanation != "" {
+			fmt.Fprintf(bw, "\t####%s\n", escapeGIFT(q.Explanation))
+		}
+		bw.WriteString("}\n\n")
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,96 @@
+package quizformat
+
+import (
+	"html/template"
+	"io"
+
+	"aiquiz/utils/markdown"
+)
+
+// 打印用的 HTML 文档: 题干、选项与解析按 Markdown 子集渲染，样式内联，便于浏览器直接打印。
+// 不带答案时只输出题目与选项，供学生作答；带答案时标出正确选项并附上解析。
+
+type htmlOption struct {
+	Label   string
+	Content template.HTML
+	Correct bool
+}
+
+type htmlQuestion struct {
+	Index       int
+	Title       template.HTML
+	Single      bool
+	Score       int
+	Options     []htmlOption
+	Explanation template.HTML
+}
+
+var htmlTemplate = template.Must(template.New("paper").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; max-width: 800px; margin: 2em auto; line-height: 1.6; color: #222; }
+h1 { text-align: center; }
+.question { margin-bottom: 1.5em; page-break-inside: avoid; }
+.question-head { display: flex; gap: .5em; }
+.question-head p:first-child { margin-top: 0; }
+.meta { color: #666; white-space: nowrap; }
+.options { list-style: none; padding-left: 1.5em; }
+.options li { display: flex; gap: .5em; }
+.options li p { margin: 0; }
+.correct { font-weight: bold; }
+.explanation { border-left: 3px solid #ccc; padding-left: 1em; color: #444; }
+pre { background: #f5f5f5; padding: .75em; overflow-x: auto; white-space: pre-wrap; }
+code { font-family: Menlo, Consolas, monospace; font-size: .9em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- range .Questions}}
+<div class="question">
+<div class="question-head"><span class="meta">{{.Index}}. [{{if .Single}}单选{{else}}多选{{end}}{{if .Score}} {{.Score}}分{{end}}]</span><div>{{.Title}}</div></div>
+<ul class="options">
+{{- range .Options}}
+<li{{if .Correct}} class="correct"{{end}}><span>{{.Label}}.</span><div>{{.Content}}</div></li>
+{{- end}}
+</ul>
+{{- if $.WithAnswers}}
+<div class="explanation"><p>答案: {{range .Options}}{{if .Correct}}{{.Label}}{{end}}{{end}}</p>{{.Explanation}}</div>
+{{- end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML 生成可打印的 HTML 文档，withAnswers 为 true 时标出正确选项并输出解析
+func WriteHTML(w io.Writer, title string, questions []Question, withAnswers bool) error {
+	items := make([]htmlQuestion, 0, len(questions))
+	for i, q := range questions {
+		item := htmlQuestion{
+			Index: i + 1,
+			// Render 的输出只包含转义后的文本与白名单标签
+			Title:  template.HTML(markdown.Render(q.Title)),
+			Single: q.Single,
+			Score:  q.Score,
+		}
+		for j, content := range q.Options {
+			item.Options = append(item.Options, htmlOption{
+				Label:   string(rune('A' + j)),
+				Content: template.HTML(markdown.Render(content)),
+				Correct: withAnswers && q.Answer&(1<<j) != 0,
+			})
+		}
+		if withAnswers {
+			item.Explanation = template.HTML(markdown.Render(q.Explanation))
+		}
+		items = append(items, item)
+	}
+	return htmlTemplate.Execute(w, map[string]interface{}{
+		"Title":       title,
+		"Questions":   items,
+		"WithAnswers": withAnswers,
+	})
+}
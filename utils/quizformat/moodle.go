@@ -0,0 +1,177 @@
+package quizformat
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type moodleText struct {
+	Format string `xml:"format,attr,omitempty"`
+	Text   string `xml:"text"`
+}
+
+// plain 按格式转换为纯文本
+func (t *moodleText) plain() string {
+	if t == nil {
+		return ""
+	}
+	if t.Format == "" || t.Format == "html" {
+		return htmlToText(t.Text)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+type moodleAnswer struct {
+	Fraction string      `xml:"fraction,attr"`
+	Format   string      `xml:"format,attr,omitempty"`
+	Text     string      `xml:"text"`
+	Feedback *moodleText `xml:"feedback,omitempty"`
+}
+
+type moodleQuestion struct {
+	Type            string         `xml:"type,attr"`
+	Category        *moodleText    `xml:"category,omitempty"`
+	Name            *moodleText    `xml:"name,omitempty"`
+	QuestionText    *moodleText    `xml:"questiontext,omitempty"`
+	GeneralFeedback *moodleText    `xml:"generalfeedback,omitempty"`
+	Single          string         `xml:"single,omitempty"`
+	ShuffleAnswers  string         `xml:"shuffleanswers,omitempty"`
+	AnswerNumbering string         `xml:"answernumbering,omitempty"`
+	Answers         []moodleAnswer `xml:"answer"`
+	Tags            *moodleTags    `xml:"tags,omitempty"`
+}
+
+type moodleTags struct {
+	Tags []moodleText `xml:"tag"`
+}
+
+type moodleQuiz struct {
+	XMLName   xml.Name         `xml:"quiz"`
+	Questions []moodleQuestion `xml:"question"`
+}
+
+// ParseMoodleXML 解析 Moodle XML，分类条目设置其后题目的分类，得分比例大于0的选项为正确答案
+func ParseMoodleXML(data []byte) ([]Item, error) {
+	var quiz moodleQuiz
+	if err := xml.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), &quiz); err != nil {
+		return nil, fmt.Errorf("Moodle XML格式错误: %w", err)
+	}
+	var items []Item
+	var category []string
+	for _, mq := range quiz.Questions {
+		if mq.Type == "category" {
+			if mq.Category != nil {
+				category = splitCategory(mq.Category.Text)
+			}
+			continue
+		}
+		item := Item{Index: len(items) + 1}
+		item.Question, item.Err = parseMoodleQuestion(mq)
+		item.Question.Category = category
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func parseMoodleQuestion(mq moodleQuestion) (Question, error) {
+	q := Question{
+		Title:       mq.QuestionText.plain(),
+		Explanation: mq.GeneralFeedback.plain(),
+	}
+	if q.Title == "" {
+		q.Title = mq.Name.plain()
+	}
+	if mq.Tags != nil {
+		for _, tag := range mq.Tags.Tags {
+			if text := strings.TrimSpace(tag.Text); text != "" {
+				q.Tags = append(q.Tags, text)
+			}
+		}
+	}
+	if mq.Type != "multichoice" {
+		return q, fmt.Errorf("不支持的题型 %s，仅支持选择题(multichoice)", mq.Type)
+	}
+	correct := 0
+	for i, answer := range mq.Answers {
+		text := (&moodleText{Format: answer.Format, Text: answer.Text}).plain()
+		q.Options = append(q.Options, text)
+		fraction, err := strconv.ParseFloat(strings.TrimSpace(answer.Fraction), 64)
+		if err != nil {
+			return q, fmt.Errorf("选项%c的得分比例 %s 无效", 'A'+i, answer.Fraction)
+		}
+		if fraction > 0 {
+			q.Answer |= 1 << i
+			correct++
+		}
+	}
+	if correct == 0 {
+		return q, errors.New("没有得分比例大于0的选项")
+	}
+	switch strings.ToLower(strings.TrimSpace(mq.Single)) {
+	case "true", "1":
+		q.Single = true
+	case "false", "0":
+		q.Single = false
+	default:
+		q.Single = correct == 1
+	}
+	return q, nil
+}
+
+// WriteMoodleXML 生成 Moodle XML，分类变化时插入分类条目
+func WriteMoodleXML(w io.Writer, questions []Question) error {
+	quiz := moodleQuiz{Questions: make([]moodleQuestion, 0, len(questions))}
+	var category []string
+	for i, q := range questions {
+		if i == 0 || !sameCategory(category, q.Category) {
+			category = q.Category
+			quiz.Questions = append(quiz.Questions, moodleQuestion{
+				Type:     "category",
+				Category: &moodleText{Text: joinCategory(category)},
+			})
+		}
+		single := "false"
+		if q.Single {
+			single = "true"
+		}
+		mq := moodleQuestion{
+			Type:            "multichoice",
+			Name:            &moodleText{Text: questionName(q.Title)},
+			QuestionText:    &moodleText{Format: "html", Text: textToHTML(q.Title)},
+			GeneralFeedback: &moodleText{Format: "html", Text: textToHTML(q.Explanation)},
+			Single:          single,
+			ShuffleAnswers:  "true",
+			AnswerNumbering: "ABCD",
+		}
+		for j, fraction := range answerFractions(q) {
+			mq.Answers = append(mq.Answers, moodleAnswer{
+				Fraction: formatFraction(fraction),
+				Format:   "html",
+				Text:     textToHTML(q.Options[j]),
+			})
+		}
+		if len(q.Tags) > 0 {
+			mq.Tags = &moodleTags{}
+			for _, tag := range q.Tags {
+				mq.Tags.Tags = append(mq.Tags.Tags, moodleText{Text: tag})
+			}
+		}
+		quiz.Questions = append(quiz.Questions, mq)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(quiz); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,523 @@
+package quizformat
+
+import (
+	"aiquiz/utils"
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// IMS QTI 内容包: 根目录的 imsmanifest.xml 列出全部资源，assessment.xml 为试卷(按顺序引用题目并记录分值)，
+// items 目录下每道题目一个 assessmentItem 文件。选择题使用 choiceInteraction，
+// 评分规则为答案与 correctResponse 完全一致得分，题目解析写入 modalFeedback。
+// QTI 3.0 与 2.1 的结构相同，只是元素名改为 qti- 前缀的短横线形式、属性名改为短横线形式。
+
+// QTI 版本
+const (
+	QTIVersion21 = "2.1"
+	QTIVersion30 = "3.0"
+)
+
+type qtiNamespaces struct {
+	item, manifest, itemType, testType, schema, schemaVersion string
+}
+
+var qtiVersions = map[string]qtiNamespaces{
+	QTIVersion21: {
+		item:          "http://www.imsglobal.org/xsd/imsqti_v2p1",
+		manifest:      "http://www.imsglobal.org/xsd/imscp_v1p1",
+		itemType:      "imsqti_item_xmlv2p1",
+		testType:      "imsqti_test_xmlv2p1",
+		schema:        "QTIv2.1 Package",
+		schemaVersion: "1.0.0",
+	},
+	QTIVersion30: {
+		item:          "http://www.imsglobal.org/xsd/imsqtiasi_v3p0",
+		manifest:      "http://www.imsglobal.org/xsd/qti/qtiv3p0/imscp_v1p1",
+		itemType:      "imsqti_item_xmlv3p0",
+		testType:      "imsqti_test_xmlv3p0",
+		schema:        "QTI Package",
+		schemaVersion: "3.0.0",
+	},
+}
+
+const (
+	qtiLOMNamespace = "http://ltsc.ieee.org/xsd/LOM"
+	qtiTestFile     = "assessment.xml"
+	qtiManifestFile = "imsmanifest.xml"
+)
+
+// IsSupportedQTIVersion 检查QTI版本是否支持
+func IsSupportedQTIVersion(version string) bool {
+	_, ok := qtiVersions[version]
+	return ok
+}
+
+// qtiNode 生成QTI文件用的XML元素，元素名与属性名使用 QTI 2.1 的驼峰形式，输出 3.0 时再转换
+type qtiNode struct {
+	name     string
+	attrs    []string // 属性名与属性值交替排列
+	text     string
+	children []*qtiNode
+}
+
+func qtiElement(name string, attrs ...string) *qtiNode {
+	return &qtiNode{name: name, attrs: attrs}
+}
+
+func (n *qtiNode) add(children ...*qtiNode) *qtiNode {
+	n.children = append(n.children, children...)
+	return n
+}
+
+func (n *qtiNode) setText(text string) *qtiNode {
+	n.text = text
+	return n
+}
+
+// kebab 将驼峰形式转换为短横线形式
+func kebab(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// write 输出元素，qti3 为 true 时转换为 QTI 3.0 的命名(清单文件不转换)
+func (n *qtiNode) write(b *bytes.Buffer, qti3 bool, depth int) {
+	name := n.name
+	if qti3 {
+		name = "qti-" + kebab(name)
+	}
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString("<" + name)
+	for i := 0; i+1 < len(n.attrs); i += 2 {
+		attr := n.attrs[i]
+		if qti3 && !strings.HasPrefix(attr, "xmlns") {
+			attr = kebab(attr)
+		}
+		b.WriteString(" " + attr + `="`)
+		xml.EscapeText(b, []byte(n.attrs[i+1]))
+		b.WriteString(`"`)
+	}
+	if n.text == "" && len(n.children) == 0 {
+		b.WriteString("/>\n")
+		return
+	}
+	b.WriteString(">")
+	if n.text != "" {
+		// 换行输出为 <br/>
+		for i, line := range strings.Split(n.text, "\n") {
+			if i > 0 {
+				b.WriteString("<br/>")
+			}
+			xml.EscapeText(b, []byte(line))
+		}
+	}
+	if len(n.children) > 0 {
+		b.WriteString("\n")
+		for _, child := range n.children {
+			child.write(b, qti3, depth+1)
+		}
+		b.WriteString(strings.Repeat("  ", depth))
+	}
+	b.WriteString("</" + name + ">\n")
+}
+
+func qtiDocument(root *qtiNode, qti3 bool) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	root.write(&b, qti3, 0)
+	return b.Bytes()
+}
+
+// qtiItem 生成单道题目，答案完全正确得1分，最终分值由试卷中的权重决定
+func qtiItem(identifier string, q Question, ns qtiNamespaces) *qtiNode {
+	cardinality, maxChoices := "multiple", "0"
+	if q.Single {
+		cardinality, maxChoices = "single", "1"
+	}
+	correct := qtiElement("correctResponse")
+	interaction := qtiElement("choiceInteraction", "responseIdentifier", "RESPONSE", "shuffle", "false", "maxChoices", maxChoices).
+		add(qtiElement("prompt").setText(q.Title))
+	for i, option := range q.Options {
+		choiceID := string(rune('A' + i))
+		if q.Answer&(1<<i) != 0 {
+			correct.add(qtiElement("value").setText(choiceID))
+		}
+		interaction.add(qtiElement("simpleChoice", "identifier", choiceID).setText(option))
+	}
+	setOutcome := func(identifier, baseType, value string) *qtiNode {
+		return qtiElement("setOutcomeValue", "identifier", identifier).
+			add(qtiElement("baseValue", "baseType", baseType).setText(value))
+	}
+	processing := qtiElement("responseProcessing").add(
+		qtiElement("responseCondition").add(
+			qtiElement("responseIf").add(
+				qtiElement("match").add(
+					qtiElement("variable", "identifier", "RESPONSE"),
+					qtiElement("correct", "identifier", "RESPONSE"),
+				),
+				setOutcome("SCORE", "float", "1"),
+			),
+			qtiElement("responseElse").add(setOutcome("SCORE", "float", "0")),
+		),
+	)
+
+	item := qtiElement("assessmentItem", "xmlns", ns.item, "identifier", identifier,
+		"title", questionName(q.Title), "adaptive", "false", "timeDependent", "false").
+		add(
+			qtiElement("responseDeclaration", "identifier", "RESPONSE", "cardinality", cardinality, "baseType", "identifier").add(correct),
+			qtiElement("outcomeDeclaration", "identifier", "SCORE", "cardinality", "single", "baseType", "float").
+				add(qtiElement("defaultValue").add(qtiElement("value").setText("0"))),
+		)
+	if q.Explanation != "" {
+		item.add(qtiElement("outcomeDeclaration", "identifier", "FEEDBACK", "cardinality", "single", "baseType", "identifier"))
+		processing.add(setOutcome("FEEDBACK", "identifier", "EXPLANATION"))
+	}
+	item.add(qtiElement("itemBody").add(interaction), processing)
+	if q.Explanation != "" {
+		item.add(qtiElement("modalFeedback", "outcomeIdentifier", "FEEDBACK", "identifier", "EXPLANATION", "showHide", "show").
+			setText(q.Explanation))
+	}
+	return item
+}
+
+// WriteQTI 生成QTI内容包(zip)，题目按顺序写入试卷，分值为 Question.Score(未设置时为1)
+func WriteQTI(w io.Writer, title string, questions []Question, version string) error {
+	ns, ok := qtiVersions[version]
+	if !ok {
+		return fmt.Errorf("不支持的QTI版本 %s", version)
+	}
+	qti3 := version == QTIVersion30
+	zw := zip.NewWriter(w)
+	writeFile := func(name string, data []byte) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+
+	section := qtiElement("assessmentSection", "identifier", "section-1", "title", title, "visible", "true")
+	testResource := qtiElement("resource", "identifier", "test", "type", ns.testType, "href", qtiTestFile).
+		add(qtiElement("file", "href", qtiTestFile))
+	resources := qtiElement("resources").add(testResource)
+	for i, q := range questions {
+		identifier := fmt.Sprintf("item-%d", i+1)
+		href := "items/" + identifier + ".xml"
+		if err := writeFile(href, qtiDocument(qtiItem(identifier, q, ns), qti3)); err != nil {
+			return err
+		}
+		score := q.Score
+		if score <= 0 {
+			score = 1
+		}
+		section.add(qtiElement("assessmentItemRef", "identifier", identifier, "href", href).
+			add(qtiElement("weight", "identifier", "W", "value", strconv.Itoa(score))))
+
+		resource := qtiElement("resource", "identifier", identifier, "type", ns.itemType, "href", href)
+		// 分类与标签作为 LOM 关键词
+		keywords := append(append([]string{}, q.Category...), q.Tags...)
+		if len(keywords) > 0 {
+			general := qtiElement("general")
+			for _, keyword := range keywords {
+				general.add(qtiElement("keyword").add(qtiElement("string").setText(keyword)))
+			}
+			resource.add(qtiElement("metadata").add(qtiElement("lom", "xmlns", qtiLOMNamespace).add(general)))
+		}
+		resource.add(qtiElement("file", "href", href))
+		resources.add(resource)
+		testResource.add(qtiElement("dependency", "identifierref", identifier))
+	}
+
+	test := qtiElement("assessmentTest", "xmlns", ns.item, "identifier", "test", "title", title).add(
+		qtiElement("outcomeDeclaration", "identifier", "SCORE", "cardinality", "single", "baseType", "float"),
+		qtiElement("testPart", "identifier", "part-1", "navigationMode", "nonlinear", "submissionMode", "simultaneous").add(section),
+		qtiElement("outcomeProcessing").add(
+			qtiElement("setOutcomeValue", "identifier", "SCORE").add(
+				qtiElement("sum").add(qtiElement("testVariables", "variableIdentifier", "SCORE", "weightIdentifier", "W")),
+			),
+		),
+	)
+	if err := writeFile(qtiTestFile, qtiDocument(test, qti3)); err != nil {
+		return err
+	}
+
+	manifest := qtiElement("manifest", "xmlns", ns.manifest, "identifier", "manifest").add(
+		qtiElement("metadata").add(
+			qtiElement("schema").setText(ns.schema),
+			qtiElement("schemaversion").setText(ns.schemaVersion),
+		),
+		qtiElement("organizations"),
+		resources,
+	)
+	if err := writeFile(qtiManifestFile, qtiDocument(manifest, false)); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// xmlTree 解析QTI文件用的通用XML树，元素名与属性名统一为 QTI 2.1 的驼峰形式(去掉命名空间与 qti- 前缀)，
+// 文本节点的 name 为空
+type xmlTree struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	children []*xmlTree
+}
+
+// camel 将短横线形式转换为驼峰形式
+func camel(name string) string {
+	parts := strings.Split(name, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func parseXMLTree(data []byte) (*xmlTree, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root := &xmlTree{}
+	stack := []*xmlTree{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlTree{name: camel(strings.TrimPrefix(t.Name.Local, "qti-")), attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.attrs[camel(attr.Name.Local)] = attr.Value
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.children = append(parent.children, &xmlTree{text: string(t)})
+		}
+	}
+	if len(root.children) == 0 {
+		return nil, errors.New("空的XML文件")
+	}
+	return root, nil
+}
+
+// find 按文档顺序查找第一个指定名称的后代元素
+func (t *xmlTree) find(name string) *xmlTree {
+	for _, child := range t.children {
+		if child.name == name {
+			return child
+		}
+		if found := child.find(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// findAll 按文档顺序查找全部指定名称的后代元素
+func (t *xmlTree) findAll(name string) []*xmlTree {
+	var result []*xmlTree
+	for _, child := range t.children {
+		if child.name == name {
+			result = append(result, child)
+		}
+		result = append(result, child.findAll(name)...)
+	}
+	return result
+}
+
+// content 提取元素的文本，br 与块级元素转为换行，skip 中的元素不计入
+func (t *xmlTree) content(skip ...string) string {
+	var b strings.Builder
+	var walk func(n *xmlTree)
+	walk = func(n *xmlTree) {
+		for _, child := range n.children {
+			switch {
+			case child.name == "":
+				b.WriteString(child.text)
+			case child.name == "br":
+				b.WriteString("\n")
+			default:
+				skipped := false
+				for _, name := range skip {
+					skipped = skipped || child.name == name
+				}
+				if skipped {
+					continue
+				}
+				walk(child)
+				if child.name == "p" || child.name == "div" {
+					b.WriteString("\n")
+				}
+			}
+		}
+	}
+	if t != nil {
+		walk(t)
+	}
+	lines := strings.Split(b.String(), "\n")
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// ParseQTI 解析QTI内容包(2.1或3.0)，有试卷时按试卷中的顺序读取题目，否则按清单中的顺序读取
+func ParseQTI(data []byte) ([]Item, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("不是有效的QTI内容包(zip)")
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[path.Clean(f.Name)] = f
+	}
+	// 解压大小计入同一预算，防止压缩炸弹
+	budget := utils.NewZipBudget()
+	readTree := func(name string) (*xmlTree, error) {
+		f, ok := files[path.Clean(name)]
+		if !ok {
+			return nil, fmt.Errorf("QTI内容包中缺少文件 %s", name)
+		}
+		content, err := budget.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		tree, err := parseXMLTree(content)
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %w", name, err)
+		}
+		return tree, nil
+	}
+
+	manifest, err := readTree(qtiManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	// 清单中的题目资源及其关键词
+	var itemHrefs, testHrefs []string
+	keywords := make(map[string][]string)
+	for _, resource := range manifest.findAll("resource") {
+		href := path.Clean(resource.attrs["href"])
+		switch resourceType := resource.attrs["type"]; {
+		case strings.Contains(resourceType, "imsqti_test"):
+			testHrefs = append(testHrefs, href)
+		case strings.Contains(resourceType, "imsqti_item"):
+			itemHrefs = append(itemHrefs, href)
+			for _, keyword := range resource.findAll("keyword") {
+				if text := keyword.content(); text != "" {
+					keywords[href] = append(keywords[href], text)
+				}
+			}
+		}
+	}
+	if len(testHrefs) > 0 {
+		test, err := readTree(testHrefs[0])
+		if err != nil {
+			return nil, err
+		}
+		itemHrefs = itemHrefs[:0]
+		for _, ref := range test.findAll("assessmentItemRef") {
+			itemHrefs = append(itemHrefs, path.Join(path.Dir(testHrefs[0]), ref.attrs["href"]))
+		}
+	}
+	if len(itemHrefs) == 0 {
+		return nil, errors.New("QTI内容包中没有题目")
+	}
+
+	items := make([]Item, 0, len(itemHrefs))
+	for i, href := range itemHrefs {
+		item := Item{Index: i + 1}
+		tree, err := readTree(href)
+		if err != nil {
+			item.Err = err
+		} else {
+			item.Question, item.Err = parseQTIItem(tree)
+		}
+		item.Question.Tags = keywords[href]
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func parseQTIItem(tree *xmlTree) (Question, error) {
+	var q Question
+	body := tree.find("itemBody")
+	interaction := tree.find("choiceInteraction")
+	if body == nil || interaction == nil {
+		q.Title = body.content()
+		return q, errors.New("不支持的题型，仅支持选择题(choiceInteraction)")
+	}
+	// 题干为交互之外的正文加上交互中的提示
+	parts := []string{body.content("choiceInteraction"), interaction.find("prompt").content()}
+	q.Title = strings.TrimSpace(strings.Join(parts, "\n"))
+	if feedback := tree.find("modalFeedback"); feedback != nil {
+		q.Explanation = feedback.content()
+	}
+
+	responseID := interaction.attrs["responseIdentifier"]
+	var declaration *xmlTree
+	for _, d := range tree.findAll("responseDeclaration") {
+		if d.attrs["identifier"] == responseID {
+			declaration = d
+		}
+	}
+	if declaration == nil {
+		return q, fmt.Errorf("缺少作答变量 %s 的声明", responseID)
+	}
+	// 正确答案优先取 correctResponse，没有时取 mapping 中得分为正的选项
+	correct := make(map[string]bool)
+	if cr := declaration.find("correctResponse"); cr != nil {
+		for _, value := range cr.findAll("value") {
+			correct[value.content()] = true
+		}
+	}
+	if len(correct) == 0 {
+		for _, entry := range declaration.findAll("mapEntry") {
+			if value, err := strconv.ParseFloat(entry.attrs["mappedValue"], 64); err == nil && value > 0 {
+				correct[entry.attrs["mapKey"]] = true
+			}
+		}
+	}
+	for i, choice := range interaction.findAll("simpleChoice") {
+		q.Options = append(q.Options, choice.content())
+		if correct[choice.attrs["identifier"]] {
+			q.Answer |= 1 << i
+		}
+	}
+	if q.Answer == 0 {
+		return q, errors.New("没有正确答案")
+	}
+	q.Single = declaration.attrs["cardinality"] == "single"
+	return q, nil
+}
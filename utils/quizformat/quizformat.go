@@ -0,0 +1,146 @@
+package quizformat
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 题库交换格式(Moodle XML、GIFT)的解析与生成，只处理单选与多选题。
+// 各格式统一转换为 Question，与数据库模型之间的转换由调用方负责。
+
+// Question 交换格式中的一道选择题
+type Question struct {
+	Title       string
+	Options     []string
+	Answer      int // 位掩码，第 i 个选项对应 1<<i
+	Single      bool
+	Explanation string
+	Category    []string // 分类路径，不含 $course$/top 等前缀
+	Tags        []string
+	Score       int // 试卷中的分值，仅 QTI 使用，0 表示未设置
+}
+
+// Item 文件中的一道题目，Index 为题目序号(从1开始，不含分类等非题目条目)，
+// 不支持的题型等无法转换的题目 Err 不为空
+type Item struct {
+	Index    int
+	Question Question
+	Err      error
+}
+
+// titleLength 导出时题目名称截取的长度
+const titleLength = 50
+
+// questionName 截取题目开头作为题目名称
+func questionName(title string) string {
+	title = strings.Join(strings.Fields(title), " ")
+	runes := []rune(title)
+	if len(runes) > titleLength {
+		return string(runes[:titleLength]) + "..."
+	}
+	return title
+}
+
+// splitCategory 解析分类路径，"//" 表示分类名称中的 "/"，去掉开头的 $course$ 等上下文与 top
+func splitCategory(path string) []string {
+	var segments []string
+	var b strings.Builder
+	runes := []rune(strings.TrimSpace(path))
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '/' {
+			if i+1 < len(runes) && runes[i+1] == '/' {
+				b.WriteRune('/')
+				i++
+				continue
+			}
+			segments = append(segments, b.String())
+			b.Reset()
+			continue
+		}
+		b.WriteRune(runes[i])
+	}
+	segments = append(segments, b.String())
+
+	result := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			continue
+		}
+		if len(result) == 0 && (strings.HasPrefix(segment, "$") && strings.HasSuffix(segment, "$") || segment == "top" && i <= 1) {
+			continue
+		}
+		result = append(result, segment)
+	}
+	return result
+}
+
+// joinCategory 生成课程下的分类路径
+func joinCategory(category []string) string {
+	parts := []string{"$course$", "top"}
+	for _, segment := range category {
+		parts = append(parts, strings.ReplaceAll(segment, "/", "//"))
+	}
+	return strings.Join(parts, "/")
+}
+
+func sameCategory(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// answerFractions 计算各选项的得分比例：单选题正确项为100，多选题正确项平分100、错误项平分-100
+func answerFractions(q Question) []float64 {
+	correct, wrong := 0, 0
+	for i := range q.Options {
+		if q.Answer&(1<<i) != 0 {
+			correct++
+		} else {
+			wrong++
+		}
+	}
+	fractions := make([]float64, len(q.Options))
+	for i := range q.Options {
+		switch {
+		case q.Answer&(1<<i) != 0 && q.Single:
+			fractions[i] = 100
+		case q.Answer&(1<<i) != 0:
+			fractions[i] = 100 / float64(correct)
+		case !q.Single:
+			fractions[i] = -100 / float64(wrong)
+		}
+	}
+	return fractions
+}
+
+// formatFraction 按 Moodle 可接受的精度(5位小数)输出得分比例
+func formatFraction(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1e5)/1e5, 'f', -1, 64)
+}
+
+var (
+	htmlBreak = regexp.MustCompile(`(?i)<br\s*/?>|</p>`)
+	htmlTag   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// htmlToText 去掉HTML标签并还原实体，换行标签转为换行
+func htmlToText(s string) string {
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = htmlTag.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
+
+// textToHTML 转义文本并把换行转为 <br>
+func textToHTML(s string) string {
+	return strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+}
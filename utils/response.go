@@ -48,6 +48,15 @@ func FailMsg(c *gin.Context, code int, message string) {
 	})
 }
 
+// FailMsgWithData 错误响应并附带数据(如逐条的校验错误)
+func FailMsgWithData(c *gin.Context, code int, message string, data interface{}) {
+	c.JSON(http.StatusOK, Response{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	})
+}
+
 // Ok 快捷方法：常见场景
 func Ok(c *gin.Context) {
 	SuccessMsg(c, nil, "操作成功")
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// 全文检索使用 SQLite FTS5 的 unicode61 分词器，它会把连续的中文当作一个词，
+// 因此写入索引与查询前先在这里做分词：中文按相邻二元组(bigram)切分，其余按单词切分，词之间用空格分隔。
+
+// isCJK 判断是否为需要按二元组切分的字符
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// isWordRune 判断是否属于英文单词/数字的一部分
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') && !isCJK(r)
+}
+
+// ngramTokens 将文本切分为检索词
+func ngramTokens(text string) []string {
+	var tokens []string
+	var cjkRun []rune
+	var word []rune
+
+	flushCJK := func() {
+		switch len(cjkRun) {
+		case 0:
+		case 1:
+			tokens = append(tokens, string(cjkRun))
+		default:
+			for i := 0; i+1 < len(cjkRun); i++ {
+				tokens = append(tokens, string(cjkRun[i:i+2]))
+			}
+		}
+		cjkRun = cjkRun[:0]
+	}
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjkRun = append(cjkRun, r)
+		case isWordRune(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushCJK()
+			flushWord()
+		}
+	}
+	flushCJK()
+	flushWord()
+	return tokens
+}
+
+// NGramTokenize 生成写入全文索引的文本
+func NGramTokenize(text string) string {
+	return strings.Join(ngramTokens(text), " ")
+}
+
+// NGramMatchQuery 将用户输入转换为 FTS5 的 MATCH 表达式：
+// 以空白分隔的每个词转换为一个短语(要求二元组连续出现)，多个词之间为 AND 关系。
+// 单个汉字无法与二元组直接匹配，使用前缀查询。没有可检索的词时返回空字符串
+func NGramMatchQuery(query string) string {
+	var phrases []string
+	for _, term := range strings.Fields(query) {
+		tokens := ngramTokens(term)
+		if len(tokens) == 0 {
+			continue
+		}
+		if len(tokens) == 1 && len([]rune(tokens[0])) == 1 && isCJK([]rune(tokens[0])[0]) {
+			phrases = append(phrases, `"`+tokens[0]+`"*`)
+			continue
+		}
+		phrases = append(phrases, `"`+strings.Join(tokens, " ")+`"`)
+	}
+	return strings.Join(phrases, " AND ")
+}
+
+// HighlightSnippet 在原文中查找第一个命中的检索词，截取其前后各 radius 个字符，
+// 对内容做HTML转义后用 <mark> 标记所有命中的检索词。都未命中时返回空字符串
+func HighlightSnippet(query string, radius int, texts ...string) string {
+	var terms [][]rune
+	for _, term := range strings.Fields(query) {
+		if t := []rune(strings.ToLower(term)); len(t) > 0 {
+			terms = append(terms, t)
+		}
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+
+	for _, text := range texts {
+		runes := []rune(text)
+		lower := make([]rune, len(runes))
+		for i, r := range runes {
+			lower[i] = unicode.ToLower(r)
+		}
+		// 标记每个位置是否被检索词覆盖
+		marked := make([]bool, len(runes))
+		first := -1
+		for _, term := range terms {
+			for i := 0; i+len(term) <= len(lower); i++ {
+				if string(lower[i:i+len(term)]) != string(term) {
+					continue
+				}
+				for j := i; j < i+len(term); j++ {
+					marked[j] = true
+				}
+				if first == -1 || i < first {
+					first = i
+				}
+			}
+		}
+		if first == -1 {
+			continue
+		}
+
+		start, end := first-radius, first+radius
+		if start < 0 {
+			start = 0
+		}
+		if end > len(runes) {
+			end = len(runes)
+		}
+		var b strings.Builder
+		if start > 0 {
+			b.WriteString("...")
+		}
+		for i := start; i < end; i++ {
+			if marked[i] && (i == start || !marked[i-1]) {
+				b.WriteString("<mark>")
+			}
+			b.WriteString(html.EscapeString(string(runes[i])))
+			if marked[i] && (i == end-1 || !marked[i+1]) {
+				b.WriteString("</mark>")
+			}
+		}
+		if end < len(runes) {
+			b.WriteString("...")
+		}
+		return b.String()
+	}
+	return ""
+}
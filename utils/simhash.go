@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"hash/fnv"
+	"math/bits"
+)
+
+// 相似题目检测使用 SimHash: 把题干与选项切分为检索词(与全文索引相同，中文按二元组切分)，
+// 以检索词及相邻两个检索词组成的短语为特征计算 64 位指纹，内容相近的题目指纹的汉明距离也较小。
+// 题目文本较短，改写措辞的重复题目距离通常在 10 以内，不相关的题目通常在 20 以上。
+
+// SimHash 计算多段文本的 64 位指纹，各段分别提取特征(特征不跨段)
+func SimHash(texts ...string) uint64 {
+	var weights [64]int
+	add := func(feature string) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	for _, text := range texts {
+		tokens := ngramTokens(text)
+		for i, token := range tokens {
+			add(token)
+			if i > 0 {
+				add(tokens[i-1] + " " + token)
+			}
+		}
+	}
+	var fingerprint uint64
+	for i, w := range weights {
+		if w > 0 {
+			fingerprint |= 1 << i
+		}
+	}
+	return fingerprint
+}
+
+// QuestionFingerprint 计算题目(题干与各选项)的指纹
+func QuestionFingerprint(title string, options []string) uint64 {
+	return SimHash(append([]string{title}, options...)...)
+}
+
+// HammingDistance 两个指纹不同的位数
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// SimHashBands 将指纹按位切分为 maxDistance+1 段，返回各段的值(按段的顺序)。
+// 由抽屉原理，汉明距离不超过 maxDistance 的两个指纹至少有一段完全相同，
+// 因此只需比较至少一段相同的指纹即可找出全部相似的指纹。maxDistance 不小于 64 时每段一位
+func SimHashBands(hash uint64, maxDistance int) []uint64 {
+	count := min(max(maxDistance+1, 1), 64)
+	bands := make([]uint64, count)
+	start := 0
+	for i := range bands {
+		// 前 64%count 段各多分一位
+		width := 64 / count
+		if i < 64%count {
+			width++
+		}
+		bands[i] = hash >> start & (1<<width - 1)
+		start += width
+	}
+	return bands
+}
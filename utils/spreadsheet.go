@@ -0,0 +1,299 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// 表格文件读取：CSV 与 XLSX 都读取为按行排列的单元格文本，空行会被跳过，
+// 每行同时返回其在文件中的行号(从1开始)，便于提示具体出错的位置。
+// XLSX 只依赖标准库解析 Office Open XML，读取工作簿中的第一个工作表。
+
+// 表格大小上限，解析过程中超出即停止，避免构造的文件(如单元格引用 XFD1048576)占用大量内存
+const (
+	MaxSheetRows  = 10000
+	MaxSheetCells = 500000 // 所有行补齐空单元格后的总数
+	// xlsxMaxColumns XLSX 最多 16384 列(A 到 XFD)
+	xlsxMaxColumns = 16384
+)
+
+var errSheetTooLarge = fmt.Errorf("表格超过大小上限(最多%d行、%d个单元格)", MaxSheetRows, MaxSheetCells)
+
+// SheetRow 表格中的一行
+type SheetRow struct {
+	Line  int
+	Cells []string
+}
+
+// ReadCSV 读取CSV文件，兼容带BOM的UTF-8文件
+func ReadCSV(r io.Reader) ([]SheetRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var rows []SheetRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV格式错误: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if !isBlankRow(record) {
+			if len(rows) >= MaxSheetRows {
+				return nil, errSheetTooLarge
+			}
+			rows = append(rows, SheetRow{Line: line, Cells: record})
+		}
+	}
+	return rows, nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText 共享字符串与内联字符串，富文本由多段 r 组成
+type xlsxText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.R) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxRow 工作表中的一行，工作表按行流式解析
+type xlsxRow struct {
+	R     int `xml:"r,attr"`
+	Cells []struct {
+		R  string   `xml:"r,attr"`
+		T  string   `xml:"t,attr"`
+		V  string   `xml:"v"`
+		Is xlsxText `xml:"is"`
+	} `xml:"c"`
+}
+
+// ReadXLSX 读取XLSX文件的第一个工作表
+func ReadXLSX(r io.ReaderAt, size int64) ([]SheetRow, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("不是有效的XLSX文件")
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	budget := NewZipBudget()
+
+	sheetPath, err := xlsxFirstSheet(files, budget)
+	if err != nil {
+		return nil, err
+	}
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, budget, &shared); err != nil {
+			return nil, err
+		}
+	}
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, errors.New("XLSX文件中缺少工作表")
+	}
+	rc, err := budget.Open(f)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var rows []SheetRow
+	line, totalCells := 0, 0
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %w", f.Name, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %w", f.Name, err)
+		}
+		if row.R > 0 {
+			line = row.R
+		} else {
+			line++
+		}
+		// 先确定列数再分配，超出上限时不分配
+		columns := 0
+		for i, c := range row.Cells {
+			col := i
+			if c.R != "" {
+				if col = xlsxColumnIndex(c.R); col < 0 {
+					return nil, fmt.Errorf("第%d行的单元格引用 %s 无效", line, c.R)
+				}
+			}
+			if col+1 > columns {
+				columns = col + 1
+			}
+		}
+		if totalCells += columns; totalCells > MaxSheetCells {
+			return nil, errSheetTooLarge
+		}
+		cells := make([]string, columns)
+		for i, c := range row.Cells {
+			col := i
+			if c.R != "" {
+				col = xlsxColumnIndex(c.R)
+			}
+			switch c.T {
+			case "s":
+				idx, err := strconv.Atoi(c.V)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("第%d行的共享字符串引用无效", line)
+				}
+				cells[col] = shared.Items[idx].String()
+			case "inlineStr":
+				cells[col] = c.Is.String()
+			default:
+				cells[col] = c.V
+			}
+		}
+		if !isBlankRow(cells) {
+			if len(rows) >= MaxSheetRows {
+				return nil, errSheetTooLarge
+			}
+			rows = append(rows, SheetRow{Line: line, Cells: cells})
+		}
+	}
+	return rows, nil
+}
+
+// xlsxFirstSheet 根据 workbook.xml 与其关系文件找到第一个工作表的路径
+func xlsxFirstSheet(files map[string]*zip.File, budget *ZipBudget) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+	wbFile, ok := files["xl/workbook.xml"]
+	relFile, relOK := files["xl/_rels/workbook.xml.rels"]
+	if !ok || !relOK {
+		return fallback, nil
+	}
+	var wb xlsxWorkbook
+	if err := decodeZipXML(wbFile, budget, &wb); err != nil {
+		return "", err
+	}
+	var rels xlsxRelationships
+	if err := decodeZipXML(relFile, budget, &rels); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", errors.New("XLSX文件中没有工作表")
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+// decodeZipXML 解析压缩包中的XML文件，读取的大小计入 budget
+func decodeZipXML(f *zip.File, budget *ZipBudget, v interface{}) error {
+	rc, err := budget.Open(f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("解析 %s 失败: %w", f.Name, err)
+	}
+	return nil
+}
+
+// xlsxColumnIndex 将单元格引用(如 "AB12")转换为从0开始的列号，
+// 列字母超过3个或超出 XFD 时返回 -1
+func xlsxColumnIndex(ref string) int {
+	col, letters := 0, 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		if letters++; letters > 3 {
+			return -1
+		}
+		col = col*26 + int(r-'A') + 1
+	}
+	if col > xlsxMaxColumns {
+		return -1
+	}
+	return col - 1
+}
+
+// ColumnIndex 将列字母(如 "A"、"ab")转换为从0开始的列号，不是列字母时返回 -1
+func ColumnIndex(letters string) int {
+	letters = strings.ToUpper(strings.TrimSpace(letters))
+	if letters == "" || len(letters) > 3 {
+		return -1
+	}
+	for _, r := range letters {
+		if r < 'A' || r > 'Z' {
+			return -1
+		}
+	}
+	return xlsxColumnIndex(letters)
+}
+
+func isBlankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Storage 附件文件存储，按内容寻址: key 为文件内容的 SHA-256 十六进制摘要，相同内容只保存一份
+type Storage interface {
+	// Put 写入文件，key 已存在时直接返回
+	Put(key string, r io.Reader) error
+	// Open 读取文件，不存在时返回 os.ErrNotExist
+	Open(key string) (io.ReadCloser, error)
+	// Delete 删除文件，不存在时不返回错误
+	Delete(key string) error
+}
+
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ErrInvalidKey key 不是合法的 SHA-256 摘要
+var ErrInvalidKey = errors.New("无效的文件标识")
+
+// LocalStorage 本地文件系统存储，文件按 key 的前两级各两个字符分目录保存，如 ab/cd/abcd...
+type LocalStorage struct {
+	root string
+}
+
+func NewLocalStorage(root string) *LocalStorage {
+	return &LocalStorage{root: root}
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if !keyPattern.MatchString(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, key[:2], key[2:4], key), nil
+}
+
+// Put 先写入同目录下的临时文件再重命名，避免读到写了一半的文件
+func (s *LocalStorage) Put(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, key+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
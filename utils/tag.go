@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeTag 标签归一化：全角转半角、转小写、合并空白，并去掉中文与其他字符之间的空格，
+// 使 "Gin 框架"、"gin框架"、"ＧＩＮ　框架" 归一为同一个标签
+func NormalizeTag(name string) string {
+	runes := make([]rune, 0, len(name))
+	for _, r := range name {
+		switch {
+		case r == '　':
+			r = ' '
+		case r >= '！' && r <= '～':
+			r -= 0xFEE0
+		}
+		runes = append(runes, unicode.ToLower(r))
+	}
+
+	fields := strings.Fields(string(runes))
+	var b strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			prev := []rune(fields[i-1])
+			next := []rune(field)
+			if !isCJK(prev[len(prev)-1]) && !isCJK(next[0]) {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(field)
+	}
+	return b.String()
+}
+
+// SplitKeywords 按中英文逗号、顿号、分号、竖线拆分关键词，保留原始写法并去重(按归一化结果)
+func SplitKeywords(keywords string) []string {
+	parts := strings.FieldsFunc(keywords, func(r rune) bool {
+		return strings.ContainsRune(",，、;；|\n", r)
+	})
+	seen := make(map[string]struct{}, len(parts))
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		normalized := NormalizeTag(part)
+		if normalized == "" {
+			continue
+		}
+		if _, ok := seen[normalized]; ok {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		result = append(result, part)
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+)
+
+// EmbeddingText 生成计算题目向量使用的文本: 题干与各选项按行拼接
+func EmbeddingText(title string, options []string) string {
+	return strings.Join(append([]string{title}, options...), "\n")
+}
+
+// NormalizeVector 将向量缩放为单位长度，之后两个向量的点积即为余弦相似度。零向量原样返回
+func NormalizeVector(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	normalized := make([]float32, len(v))
+	for i, x := range v {
+		normalized[i] = x / norm
+	}
+	return normalized
+}
+
+// CosineSimilarity 两个单位向量的余弦相似度，维度不同时返回 0
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+// EncodeVector 将向量编码为小端序的 float32 字节序列
+func EncodeVector(v []float32) []byte {
+	data := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(x))
+	}
+	return data
+}
+
+// DecodeVector 解码 EncodeVector 编码的向量
+func DecodeVector(data []byte) []float32 {
+	v := make([]float32, len(data)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return v
+}
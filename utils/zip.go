@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+)
+
+// 导入的 zip 文件(XLSX、QTI 内容包)解压后的大小上限，防止压缩炸弹
+const (
+	MaxZipEntrySize   = 20 << 20 // 单个文件
+	MaxZipPackageSize = 50 << 20 // 整个压缩包累计读取
+)
+
+// ZipBudget 记录从同一个压缩包中已读取的解压后大小，超出上限时拒绝继续读取
+type ZipBudget struct {
+	remaining int64
+}
+
+func NewZipBudget() *ZipBudget {
+	return &ZipBudget{remaining: MaxZipPackageSize}
+}
+
+// Open 打开压缩包中的文件，返回的 Reader 读取超过单个文件或剩余的总上限时返回错误。
+// 文件头中声明的大小只用于提前拒绝，实际以读取到的字节数为准
+func (b *ZipBudget) Open(f *zip.File) (io.ReadCloser, error) {
+	limit := int64(MaxZipEntrySize)
+	if b.remaining < limit {
+		limit = b.remaining
+	}
+	if f.UncompressedSize64 > uint64(limit) {
+		return nil, zipTooLarge(f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &zipLimitReader{ReadCloser: rc, budget: b, name: f.Name, limit: limit}, nil
+}
+
+// ReadFile 读取压缩包中文件的全部内容
+func (b *ZipBudget) ReadFile(f *zip.File) ([]byte, error) {
+	rc, err := b.Open(f)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+type zipLimitReader struct {
+	io.ReadCloser
+	budget *ZipBudget
+	name   string
+	limit  int64
+}
+
+func (r *zipLimitReader) Read(p []byte) (int, error) {
+	if r.limit <= 0 {
+		// 多读一个字节判断是否恰好读完
+		var one [1]byte
+		if n, _ := r.ReadCloser.Read(one[:]); n > 0 {
+			return 0, zipTooLarge(r.name)
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.limit {
+		p = p[:r.limit]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.limit -= int64(n)
+	r.budget.remaining -= int64(n)
+	return n, err
+}
+
+func zipTooLarge(name string) error {
+	return fmt.Errorf("%s 解压后超过大小上限", name)
+}